@@ -0,0 +1,137 @@
+// Administración de API keys desde la terminal, por ejemplo para crear la primera sin pasar por la API.
+// Cada clave pertenece a una tienda; -tenant la elige (por defecto, "default").
+//
+//	go run ./cmd/apikey create [-tenant t] -name escaner-deposito-1 -scopes product:read,product:stock [-expires 2160h]
+//	go run ./cmd/apikey list [-tenant t]
+//	go run ./cmd/apikey revoke [-tenant t] <id>
+//	go run ./cmd/apikey usage [-tenant t] [-days 30] <id>
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"mlsport/config"
+	"mlsport/internal/apikey/domain"
+	"mlsport/internal/apikey/infrastructure"
+	"mlsport/internal/apikey/usecase"
+	"mlsport/internal/auth"
+	"mlsport/internal/logging"
+	"mlsport/internal/tenant"
+	"os"
+	"strings"
+	"time"
+)
+
+const usage = "uso: apikey create|list|revoke|usage"
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	config.InitMongo()
+	repo := infrastructure.NewMongoAPIKeyRepo()
+	if err := repo.EnsureIndexes(context.Background()); err != nil {
+		fatal("Error creando índices de API keys", err)
+	}
+	service := usecase.NewAPIKeyService(repo)
+
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	tenantID := fs.String("tenant", tenant.Default, "tienda de la clave")
+	// Quien corre el comando tiene acceso a la base: actúa como admin y queda como creador.
+	principal := &auth.Principal{
+		Subject: "cli:" + config.GetEnv("USER", "desconocido"),
+		Roles:   []string{auth.RoleAdmin},
+		Method:  "cli",
+	}
+	scoped := func() context.Context {
+		if !tenant.ValidID(*tenantID) {
+			fmt.Fprintf(os.Stderr, "tienda %q inválida: %v\n", *tenantID, tenant.ErrInvalidID)
+			os.Exit(2)
+		}
+		return tenant.WithID(auth.WithPrincipal(context.Background(), principal), *tenantID)
+	}
+
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "create":
+		name := fs.String("name", "", "nombre del cliente")
+		scopes := fs.String("scopes", "", "permisos separados por coma, p. ej. product:read,product:stock")
+		expires := fs.Duration("expires", 0, "vigencia (p. ej. 2160h); 0 no vence")
+		_ = fs.Parse(args)
+
+		k := &domain.APIKey{Name: *name}
+		for _, scope := range strings.Split(*scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				k.Scopes = append(k.Scopes, auth.Permission(scope))
+			}
+		}
+		if *expires > 0 {
+			at := time.Now().UTC().Add(*expires)
+			k.ExpiresAt = &at
+		}
+		if err := service.Create(scoped(), k); err != nil {
+			fatal("Error creando API key", err)
+		}
+		printJSON(k)
+		fmt.Fprintln(os.Stderr, "Guarde la clave ahora: no se puede volver a consultar.")
+
+	case "list":
+		_ = fs.Parse(args)
+		list, err := service.GetAll(scoped())
+		if err != nil {
+			fatal("Error listando API keys", err)
+		}
+		if list == nil {
+			list = []domain.APIKey{}
+		}
+		printJSON(list)
+
+	case "revoke":
+		_ = fs.Parse(args)
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "uso: apikey revoke [-tenant t] <id>")
+			os.Exit(2)
+		}
+		k, err := service.Revoke(scoped(), fs.Arg(0))
+		if err != nil {
+			fatal("Error revocando API key", err)
+		}
+		printJSON(k)
+
+	case "usage":
+		days := fs.Int("days", usecase.DefaultUsageDays, "días hacia atrás, hoy incluido")
+		_ = fs.Parse(args)
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "uso: apikey usage [-tenant t] [-days N] <id>")
+			os.Exit(2)
+		}
+		list, err := service.Usage(scoped(), fs.Arg(0), *days)
+		if err != nil {
+			fatal("Error consultando uso", err)
+		}
+		printJSON(list)
+
+	default:
+		fmt.Fprintf(os.Stderr, "subcomando desconocido %q; %s\n", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fatal("Error escribiendo resultado", err)
+	}
+}
+
+// fatal registra el error y termina el proceso.
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
@@ -3,33 +3,327 @@
 // @description API REST para productos deportivos.
 // @host localhost:8080
 // @BasePath /api
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT como "Bearer <token>"; las lecturas pueden ser públicas según AUTH_PUBLIC_READS.
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key para clientes sin login (escáneres, integraciones); ver /api-keys.
 
 package main
 
 import (
+	"context"
+	"crypto/rsa"
 	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"log"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"log/slog"
 	"mlsport/config"
 	_ "mlsport/docs"
+	apikeyDelivery "mlsport/internal/apikey/delivery"
+	apikeyInfrastructure "mlsport/internal/apikey/infrastructure"
+	apikeyUsecase "mlsport/internal/apikey/usecase"
+	"mlsport/internal/auth"
+	brandDelivery "mlsport/internal/brand/delivery"
+	brandInfrastructure "mlsport/internal/brand/infrastructure"
+	brandUsecase "mlsport/internal/brand/usecase"
+	categoryDelivery "mlsport/internal/category/delivery"
+	categoryInfrastructure "mlsport/internal/category/infrastructure"
+	categoryUsecase "mlsport/internal/category/usecase"
+	"mlsport/internal/events"
+	"mlsport/internal/idempotency"
+	"mlsport/internal/logging"
+	"mlsport/internal/monitoring"
 	"mlsport/internal/product/delivery"
+	"mlsport/internal/product/domain"
 	"mlsport/internal/product/infrastructure"
 	"mlsport/internal/product/usecase"
+	"mlsport/internal/ratelimit"
+	"mlsport/internal/tenant"
+	tenantDelivery "mlsport/internal/tenant/delivery"
+	tenantInfrastructure "mlsport/internal/tenant/infrastructure"
+	tenantUsecase "mlsport/internal/tenant/usecase"
+	webhookDelivery "mlsport/internal/webhook/delivery"
+	webhookInfrastructure "mlsport/internal/webhook/infrastructure"
+	webhookUsecase "mlsport/internal/webhook/usecase"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 )
-func init() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No se cargó archivo .env, usando variables de entorno del sistema")
-	}
-}
+
 func main() {
+	envErr := godotenv.Load()
+	logger := config.InitLogging()
+	if envErr != nil {
+		logger.Info("No se cargó archivo .env, usando variables de entorno del sistema")
+	}
+	// Antes de Mongo: el monitor del driver toma el proveedor de trazas global.
+	shutdownTracing := config.InitTracing(context.Background())
 	config.InitMongo()
 
-	repo := infrastructure.NewMongoProductRepo()
+	// Categorías y marcas son propias de cada tienda; las anteriores a los tenants pasan a la tienda por defecto.
+	categoryRepo := categoryInfrastructure.NewMongoCategoryRepo()
+	if err := categoryRepo.EnsureIndexes(context.Background()); err != nil {
+		logger.Error("Error creando índices de categorías", logging.Err(err))
+	}
+	categoryService := categoryUsecase.NewCategoryService(categoryRepo)
+	categoryHandler := categoryDelivery.NewCategoryHandler(categoryService)
+
+	brandRepo := brandInfrastructure.NewMongoBrandRepo()
+	if err := brandRepo.EnsureIndexes(context.Background()); err != nil {
+		logger.Error("Error creando índices de marcas", logging.Err(err))
+	}
+	brandService := brandUsecase.NewBrandService(brandRepo)
+	brandHandler := brandDelivery.NewBrandHandler(brandService)
+
+	// Cada tienda tiene su catálogo; los datos anteriores a los tenants pasan a la tienda por defecto.
+	tenantService := tenantUsecase.NewTenantService(tenantInfrastructure.NewMongoTenantRepo())
+	if err := tenantService.EnsureDefault(); err != nil {
+		logger.Error("Error creando la tienda por defecto", logging.Err(err))
+	}
+	tenantHandler := tenantDelivery.NewTenantHandler(tenantService)
+
+	mongoRepo := infrastructure.NewMongoProductRepo()
+	if n, err := mongoRepo.AssignDefaultTenant(context.Background()); err != nil {
+		logger.Error("Error asignando la tienda por defecto a los productos", logging.Err(err))
+	} else if n > 0 {
+		logger.Info("Productos asignados a la tienda por defecto", "count", n)
+	}
+	if err := mongoRepo.EnsureIndexes(context.Background()); err != nil {
+		logger.Error("Error creando índices de productos", logging.Err(err))
+	}
+	outboxEnabled := config.GetEnv("OUTBOX_ENABLED", "true") == "true"
+	if outboxEnabled {
+		mongoRepo.Outbox = infrastructure.NewMongoOutboxRepo()
+		if err := mongoRepo.Outbox.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Error creando índices del outbox", logging.Err(err))
+		}
+	}
+	// Métricas operativas en /metrics; se miden las consultas que llegan a Mongo, debajo de la caché.
+	var meter *monitoring.Metrics
+	var productRepo domain.ProductRepository = mongoRepo
+	if config.GetEnv("METRICS_ENABLED", "true") == "true" {
+		meter = monitoring.New()
+		productRepo = infrastructure.NewMeteredProductRepo(mongoRepo, meter)
+	}
+	repo := infrastructure.NewCachedProductRepo(
+		productRepo,
+		config.GetEnvDuration("METRICS_CACHE_TTL", 30*time.Second),
+	)
+	cacheHandler := delivery.NewCacheHandler(repo)
+
+	counterRepo := infrastructure.NewMongoCounterRepo()
+	counters := usecase.NewCountersService(counterRepo)
+	// Los contadores se actualizan en la transacción de cada escritura, con las lecturas hechas en ella.
+	mongoRepo.Counters = counterRepo
+	countersHandler := delivery.NewCountersHandler(counters)
+	rebuildCounters := func() {
+		err := tenant.Each(context.Background(), tenantService, func(ctx context.Context) error {
+			_, err := counters.Rebuild(tenant.ID(ctx))
+			return err
+		})
+		if err != nil {
+			logger.Error("Error reconstruyendo contadores", logging.Err(err))
+		}
+	}
+
+	// Los productos cargados con la categoría en texto libre pasan a su slug, que es lo que buscan
+	// los filtros; los contadores por categoría se recalculan con los valores nuevos.
+	if n, err := mongoRepo.SlugifyCategories(context.Background()); err != nil {
+		logger.Error("Error migrando categorías de productos a slugs", logging.Err(err))
+	} else if n > 0 {
+		logger.Info("Categorías de productos migradas a slugs", "count", n)
+		rebuildCounters()
+	}
+	// Igual con las marcas: "NIKE" y "nike " pasan al nombre registrado en la tienda. Los contadores
+	// no dependen de la marca.
+	if n, err := brandRepo.CanonicalizeProducts(context.Background()); err != nil {
+		logger.Error("Error normalizando las marcas de los productos", logging.Err(err))
+	} else if n > 0 {
+		logger.Info("Marcas de productos normalizadas", "count", n)
+	}
+
+	// Renombrar o fusionar categorías reetiqueta los productos con el repo de productos, en la misma
+	// transacción: suma los contadores y deja en el outbox un evento por producto. La caché no se
+	// entera porque la escritura no pasa por ella.
+	categoryRepo.Relabel = mongoRepo.RelabelCategories
+	var stream *usecase.DashboardStream
+	categoryService.OnProductsRelabeled = func(ctx context.Context) {
+		repo.Invalidate()
+		stream.Refresh()
+		if mongoRepo.OnOutbox != nil {
+			mongoRepo.OnOutbox()
+		}
+	}
+	// Renombrar una marca también reetiqueta productos, igual que las categorías.
+	brandRepo.Relabel = mongoRepo.RelabelBrand
+	brandService.OnProductsRelabeled = categoryService.OnProductsRelabeled
+
+	authn := authenticators()
+	apiKeyRepo := apikeyInfrastructure.NewMongoAPIKeyRepo()
+	apiKeyHandler := apikeyDelivery.NewAPIKeyHandler(apikeyUsecase.NewAPIKeyService(apiKeyRepo))
+	if config.GetEnv("API_KEYS_ENABLED", "false") == "true" {
+		if err := apiKeyRepo.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Error creando índices de API keys", logging.Err(err))
+		}
+		apiKeyUsage := apikeyUsecase.NewUsageRecorder(apiKeyRepo)
+		apiKeyUsage.Interval = config.GetEnvDuration("API_KEY_USAGE_FLUSH_INTERVAL", apikeyUsecase.DefaultUsageFlushInterval)
+		go apiKeyUsage.Run(context.Background())
+		authn = append(authn, apikeyUsecase.NewAuthenticator(apiKeyRepo, apiKeyUsage))
+	}
+	authEnabled := len(authn) > 0
+
 	service := usecase.NewProductService(repo)
+	service.EnforcePermissions = authEnabled
+	service.Categories = categoryService
+	service.Brands = brandService
+	service.LowStockThreshold = config.GetEnvInt("LOW_STOCK_THRESHOLD", domain.DefaultLowStockThreshold)
 	handler := delivery.NewProductHandler(service)
 
-	r := gin.Default()
+	dashboard := usecase.NewDashboardService(service)
+	dashboard.WidgetTimeout = config.GetEnvDuration("DASHBOARD_WIDGET_TIMEOUT", usecase.DefaultWidgetTimeout)
+	if meter != nil {
+		dashboard.OnWidget = meter.ObserveWidget
+	}
+	dashboardHandler := delivery.NewDashboardHandler(dashboard)
+
+	stream = usecase.NewDashboardStream(service)
+	stream.MetricsInterval = config.GetEnvDuration("DASHBOARD_STREAM_METRICS_INTERVAL", usecase.DefaultStreamMetricsInterval)
+
+	stockFeed := usecase.NewStockFeed()
+	stockFeed.MaxSubscriptions = config.GetEnvInt("STOCK_WS_MAX_SUBSCRIPTIONS", usecase.DefaultMaxSubscriptions)
+	var stockTokens []string
+	if tokens := config.GetEnv("STOCK_WS_TOKENS", ""); tokens != "" {
+		stockTokens = strings.Split(tokens, ",")
+	}
+	// El feed acepta sus propios tokens o un usuario ya autenticado por el middleware de /api.
+	stockTokenAuth := delivery.TokenAuthorizer(stockTokens)
+	stockSocket := delivery.NewStockSocketHandler(stockFeed, func(r *http.Request) bool {
+		_, ok := auth.FromContext(r.Context())
+		return ok || stockTokenAuth(r)
+	})
+
+	// Con el outbox, los eventos se guardan junto a cada escritura y los publica el relay;
+	// sin él, el servicio los publica directamente después de escribir.
+	bus := events.NewBus()
+	var relay *usecase.OutboxRelay
+	if outboxEnabled {
+		relay = usecase.NewOutboxRelay(mongoRepo.Outbox, bus)
+		relay.Interval = config.GetEnvDuration("OUTBOX_POLL_INTERVAL", usecase.DefaultOutboxInterval)
+		relay.MaxAttempts = config.GetEnvInt("OUTBOX_MAX_ATTEMPTS", usecase.DefaultOutboxMaxAttempts)
+		mongoRepo.OnOutbox = relay.Wake
+	} else {
+		service.Events = bus
+	}
+	bus.Subscribe(events.All, stream.Handle, events.Named("dashboard-stream"))
+	bus.Subscribe(events.All, stockFeed.Handle, events.Named("stock-feed"))
+	streamHandler := delivery.NewStreamHandler(stream)
+	streamHandler.Heartbeat = config.GetEnvDuration("DASHBOARD_STREAM_HEARTBEAT", delivery.DefaultHeartbeatInterval)
+	go stream.Run(context.Background())
+
+	snapshotRepo := infrastructure.NewMongoSnapshotRepo()
+	if err := snapshotRepo.EnsureIndexes(context.Background()); err != nil {
+		logger.Error("Error creando índices de snapshots", logging.Err(err))
+	}
+	snapshots := usecase.NewSnapshotService(service, snapshotRepo)
+	snapshots.Tenants = tenantService
+	snapshotHandler := delivery.NewSnapshotHandler(snapshots)
+	go snapshots.Run(context.Background(), config.GetEnvDuration("METRICS_SNAPSHOT_INTERVAL", time.Hour))
+
+	checker := usecase.NewLowStockChecker(service, alertNotifier(), config.GetEnvDuration("LOW_STOCK_CHECK_INTERVAL", 5*time.Minute))
+	checker.Tenants = tenantService
+	checker.Repo = productRepo
+	bus.Subscribe(domain.EventStockChanged, checker.Handle, events.Async(events.DefaultAsyncQueue), events.Named("low-stock"))
+	go checker.Run(context.Background())
+
+	if meter != nil {
+		// Los gauges del catálogo salen de los contadores, que no recorren la colección.
+		go meter.RunCatalog(context.Background(), config.GetEnvDuration("METRICS_CATALOG_INTERVAL", monitoring.DefaultCatalogInterval), tenantService,
+			func(tenantID string) (int, int, error) {
+				c, err := counters.Get(tenantID)
+				if err != nil {
+					return 0, 0, err
+				}
+				return c.TotalProducts, c.TotalStock, nil
+			})
+	}
+
+	webhookRepo := webhookInfrastructure.NewMongoWebhookRepo()
+	deliveryRepo := webhookInfrastructure.NewMongoDeliveryRepo()
+	if err := deliveryRepo.EnsureIndexes(context.Background()); err != nil {
+		logger.Error("Error creando índices de webhooks", logging.Err(err))
+	}
+	webhookEvents := append([]string{domain.EventStockChanged, domain.EventPriceChanged}, domain.ProductEvents...)
+	webhookService := webhookUsecase.NewWebhookService(webhookRepo, deliveryRepo, webhookEvents)
+	webhookHandler := webhookDelivery.NewWebhookHandler(webhookService)
+	dispatcher := webhookUsecase.NewDispatcher(webhookRepo, deliveryRepo)
+	// Por defecto las entregas no llegan a loopback, redes privadas ni a la metadata de la nube.
+	if config.GetEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "false") == "true" {
+		webhookService.AllowPrivateNetworks = true
+		dispatcher.Client = webhookUsecase.NewClient(webhookUsecase.DefaultDeliveryTimeout, true)
+	}
+	dispatcher.MaxAttempts = config.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", webhookUsecase.DefaultDeliveryMaxAttempts)
+	dispatcher.Backoff = config.GetEnvDuration("WEBHOOK_RETRY_BACKOFF", webhookUsecase.DefaultDeliveryBackoff)
+	webhookService.OnRetry = dispatcher.Wake
+	// Síncrono: si no se puede registrar la entrega, el outbox vuelve a entregar el evento.
+	bus.Subscribe(events.All, dispatcher.Handle, events.Named("webhooks"))
+	go dispatcher.Run(context.Background())
+
+	// El relay arranca cuando ya están todos los suscriptores, para no marcar como entregado
+	// un evento que nadie recibió.
+	if relay != nil {
+		go relay.Run(context.Background())
+	}
+
+	// Cambios hechos directamente en Mongo (scripts de datos): se publican los mismos eventos y se
+	// corrigen caché y contadores. Distingue las escrituras de la API por su transacción con el outbox.
+	if config.GetEnv("CHANGE_STREAM_ENABLED", "false") == "true" {
+		if !outboxEnabled {
+			logger.Warn("CHANGE_STREAM_ENABLED requiere OUTBOX_ENABLED; el change stream no se inicia")
+		} else {
+			watcher := infrastructure.NewProductChangeWatcher(bus)
+			if err := watcher.EnablePreImages(context.Background()); err != nil {
+				logger.Warn("No se pudieron activar las pre-imágenes de products", logging.Err(err))
+			}
+			watcher.OnExternalChange = func(ctx context.Context, before, after *domain.Product) {
+				repo.Invalidate()
+				if delta := domain.ChangeDelta(before, after); !delta.IsZero() {
+					if err := counterRepo.Apply(tenant.ID(ctx), delta); err != nil {
+						logger.ErrorContext(ctx, "Error actualizando contadores de métricas", logging.Err(err))
+					}
+				}
+			}
+			watcher.OnResync = func() {
+				repo.Invalidate()
+				rebuildCounters()
+			}
+			go watcher.Run(context.Background())
+		}
+	}
+
+	// Logs estructurados en lugar del logger de gin: cada petición lleva su X-Request-ID.
+	redactor := logging.NewRedactor(strings.Split(config.GetEnv("LOG_REDACT_HEADERS", ""), ",")...).
+		WithQuery(strings.Split(config.GetEnv("LOG_REDACT_QUERY", ""), ",")...)
+	r := gin.New()
+	r.Use(
+		// Un span por petición, continuando el traceparent recibido; /metrics no se traza.
+		otelgin.Middleware("mlsport", otelgin.WithFilter(func(req *http.Request) bool {
+			return req.URL.Path != "/metrics"
+		})),
+		logging.RequestIDMiddleware(),
+		logging.AccessLog(logger, redactor),
+		logging.Recovery(logger),
+	)
+	if meter != nil {
+		r.Use(meter.Middleware())
+		r.GET("/metrics", gin.WrapH(meter.Handler()))
+	}
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -40,13 +334,52 @@ func main() {
 		})
 	})
 
-	api := r.Group("/api")
+	api := r.Group("/api", auth.Audit())
+	if authEnabled {
+		api.Use(auth.Required(config.GetEnv("AUTH_PUBLIC_READS", "true") == "true", authn...))
+	} else {
+		logger.Warn("⚠️ Sin JWT_HS256_SECRET, JWT_JWKS_FILE ni API_KEYS_ENABLED: la API no exige autenticación")
+	}
+	// La tienda sale del token o de X-Tenant-ID; todo lo que sigue trabaja sobre ella.
+	api.Use(tenant.Middleware(tenantService))
+	if config.GetEnv("RATE_LIMIT_ENABLED", "true") == "true" {
+		api.Use(rateLimiter().Middleware())
+	}
+	// Los reintentos de POST y PATCH con la misma Idempotency-Key repiten la primera respuesta.
+	var idempotencyStore idempotency.Store = idempotency.NewMemoryStore()
+	if config.GetEnv("IDEMPOTENCY_STORE", "mongo") == "mongo" {
+		mongoStore := idempotency.NewMongoStore()
+		if err := mongoStore.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Error creando índices de idempotencia", logging.Err(err))
+		}
+		idempotencyStore = mongoStore
+	}
+	idempotencyGuard := idempotency.NewGuard(idempotencyStore)
+	idempotencyGuard.TTL = config.GetEnvDuration("IDEMPOTENCY_TTL", idempotency.DefaultTTL)
+	api.Use(idempotencyGuard.Middleware())
+	// Permisos por ruta (ver auth.RolePermissions); sin autenticación configurada no se exigen.
+	pass := func(c *gin.Context) { c.Next() }
+	can := func(perms ...auth.Permission) gin.HandlerFunc {
+		if !authEnabled {
+			return pass
+		}
+		return auth.Require(perms...)
+	}
+	canAny := func(perms ...auth.Permission) gin.HandlerFunc {
+		if !authEnabled {
+			return pass
+		}
+		return auth.RequireAny(perms...)
+	}
+	editProduct := canAny(auth.PermProductEdit, auth.PermProductPrice, auth.PermProductStock)
+	manageCatalog := can(auth.PermCatalogManage)
 	{
 		api.GET("", func(c *gin.Context) {
 			c.Redirect(302, "/swagger/index.html")
 		})
 
-		api.GET("/products/dashboard", handler.GetDashboard)
+		api.GET("/products/dashboard", dashboardHandler.GetDashboard)
+		api.GET("/products/dashboard/stream", streamHandler.GetStream)
 
 		products := api.Group("/products")
 		{
@@ -54,21 +387,168 @@ func main() {
 			products.GET("/:id", handler.GetByID)
 			products.GET("/categories/:category", handler.GetByCategory)
 			products.GET("/metrics", handler.GetMetrics)
+			products.GET("/metrics/prices", handler.GetPriceDistribution)
+			products.GET("/metrics/history", snapshotHandler.GetHistory)
+			products.GET("/metrics/counters", countersHandler.Get)
+			products.GET("/metrics/counters/check", countersHandler.Check)
+			products.GET("/low-stock", handler.GetLowStock)
+			products.GET("/stock/ws", stockSocket.Serve)
+			products.GET("/cache/stats", cacheHandler.GetStats)
 			products.GET("/categories", handler.GetCategories)
 
-			products.POST("", handler.Create)
-			products.PUT("/:id", handler.Update)
-			products.PATCH("/:id", handler.Patch)
-			products.DELETE("/:id", handler.Delete)
+			products.POST("", can(auth.PermProductCreate), handler.Create)
+			products.PUT("/:id", editProduct, handler.Update)
+			products.PATCH("/:id", editProduct, handler.Patch)
+			products.DELETE("/:id", can(auth.PermProductDelete), handler.Delete)
+		}
+
+		categories := api.Group("/categories")
+		{
+			categories.GET("", categoryHandler.GetAll)
+			categories.GET("/tree", categoryHandler.GetTree)
+			categories.GET("/history", categoryHandler.GetHistory)
+			categories.GET("/:slug", categoryHandler.GetBySlug)
+
+			categories.POST("", manageCatalog, categoryHandler.Create)
+			categories.POST("/merge", manageCatalog, categoryHandler.Merge)
+			categories.POST("/:slug/rename", manageCatalog, categoryHandler.Rename)
+			categories.DELETE("/:slug", manageCatalog, categoryHandler.Delete)
+		}
+
+		brands := api.Group("/brands")
+		{
+			brands.GET("", brandHandler.GetAll)
+			brands.GET("/:slug", brandHandler.GetBySlug)
+
+			brands.POST("", manageCatalog, brandHandler.Create)
+			brands.PUT("/:slug", manageCatalog, brandHandler.Update)
+			brands.DELETE("/:slug", manageCatalog, brandHandler.Delete)
+		}
+
+		webhooks := api.Group("/webhooks", can(auth.PermWebhooksManage))
+		{
+			webhooks.GET("", webhookHandler.GetAll)
+			webhooks.GET("/dead-letters", webhookHandler.GetDeadLetters)
+			webhooks.GET("/:id", webhookHandler.GetByID)
+			webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+
+			webhooks.POST("", webhookHandler.Create)
+			webhooks.PUT("/:id", webhookHandler.Update)
+			webhooks.DELETE("/:id", webhookHandler.Delete)
+			webhooks.POST("/deliveries/:id/retry", webhookHandler.RetryDelivery)
+		}
+
+		tenants := api.Group("/tenants", can(auth.PermTenantsManage), tenant.PlatformOnly())
+		{
+			tenants.GET("", tenantHandler.GetAll)
+			tenants.GET("/:id", tenantHandler.GetByID)
+
+			tenants.POST("", tenantHandler.Create)
+			tenants.PUT("/:id", tenantHandler.Update)
+		}
+
+		apiKeys := api.Group("/api-keys", can(auth.PermAPIKeysManage))
+		{
+			apiKeys.GET("", apiKeyHandler.GetAll)
+			apiKeys.GET("/:id", apiKeyHandler.GetByID)
+			apiKeys.GET("/:id/usage", apiKeyHandler.GetUsage)
+
+			apiKeys.POST("", apiKeyHandler.Create)
+			apiKeys.DELETE("/:id", apiKeyHandler.Revoke)
 		}
 	}
 
 	if err := r.SetTrustedProxies(nil); err != nil {
-		log.Fatalf("Error setting trusted proxies: %v", err)
+		fatal("Error setting trusted proxies", err)
 	}
 
 	if err := r.Run(":8080"); err != nil {
-		log.Fatalf("Error running server: %v", err)
+		// Los spans en lote que falten se envían antes de salir.
+		_ = shutdownTracing(context.Background())
+		fatal("Error running server", err)
 	}
 
 }
+
+// fatal registra el error y termina el proceso.
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
+
+// alertNotifier arma los canales de alerta de stock bajo según el entorno; el log siempre está activo.
+func alertNotifier() domain.AlertNotifier {
+	notifiers := infrastructure.MultiNotifier{infrastructure.LogNotifier{}}
+
+	if url := config.GetEnv("ALERT_WEBHOOK_URL", ""); url != "" {
+		notifiers = append(notifiers, infrastructure.NewWebhookNotifier(url))
+	}
+
+	if addr, to := config.GetEnv("SMTP_ADDR", ""), config.GetEnv("ALERT_EMAIL_TO", ""); addr != "" && to != "" {
+		notifiers = append(notifiers, &infrastructure.SMTPNotifier{
+			Addr: addr,
+			From: config.GetEnv("ALERT_EMAIL_FROM", "alertas@mlsport.local"),
+			To:   strings.Split(to, ","),
+		})
+	}
+
+	return notifiers
+}
+
+// authenticators arma la validación de JWT según el entorno: HS256 con JWT_HS256_SECRET y/o RS256
+// con las claves del JWKS local JWT_JWKS_FILE.
+func authenticators() []auth.Authenticator {
+	secret := config.GetEnv("JWT_HS256_SECRET", "")
+	jwksFile := config.GetEnv("JWT_JWKS_FILE", "")
+	if secret == "" && jwksFile == "" {
+		return nil
+	}
+
+	var keys map[string]*rsa.PublicKey
+	if jwksFile != "" {
+		var err error
+		if keys, err = auth.LoadJWKS(jwksFile); err != nil {
+			fatal("Error cargando JWKS "+jwksFile, err)
+		}
+	}
+	verifier := auth.NewJWTVerifier([]byte(secret), keys)
+	verifier.Issuer = config.GetEnv("JWT_ISSUER", "")
+	verifier.Audience = config.GetEnv("JWT_AUDIENCE", "")
+	return []auth.Authenticator{verifier}
+}
+
+// rateLimiter arma los límites por cliente según el entorno: un balde por clase de ruta
+// (RATE_LIMIT_READS, _WRITES, _EXPORTS, _DASHBOARD, como "600/1m" o "600/1m:100") y la cuota
+// diaria RATE_LIMIT_DAILY_QUOTA.
+func rateLimiter() *ratelimit.Limiter {
+	defaults := map[ratelimit.Class]string{
+		ratelimit.ClassReads:     "600/1m",
+		ratelimit.ClassWrites:    "120/1m",
+		ratelimit.ClassExports:   "30/1m",
+		ratelimit.ClassDashboard: "120/1m",
+	}
+	limits := map[ratelimit.Class]ratelimit.Limit{}
+	for class, fallback := range defaults {
+		key := "RATE_LIMIT_" + strings.ToUpper(string(class))
+		limit, err := ratelimit.ParseLimit(config.GetEnv(key, fallback))
+		if err != nil {
+			fatal("Error en "+key, err)
+		}
+		limits[class] = limit
+	}
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), limits)
+	limiter.DailyQuota = int64(config.GetEnvInt("RATE_LIMIT_DAILY_QUOTA", 0))
+	// El listado completo de productos recorre toda la colección: cuenta como exportación.
+	limiter.Classify = func(c *gin.Context) ratelimit.Class {
+		path := c.FullPath()
+		switch {
+		case path == "/api/products" && c.Request.Method == http.MethodGet:
+			return ratelimit.ClassExports
+		case strings.HasPrefix(path, "/api/products/dashboard"), strings.HasPrefix(path, "/api/products/metrics"):
+			return ratelimit.ClassDashboard
+		}
+		return ratelimit.ClassifyMethod(c)
+	}
+	return limiter
+}
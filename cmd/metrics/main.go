@@ -0,0 +1,72 @@
+// Comando de mantenimiento del modelo de lectura de métricas, por tienda (por defecto, "default").
+//
+//	go run ./cmd/metrics rebuild [tienda]   recalcula los contadores desde la colección de productos
+//	go run ./cmd/metrics check [tienda]     compara los contadores guardados con la agregación en vivo
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mlsport/config"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/infrastructure"
+	"mlsport/internal/product/usecase"
+	"mlsport/internal/tenant"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "uso: metrics rebuild|check [tienda]")
+		os.Exit(2)
+	}
+	tenantID := tenant.Default
+	if len(os.Args) > 2 {
+		tenantID = os.Args[2]
+	}
+	if !tenant.ValidID(tenantID) {
+		fmt.Fprintf(os.Stderr, "tienda %q inválida: %v\n", tenantID, tenant.ErrInvalidID)
+		os.Exit(2)
+	}
+
+	config.InitMongo()
+	counters := usecase.NewCountersService(infrastructure.NewMongoCounterRepo())
+
+	switch os.Args[1] {
+	case "rebuild":
+		result, err := counters.Rebuild(tenantID)
+		if err != nil {
+			fatal("Error reconstruyendo contadores", err)
+		}
+		printJSON(result)
+
+	case "check":
+		check, err := counters.Check(tenantID)
+		if err != nil {
+			fatal("Error verificando contadores", err)
+		}
+		printJSON(check)
+		if !check.Consistent {
+			os.Exit(1)
+		}
+
+	default:
+		fmt.Fprintf(os.Stderr, "subcomando desconocido %q, use rebuild o check\n", os.Args[1])
+		os.Exit(2)
+	}
+}
+
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fatal("Error escribiendo resultado", err)
+	}
+}
+
+// fatal registra el error y termina el proceso.
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+func GetEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func GetEnvInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		slog.Warn("Variable de entorno inválida, se usa el valor por defecto", "key", key, "value", v, "default", fallback)
+		return fallback
+	}
+	return n
+}
+
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		slog.Warn("Variable de entorno inválida, se usa el valor por defecto", "key", key, "value", v, "default", fallback.String())
+		return fallback
+	}
+	return d
+}
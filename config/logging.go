@@ -0,0 +1,20 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+
+	"mlsport/internal/logging"
+)
+
+// InitLogging configura el logger por defecto según LOG_LEVEL (debug, info, warn, error) y
+// LOG_FORMAT (json o text). También recibe lo que se siga escribiendo con el paquete log.
+func InitLogging() *slog.Logger {
+	level, err := logging.ParseLevel(GetEnv("LOG_LEVEL", "info"))
+	logger := logging.New(os.Stderr, level, GetEnv("LOG_FORMAT", logging.FormatJSON))
+	slog.SetDefault(logger)
+	if err != nil {
+		logger.Warn("LOG_LEVEL inválido, se usa info", logging.Err(err))
+	}
+	return logger
+}
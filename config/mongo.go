@@ -2,50 +2,119 @@ package config
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
 	"time"
 
 	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
 var MongoClient *mongo.Client
 
+// transactionsSupported se detecta una vez en InitMongo; WithTransaction lo consulta.
+var transactionsSupported = true
+
 func InitMongo() *mongo.Client {
 	// Cargar variables del .env
 	if err := godotenv.Load(); err != nil {
-		log.Println("No se pudo cargar el archivo .env")
+		slog.Info("No se pudo cargar el archivo .env")
 	}
 
 	mongoURI := os.Getenv("MONGO_URI")
 
 	if mongoURI == "" {
-		log.Fatal("Defina los ENV")
+		fatal("Defina MONGO_URI en el entorno")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	// Cada comando queda como span hijo del que esté en el ctx (petición o método del servicio).
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI).SetMonitor(otelmongo.NewMonitor()))
 	if err != nil {
-		log.Fatalf("Error al conectar con MongoDB: %v", err)
+		fatal("Error al conectar con MongoDB", "error", err)
 	}
 
 	if err := client.Ping(ctx, nil); err != nil {
-		log.Fatalf("❌ Ping a MongoDB falló: %v", err)
+		fatal("❌ Ping a MongoDB falló", "error", err)
 	}
 
-	log.Println("Conexión a MongoDB establecida")
+	slog.Info("Conexión a MongoDB establecida")
 
 	MongoClient = client
+	detectTransactions(ctx, client)
 	return client
 }
+
+// detectTransactions consulta el comando hello: solo un replica set o un mongos aceptan
+// transacciones. Sin ellas las escrituras de productos, el outbox y los contadores dejan de ser
+// atómicas, así que se avisa al arrancar; con MONGO_REQUIRE_TRANSACTIONS=true el proceso termina.
+func detectTransactions(ctx context.Context, client *mongo.Client) {
+	var hello bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		slog.Warn("No se pudo comprobar si MongoDB soporta transacciones", "error", err)
+		return
+	}
+	if supportsTransactions(hello) {
+		return
+	}
+
+	transactionsSupported = false
+	if GetEnv("MONGO_REQUIRE_TRANSACTIONS", "false") == "true" {
+		fatal("MongoDB no soporta transacciones (no es un replica set) y MONGO_REQUIRE_TRANSACTIONS=true")
+	}
+	slog.Warn("MongoDB no soporta transacciones (no es un replica set): productos, outbox, contadores y " +
+		"categorías se escriben sin atomicidad. Use un replica set en producción o MONGO_REQUIRE_TRANSACTIONS=true " +
+		"para no arrancar así")
+}
+
+// supportsTransactions interpreta la respuesta de hello: los miembros de un replica set traen
+// setName y mongos responde msg "isdbgrid".
+func supportsTransactions(hello bson.M) bool {
+	if name, _ := hello["setName"].(string); name != "" {
+		return true
+	}
+	msg, _ := hello["msg"].(string)
+	return msg == "isdbgrid"
+}
+
+// TransactionsSupported indica si el servidor detectado al arrancar acepta transacciones.
+func TransactionsSupported() bool {
+	return transactionsSupported
+}
 func GetDB() *mongo.Database {
 	dbName := os.Getenv("MONGO_DB_NAME")
 	if dbName == "" {
-		log.Fatal("❌ MONGO_DB_NAME no está definido en el entorno")
+		fatal("❌ MONGO_DB_NAME no está definido en el entorno")
 	}
 	return MongoClient.Database(dbName)
 }
+
+// WithTransaction ejecuta fn dentro de una transacción. Si al arrancar se detectó que el servidor
+// no es un replica set (ya se avisó entonces), fn se ejecuta sin ella.
+func WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !transactionsSupported {
+		return fn(ctx)
+	}
+
+	session, err := MongoClient.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+// fatal registra el error y termina el proceso, como log.Fatal.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,14 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSupportsTransactions(t *testing.T) {
+	assert.True(t, supportsTransactions(bson.M{"isWritablePrimary": true, "setName": "rs0"}))
+	assert.True(t, supportsTransactions(bson.M{"isWritablePrimary": true, "msg": "isdbgrid"}))
+	assert.False(t, supportsTransactions(bson.M{"isWritablePrimary": true}))
+}
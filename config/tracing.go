@@ -0,0 +1,26 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"mlsport/internal/logging"
+	"mlsport/internal/tracing"
+)
+
+// InitTracing configura OpenTelemetry según OTEL_TRACES_EXPORTER (otlp, stdout o none, por
+// defecto). Hay que llamarlo antes de InitMongo para que los comandos de Mongo queden trazados.
+// La función devuelta envía los spans pendientes.
+func InitTracing(ctx context.Context) func(context.Context) error {
+	exporter := GetEnv("OTEL_TRACES_EXPORTER", tracing.ExporterNone)
+	shutdown, err := tracing.Setup(ctx, exporter, "mlsport", os.Stdout)
+	if err != nil {
+		slog.Error("No se pudo configurar el trazado, se continúa sin trazas", logging.Err(err))
+		return func(context.Context) error { return nil }
+	}
+	if exporter != tracing.ExporterNone {
+		slog.Info("Trazas de OpenTelemetry activas", "exporter", exporter)
+	}
+	return shutdown
+}
@@ -15,30 +15,55 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/products": {
+        "/api-keys": {
             "get": {
-                "description": "Devuelve una lista completa de productos registrados en el sistema.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Devuelve las claves con su prefijo, permisos, vencimiento, último uso y total de usos; nunca la clave.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Productos"
+                    "API keys"
                 ],
-                "summary": "Obtener todos los productos",
+                "summary": "Listar API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Product"
+                                "$ref": "#/definitions/mlsport_internal_apikey_domain.APIKey"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Permite registrar un nuevo producto en la base de datos.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Crea una clave para un cliente sin login (escáner, integración) con los permisos indicados,\npor ejemplo [\"product:read\", \"product:stock\"], y un vencimiento opcional. La clave solo se\ndevuelve en esta respuesta; se envía en la cabecera X-API-Key.",
                 "consumes": [
                     "application/json"
                 ],
@@ -46,17 +71,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Productos"
+                    "API keys"
                 ],
-                "summary": "Crear nuevo producto",
+                "summary": "Crear API key",
                 "parameters": [
                     {
-                        "description": "Producto a registrar",
-                        "name": "producto",
+                        "description": "Nombre, permisos (scopes) y expires_at opcional",
+                        "name": "apikey",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "$ref": "#/definitions/mlsport_internal_apikey_domain.APIKey"
                         }
                     }
                 ],
@@ -64,7 +89,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "$ref": "#/definitions/mlsport_internal_apikey_domain.APIKey"
                         }
                     },
                     "400": {
@@ -75,48 +100,94 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/products/categories": {
+        "/api-keys/{id}": {
             "get": {
-                "description": "Retorna una lista de categorías derivadas de los productos registrados.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Productos"
+                    "API keys"
+                ],
+                "summary": "Consultar una API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la API key",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Listar categorías únicas",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
+                            "$ref": "#/definitions/mlsport_internal_apikey_domain.APIKey"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
                                 "type": "string"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/products/categories/{category}": {
-            "get": {
-                "description": "Filtra los productos según la categoría proporcionada.",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "La clave deja de aceptarse de inmediato; se conserva con sus contadores.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Productos"
+                    "API keys"
                 ],
-                "summary": "Obtener productos por categoría",
+                "summary": "Revocar API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Nombre de la categoría",
-                        "name": "category",
+                        "description": "ID de la API key",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -125,72 +196,190 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Product"
+                            "$ref": "#/definitions/mlsport_internal_apikey_domain.APIKey"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/products/dashboard": {
+        "/api-keys/{id}/usage": {
             "get": {
-                "description": "Consulta combinada que obtiene productos y métricas en paralelo.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Peticiones autenticadas con la clave por día (UTC). Los usos se guardan cada pocos segundos.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Productos"
+                    "API keys"
+                ],
+                "summary": "Uso diario de una API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la API key",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Días hacia atrás, hoy incluido (30 por defecto, hasta 366)",
+                        "name": "days",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Dashboard de productos y métricas",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_apikey_domain.DailyUsage"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/products/metrics": {
+        "/brands": {
             "get": {
-                "description": "Devuelve métricas agregadas como total de productos, promedio de precios y stock acumulado.",
+                "description": "Devuelve las marcas registradas con el número de productos de cada una.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Productos"
+                    "Marcas"
                 ],
-                "summary": "Métricas de productos",
+                "summary": "Listar marcas",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_brand_domain.Brand"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Registra una marca canónica; los productos con variantes del nombre se normalizan a ella.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Marcas"
+                ],
+                "summary": "Crear marca",
+                "parameters": [
+                    {
+                        "description": "Marca a registrar",
+                        "name": "marca",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_brand_domain.Brand"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_brand_domain.Brand"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/products/{id}": {
+        "/brands/{slug}": {
             "get": {
-                "description": "Retorna la información detallada de un producto específico.",
+                "description": "Retorna una marca a partir de su slug.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Productos"
+                    "Marcas"
                 ],
-                "summary": "Consultar un producto por ID",
+                "summary": "Consultar una marca",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID del producto",
-                        "name": "id",
+                        "description": "Slug de la marca",
+                        "name": "slug",
                         "in": "path",
                         "required": true
                     }
@@ -199,7 +388,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "$ref": "#/definitions/mlsport_internal_brand_domain.Brand"
                         }
                     },
                     "404": {
@@ -214,7 +403,15 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Actualiza todos los campos de un producto existente con los nuevos valores proporcionados.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Reemplaza nombre, slug, logo y país de una marca; si cambia el nombre, también actualiza los productos de la tienda.",
                 "consumes": [
                     "application/json"
                 ],
@@ -222,24 +419,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Productos"
+                    "Marcas"
                 ],
-                "summary": "Reemplazar producto existente",
+                "summary": "Actualizar marca",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID del producto",
-                        "name": "id",
+                        "description": "Slug de la marca",
+                        "name": "slug",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Datos actualizados del producto",
-                        "name": "producto",
+                        "description": "Datos actualizados",
+                        "name": "marca",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "$ref": "#/definitions/mlsport_internal_brand_domain.Brand"
                         }
                     }
                 ],
@@ -247,11 +444,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "$ref": "#/definitions/mlsport_internal_brand_domain.Brand"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -262,16 +459,24 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Borra un producto según su ID.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Borra una marca del catálogo; los productos conservan el nombre guardado.",
                 "tags": [
-                    "Productos"
+                    "Marcas"
                 ],
-                "summary": "Eliminar producto",
+                "summary": "Eliminar marca",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID del producto",
-                        "name": "id",
+                        "description": "Slug de la marca",
+                        "name": "slug",
                         "in": "path",
                         "required": true
                     }
@@ -287,60 +492,1984 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Permite actualizar solo algunos campos de un producto existente.",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "Devuelve todas las categorías registradas, sin jerarquía.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Productos"
-                ],
-                "summary": "Actualizar parcialmente un producto",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID del producto",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Campos a modificar (por ejemplo: stock, price)",
-                        "name": "fields",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object"
-                        }
-                    }
+                    "Categorías"
                 ],
+                "summary": "Listar categorías administradas",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_category_domain.Category"
                             }
                         }
                     }
                 }
-            }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Registra una categoría; el slug se genera a partir del nombre si no se envía.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categorías"
+                ],
+                "summary": "Crear categoría",
+                "parameters": [
+                    {
+                        "description": "Categoría a registrar",
+                        "name": "categoria",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_category_domain.Category"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_category_domain.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/history": {
+            "get": {
+                "description": "Lista los renombrados y fusiones realizados, del más reciente al más antiguo.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categorías"
+                ],
+                "summary": "Historial de categorías",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_category_domain.Change"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mueve productos y subcategorías de las categorías origen a la categoría destino y elimina las de origen.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categorías"
+                ],
+                "summary": "Fusionar categorías",
+                "parameters": [
+                    {
+                        "description": "Slugs de origen y destino",
+                        "name": "datos",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_category_delivery.mergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/tree": {
+            "get": {
+                "description": "Devuelve la jerarquía de categorías con sus subcategorías anidadas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categorías"
+                ],
+                "summary": "Árbol de categorías",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_category_domain.Node"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{slug}": {
+            "get": {
+                "description": "Retorna una categoría a partir de su slug.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categorías"
+                ],
+                "summary": "Consultar una categoría",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug de la categoría",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_category_domain.Category"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Borra una categoría sin subcategorías.",
+                "tags": [
+                    "Categorías"
+                ],
+                "summary": "Eliminar categoría",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug de la categoría",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{slug}/rename": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Cambia nombre y slug de una categoría y actualiza todos los productos asociados en una sola operación.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categorías"
+                ],
+                "summary": "Renombrar categoría",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug de la categoría",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nuevo nombre (y slug opcional)",
+                        "name": "datos",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_category_delivery.renameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Devuelve una lista completa de productos registrados en el sistema.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Obtener todos los productos",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Filtrar por marca (se admite una lista separada por comas)",
+                        "name": "brand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Filtrar por categoría",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_product_domain.Product"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Permite registrar un nuevo producto en la base de datos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Crear nuevo producto",
+                "parameters": [
+                    {
+                        "description": "Producto a registrar",
+                        "name": "producto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_product_domain.Product"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar sin duplicar el producto",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_product_domain.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/cache/stats": {
+            "get": {
+                "description": "Aciertos, fallos, consultas compartidas e invalidaciones de la caché de métricas y listados.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Estadísticas de la caché",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_product_domain.CacheStats"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/categories": {
+            "get": {
+                "description": "Retorna una lista de categorías derivadas de los productos registrados.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Listar categorías únicas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/categories/{category}": {
+            "get": {
+                "description": "Filtra los productos según la categoría proporcionada.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Obtener productos por categoría",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nombre de la categoría",
+                        "name": "category",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Incluir productos de las subcategorías",
+                        "name": "descendants",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_product_domain.Product"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/dashboard": {
+            "get": {
+                "description": "Consulta los widgets en paralelo, cada uno con su propio tiempo límite. Si un widget falla\nse devuelven los demás y el fallido trae su campo error (partial = true).\nWidgets: products, metrics, top_categories, low_stock, recent_changes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Dashboard de productos y métricas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Widgets separados por comas (por defecto todos)",
+                        "name": "widgets",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_product_domain.Dashboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_product_domain.Dashboard"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/dashboard/stream": {
+            "get": {
+                "description": "Emite un evento \"product\" por cada alta, modificación o baja y un evento \"metrics\" con las\nmétricas recalculadas. Al conectarse sin Last-Event-ID se recibe primero el estado actual.\nCon Last-Event-ID (o ?last_event_id=) se reenvían los eventos perdidos si siguen en el historial.\nEnvía un comentario de heartbeat periódico; un cliente demasiado lento se desconecta y debe reconectarse.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Dashboard en vivo (Server-Sent Events)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Último evento recibido",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último evento recibido (alternativa al header)",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "stream de eventos",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/low-stock": {
+            "get": {
+                "description": "Lista los productos cuyo stock no supera su umbral de reposición (o el umbral general).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Productos con stock bajo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Umbral general a usar para productos sin umbral propio",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_product_domain.Product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/metrics": {
+            "get": {
+                "description": "Devuelve métricas agregadas como total de productos, promedio de precios y stock acumulado,\njunto con el desglose por categoría y por marca (cantidad, stock, valor del stock y precios).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Métricas de productos",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cantidad de categorías y marcas en el desglose (por defecto 5)",
+                        "name": "top",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Filtrar por marca",
+                        "name": "brand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Filtrar por categoría",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/metrics/counters": {
+            "get": {
+                "description": "Modelo de lectura actualizado en cada escritura: totales, stock, suma de precios y productos por categoría.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Contadores de métricas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/products/metrics/counters/check": {
+            "get": {
+                "description": "Compara los contadores guardados con la agregación en vivo y lista las diferencias.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Verificar contadores de métricas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_product_domain.CounterCheck"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/metrics/history": {
+            "get": {
+                "description": "Serie temporal de los snapshots diarios de métricas (cierre de cada periodo).\nCon compare_from y compare_to agrega la diferencia entre el cierre de ambos periodos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Histórico de métricas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fecha inicial YYYY-MM-DD (por defecto hace 30 días)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha final YYYY-MM-DD (por defecto hoy)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "day, week o month (por defecto day)",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Inicio del periodo a comparar YYYY-MM-DD",
+                        "name": "compare_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fin del periodo a comparar YYYY-MM-DD",
+                        "name": "compare_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/metrics/prices": {
+            "get": {
+                "description": "Devuelve mediana, percentiles 25/75/90 e histograma de precios. Con boundaries se usan\nlímites fijos; si no, los precios se reparten en buckets grupos automáticos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Distribución de precios",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Límites del histograma en orden ascendente, p. ej. 0,50,100,200",
+                        "name": "boundaries",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cantidad de grupos automáticos (por defecto 10)",
+                        "name": "buckets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Filtrar por marca",
+                        "name": "brand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Filtrar por categoría",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_product_domain.PriceDistribution"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/stock/ws": {
+            "get": {
+                "description": "Canal WebSocket para suscribirse a productos o categorías y recibir cambios de stock y precio.\nRequiere token (Authorization: Bearer o ?token=). Cada evento trae seq; al reconectar se envía\n\"since\" con el último recibido para recuperar lo perdido.",
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Cambios de stock en tiempo real (WebSocket)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token de acceso",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Retorna la información detallada de un producto específico.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Consultar un producto por ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del producto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_product_domain.Product"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Actualiza todos los campos de un producto existente con los nuevos valores proporcionados.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Reemplazar producto existente",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del producto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos actualizados del producto",
+                        "name": "producto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_product_domain.Product"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_product_domain.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Borra un producto según su ID.",
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Eliminar producto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del producto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Permite actualizar solo algunos campos de un producto existente.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Productos"
+                ],
+                "summary": "Actualizar parcialmente un producto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del producto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Campos a modificar (por ejemplo: stock, price)",
+                        "name": "fields",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar el ajuste sin aplicarlo dos veces",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tenants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Tiendas alojadas en el despliegue. Solo para usuarios de la plataforma (tenant * en el token).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tiendas"
+                ],
+                "summary": "Listar tiendas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_tenant_domain.Tenant"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Crea una tienda con catálogo propio. El ID (minúsculas, números y guiones) es el que llevan\nlos tokens en el claim \"tenant\" y la cabecera X-Tenant-ID, y no se puede cambiar.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tiendas"
+                ],
+                "summary": "Dar de alta una tienda",
+                "parameters": [
+                    {
+                        "description": "ID y nombre",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_tenant_domain.Tenant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_tenant_domain.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tenants/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tiendas"
+                ],
+                "summary": "Consultar una tienda",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la tienda",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_tenant_domain.Tenant"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Cambia el nombre o la deshabilita (disabled): una tienda deshabilitada rechaza todas las\npeticiones pero conserva sus datos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tiendas"
+                ],
+                "summary": "Actualizar una tienda",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la tienda",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nombre y estado",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_tenant_domain.Tenant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_tenant_domain.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Devuelve los webhooks registrados, sin sus secretos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Listar webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_webhook_domain.Webhook"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Suscribe una URL a tipos de evento (p. ej. product.price_changed, o \"*\" para todos).\nCada entrega lleva X-Mlsport-Signature: t=\u003cunix\u003e,v1=\u003cHMAC-SHA256 de \"\u003ct\u003e.\u003ccuerpo\u003e\" con el secreto\u003e.\nSi no se envía secreto se genera uno; esta es la única respuesta que lo incluye.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Registrar webhook",
+                "parameters": [
+                    {
+                        "description": "URL, eventos y secreto opcional",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_webhook_domain.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_webhook_domain.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/dead-letters": {
+            "get": {
+                "description": "Entregas que agotaron sus reintentos, de todos los webhooks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Entregas muertas",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Máximo de entregas (50 por defecto, hasta 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_webhook_domain.Delivery"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Vuelve a poner en cola una entrega, por ejemplo de la lista de muertas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Reintentar entrega",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la entrega",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_webhook_domain.Delivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Consultar un webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_webhook_domain.Webhook"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Reemplaza URL, eventos y estado (disabled). Sin secreto se conserva el actual.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Actualizar webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos actualizados",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_webhook_domain.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mlsport_internal_webhook_domain.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Borra el webhook; sus entregas pendientes pasan a la lista de muertas.",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Eliminar webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Entregas del webhook, de la más reciente a la más antigua, con cada intento (código, error y duración).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Registro de entregas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, succeeded o dead",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de entregas (50 por defecto, hasta 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/mlsport_internal_webhook_domain.Delivery"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
-        "domain.Product": {
+        "internal_category_delivery.mergeRequest": {
+            "type": "object",
+            "required": [
+                "sources",
+                "target"
+            ],
+            "properties": {
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "target": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_category_delivery.renameRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_apikey_domain.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/mlsport_internal_auth.Permission"
+                    }
+                },
+                "tenant": {
+                    "description": "Tenant es la tienda a la que la clave da acceso; se toma del contexto al crearla.",
+                    "type": "string"
+                },
+                "usage_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "mlsport_internal_apikey_domain.DailyUsage": {
             "type": "object",
             "properties": {
-                "brand": {
+                "count": {
+                    "type": "integer"
+                },
+                "day": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_auth.Permission": {
+            "type": "string",
+            "enum": [
+                "product:read",
+                "product:create",
+                "product:edit",
+                "product:price",
+                "product:stock",
+                "product:delete",
+                "catalog:manage",
+                "webhooks:manage",
+                "apikeys:manage",
+                "tenants:manage"
+            ],
+            "x-enum-varnames": [
+                "PermProductRead",
+                "PermProductCreate",
+                "PermProductEdit",
+                "PermProductPrice",
+                "PermProductStock",
+                "PermProductDelete",
+                "PermCatalogManage",
+                "PermWebhooksManage",
+                "PermAPIKeysManage",
+                "PermTenantsManage"
+            ]
+        },
+        "mlsport_internal_brand_domain.Brand": {
+            "type": "object",
+            "properties": {
+                "country": {
                     "type": "string"
                 },
-                "category": {
+                "id": {
+                    "type": "string"
+                },
+                "logo_url": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_count": {
+                    "type": "integer"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_category_domain.Breadcrumb": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_category_domain.Category": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_category_domain.Change": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "products_updated": {
+                    "type": "integer"
+                },
+                "to": {
                     "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_category_domain.Node": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/mlsport_internal_category_domain.Node"
+                    }
                 },
                 "id": {
                     "type": "string"
@@ -348,17 +2477,313 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
-                "objectID": {
+                "parent_id": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_product_domain.CacheStats": {
+            "type": "object",
+            "properties": {
+                "coalesced": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "integer"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "invalidations": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "ttl_seconds": {
+                    "type": "number"
+                }
+            }
+        },
+        "mlsport_internal_product_domain.CounterCheck": {
+            "type": "object",
+            "properties": {
+                "consistent": {
+                    "type": "boolean"
+                },
+                "differences": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "live": {
+                    "$ref": "#/definitions/mlsport_internal_product_domain.MetricsCounters"
+                },
+                "stored": {
+                    "$ref": "#/definitions/mlsport_internal_product_domain.MetricsCounters"
+                }
+            }
+        },
+        "mlsport_internal_product_domain.Dashboard": {
+            "type": "object",
+            "properties": {
+                "generated_at": {
+                    "type": "string"
+                },
+                "partial": {
+                    "type": "boolean"
+                },
+                "widgets": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/mlsport_internal_product_domain.DashboardWidget"
+                    }
+                }
+            }
+        },
+        "mlsport_internal_product_domain.DashboardWidget": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "elapsed_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_product_domain.MetricsCounters": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "price_sum": {
+                    "type": "number"
+                },
+                "total_products": {
+                    "type": "integer"
+                },
+                "total_stock": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_product_domain.PriceBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                }
+            }
+        },
+        "mlsport_internal_product_domain.PriceDistribution": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "histogram": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/mlsport_internal_product_domain.PriceBucket"
+                    }
+                },
+                "max": {
+                    "type": "number"
+                },
+                "median": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "p25": {
+                    "type": "number"
+                },
+                "p75": {
+                    "type": "number"
+                },
+                "p90": {
+                    "type": "number"
+                }
+            }
+        },
+        "mlsport_internal_product_domain.Product": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "breadcrumbs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/mlsport_internal_category_domain.Breadcrumb"
+                    }
+                },
+                "category": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
                     "type": "string"
                 },
                 "price": {
                     "type": "number"
                 },
+                "reorder_threshold": {
+                    "description": "ReorderThreshold es el stock mínimo antes de alertar; 0 usa el umbral general.",
+                    "type": "integer"
+                },
                 "stock": {
                     "type": "integer"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt lo fija el repositorio en cada alta o modificación.",
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_tenant_domain.Tenant": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_webhook_domain.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/mlsport_internal_webhook_domain.DeliveryAttempt"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "mlsport_internal_webhook_domain.DeliveryAttempt": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "mlsport_internal_webhook_domain.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "APIKeyAuth": {
+            "description": "API key para clientes sin login (escáneres, integraciones); ver /api-keys.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT como \"Bearer \u003ctoken\u003e\"; las lecturas pueden ser públicas según AUTH_PUBLIC_READS.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
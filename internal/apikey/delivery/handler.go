@@ -0,0 +1,148 @@
+package delivery
+
+import (
+	"errors"
+	"mlsport/internal/apikey/domain"
+	"mlsport/internal/apikey/usecase"
+	"mlsport/internal/auth"
+	"mlsport/internal/logging"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	Service *usecase.APIKeyService
+}
+
+func NewAPIKeyHandler(s *usecase.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{Service: s}
+}
+
+// GetAll godoc
+// @Summary Listar API keys
+// @Description Devuelve las claves con su prefijo, permisos, vencimiento, último uso y total de usos; nunca la clave.
+// @Tags API keys
+// @Produce json
+// @Success 200 {array} domain.APIKey
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api-keys [get]
+func (h *APIKeyHandler) GetAll(c *gin.Context) {
+	list, err := h.Service.GetAll(c.Request.Context())
+	if err != nil {
+		logging.InternalError(c, "no se pudieron obtener las API keys", err)
+		return
+	}
+	if list == nil {
+		list = []domain.APIKey{}
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GetByID godoc
+// @Summary Consultar una API key
+// @Tags API keys
+// @Produce json
+// @Param id path string true "ID de la API key"
+// @Success 200 {object} domain.APIKey
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api-keys/{id} [get]
+func (h *APIKeyHandler) GetByID(c *gin.Context) {
+	k, err := h.Service.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "no se pudo obtener la API key")
+		return
+	}
+	c.JSON(http.StatusOK, k)
+}
+
+// Create godoc
+// @Summary Crear API key
+// @Description Crea una clave para un cliente sin login (escáner, integración) con los permisos indicados,
+// @Description por ejemplo ["product:read", "product:stock"], y un vencimiento opcional. La clave solo se
+// @Description devuelve en esta respuesta; se envía en la cabecera X-API-Key.
+// @Tags API keys
+// @Accept json
+// @Produce json
+// @Param apikey body domain.APIKey true "Nombre, permisos (scopes) y expires_at opcional"
+// @Success 201 {object} domain.APIKey
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var input domain.APIKey
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+		return
+	}
+	if err := h.Service.Create(c.Request.Context(), &input); err != nil {
+		respondError(c, err, "no se pudo crear la API key")
+		return
+	}
+	c.JSON(http.StatusCreated, input)
+}
+
+// Revoke godoc
+// @Summary Revocar API key
+// @Description La clave deja de aceptarse de inmediato; se conserva con sus contadores.
+// @Tags API keys
+// @Produce json
+// @Param id path string true "ID de la API key"
+// @Success 200 {object} domain.APIKey
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	k, err := h.Service.Revoke(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "no se pudo revocar la API key")
+		return
+	}
+	c.JSON(http.StatusOK, k)
+}
+
+// GetUsage godoc
+// @Summary Uso diario de una API key
+// @Description Peticiones autenticadas con la clave por día (UTC). Los usos se guardan cada pocos segundos.
+// @Tags API keys
+// @Produce json
+// @Param id path string true "ID de la API key"
+// @Param days query int false "Días hacia atrás, hoy incluido (30 por defecto, hasta 366)"
+// @Success 200 {array} domain.DailyUsage
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api-keys/{id}/usage [get]
+func (h *APIKeyHandler) GetUsage(c *gin.Context) {
+	days, _ := strconv.Atoi(c.Query("days"))
+	usage, err := h.Service.Usage(c.Request.Context(), c.Param("id"), days)
+	if err != nil {
+		respondError(c, err, "no se pudo obtener el uso de la API key")
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidAPIKey), errors.Is(err, domain.ErrUnknownScope), errors.Is(err, domain.ErrInvalidExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case auth.IsAuthError(err):
+		auth.AbortWithError(c, err)
+	default:
+		logging.InternalError(c, fallback, err)
+	}
+}
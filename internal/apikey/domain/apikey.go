@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"mlsport/internal/auth"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key no encontrada")
+	ErrInvalidAPIKey  = errors.New("la API key necesita un nombre y al menos un permiso")
+	ErrUnknownScope   = errors.New("permiso desconocido")
+	ErrInvalidExpiry  = errors.New("la fecha de vencimiento debe ser futura")
+	ErrKeyRevoked     = errors.New("API key revocada")
+	ErrKeyExpired     = errors.New("API key vencida")
+)
+
+// HeaderAPIKey es la cabecera con la que los clientes envían la clave.
+const HeaderAPIKey = "X-API-Key"
+
+// KeyPrefix identifica las claves de mlsport, por ejemplo en escáneres de secretos.
+const KeyPrefix = "mlk_"
+
+// APIKey es la credencial de un cliente sin login interactivo (escáneres, integraciones). Solo se
+// guarda el hash de la clave; Key lleva la clave en claro únicamente en la respuesta de alta.
+type APIKey struct {
+	ID       string             `json:"id" bson:"-"`
+	ObjectID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	// Tenant es la tienda a la que la clave da acceso; se toma del contexto al crearla.
+	Tenant     string            `json:"tenant" bson:"tenant"`
+	Name       string            `json:"name" bson:"name"`
+	Key        string            `json:"key,omitempty" bson:"-"`
+	Prefix     string            `json:"prefix" bson:"prefix"`
+	Hash       string            `json:"-" bson:"hash"`
+	Scopes     []auth.Permission `json:"scopes" bson:"scopes"`
+	ExpiresAt  *time.Time        `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	CreatedAt  time.Time         `json:"created_at" bson:"created_at"`
+	CreatedBy  string            `json:"created_by" bson:"created_by"`
+	RevokedAt  *time.Time        `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	LastUsedAt *time.Time        `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	UsageCount int64             `json:"usage_count" bson:"usage_count"`
+}
+
+// Check indica si la clave puede usarse en at.
+func (k *APIKey) Check(at time.Time) error {
+	if k.RevokedAt != nil {
+		return ErrKeyRevoked
+	}
+	if k.ExpiresAt != nil && !at.Before(*k.ExpiresAt) {
+		return ErrKeyExpired
+	}
+	return nil
+}
+
+// DailyUsage es el uso de una clave en un día (UTC, "2006-01-02").
+type DailyUsage struct {
+	Day   string `json:"day" bson:"day"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// HashKey es el hash con el que se guarda y se busca la clave. Las claves son aleatorias de 192
+// bits, así que no hace falta un hash lento como para contraseñas.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Day devuelve el día UTC de at en el formato de DailyUsage.
+func Day(at time.Time) string {
+	return at.UTC().Format("2006-01-02")
+}
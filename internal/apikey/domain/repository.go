@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type APIKeyRepository interface {
+	Create(k *APIKey) error
+	// FindAll lista las claves de una tienda.
+	FindAll(tenantID string) ([]APIKey, error)
+	FindByID(id string) (*APIKey, error)
+	FindByHash(hash string) (*APIKey, error)
+	Revoke(id string, at time.Time) error
+	// RecordUsage suma count usos en day al total y al contador diario, y actualiza last_used_at.
+	RecordUsage(id, day string, count int64, lastUsed time.Time) error
+	// Usage devuelve los contadores diarios desde since (inclusive), del más antiguo al más nuevo.
+	Usage(id, since string) ([]DailyUsage, error)
+}
@@ -0,0 +1,195 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mlsport/config"
+	"mlsport/internal/apikey/domain"
+	"mlsport/internal/logging"
+	"mlsport/internal/tenant"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoAPIKeyRepo guarda las claves en api_keys y sus contadores diarios en api_key_usage.
+type MongoAPIKeyRepo struct {
+	CollectionName      string
+	UsageCollectionName string
+}
+
+func NewMongoAPIKeyRepo() *MongoAPIKeyRepo {
+	return &MongoAPIKeyRepo{CollectionName: "api_keys", UsageCollectionName: "api_key_usage"}
+}
+
+// EnsureIndexes crea el índice único del hash, con el que se busca la clave en cada petición, y el
+// de los contadores diarios.
+func (r *MongoAPIKeyRepo) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	db := config.GetDB()
+	_, err := db.Collection(r.CollectionName).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection(r.UsageCollectionName).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "key_id", Value: 1}, {Key: "day", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *MongoAPIKeyRepo) Create(k *domain.APIKey) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	res, err := collection.InsertOne(ctx, k)
+	if err != nil {
+		return err
+	}
+
+	k.ObjectID = res.InsertedID.(primitive.ObjectID)
+	k.ID = k.ObjectID.Hex()
+	return nil
+}
+
+func (r *MongoAPIKeyRepo) FindAll(tenantID string) ([]domain.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var result []domain.APIKey
+	collection := config.GetDB().Collection(r.CollectionName)
+	filter := bson.M{"tenant": tenantID}
+	if tenantID == tenant.Default {
+		// Las claves anteriores a los tenants no tienen el campo y son de la tienda por defecto.
+		filter["tenant"] = bson.M{"$in": bson.A{tenant.Default, nil}}
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var k domain.APIKey
+		if err := cursor.Decode(&k); err != nil {
+			continue
+		}
+		k.ID = k.ObjectID.Hex()
+		if k.Tenant == "" {
+			k.Tenant = tenant.Default
+		}
+		result = append(result, k)
+	}
+
+	return result, nil
+}
+
+func (r *MongoAPIKeyRepo) FindByID(id string) (*domain.APIKey, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	return r.findOne(bson.M{"_id": objID})
+}
+
+func (r *MongoAPIKeyRepo) FindByHash(hash string) (*domain.APIKey, error) {
+	return r.findOne(bson.M{"hash": hash})
+}
+
+func (r *MongoAPIKeyRepo) findOne(filter bson.M) (*domain.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var k domain.APIKey
+	err := config.GetDB().Collection(r.CollectionName).FindOne(ctx, filter).Decode(&k)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k.ID = k.ObjectID.Hex()
+	if k.Tenant == "" {
+		k.Tenant = tenant.Default
+	}
+	return &k, nil
+}
+
+func (r *MongoAPIKeyRepo) Revoke(id string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"revoked_at": at}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *MongoAPIKeyRepo) RecordUsage(id, day string, count int64, lastUsed time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	db := config.GetDB()
+	_, err = db.Collection(r.UsageCollectionName).UpdateOne(ctx,
+		bson.M{"key_id": id, "day": day},
+		bson.M{"$inc": bson.M{"count": count}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection(r.CollectionName).UpdateOne(ctx, bson.M{"_id": objID}, bson.M{
+		"$inc": bson.M{"usage_count": count},
+		"$max": bson.M{"last_used_at": lastUsed},
+	})
+	return err
+}
+
+func (r *MongoAPIKeyRepo) Usage(id, since string) ([]domain.DailyUsage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := []domain.DailyUsage{}
+	collection := config.GetDB().Collection(r.UsageCollectionName)
+	cursor, err := collection.Find(ctx,
+		bson.M{"key_id": id, "day": bson.M{"$gte": since}},
+		options.Find().SetSort(bson.M{"day": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
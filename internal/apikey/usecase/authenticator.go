@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mlsport/internal/apikey/domain"
+	"mlsport/internal/auth"
+)
+
+// Authenticator acepta API keys en la cabecera X-API-Key y registra cada uso.
+type Authenticator struct {
+	Repo  domain.APIKeyRepository
+	Usage *UsageRecorder
+
+	now func() time.Time
+}
+
+func NewAuthenticator(repo domain.APIKeyRepository, usage *UsageRecorder) *Authenticator {
+	return &Authenticator{Repo: repo, Usage: usage, now: func() time.Time { return time.Now().UTC() }}
+}
+
+func (a *Authenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	key := strings.TrimSpace(r.Header.Get(domain.HeaderAPIKey))
+	if key == "" {
+		return nil, auth.ErrNoCredentials
+	}
+	k, err := a.Repo.FindByHash(domain.HashKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+	}
+	now := a.now()
+	if err := k.Check(now); err != nil {
+		return nil, fmt.Errorf("%w: %s %v", auth.ErrInvalidToken, k.Prefix, err)
+	}
+	if a.Usage != nil {
+		a.Usage.Record(k.ID, now)
+	}
+	return &auth.Principal{Subject: "apikey:" + k.ID, Scopes: k.Scopes, Tenant: k.Tenant, Method: auth.MethodAPIKey}, nil
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"mlsport/internal/apikey/domain"
+	"mlsport/internal/auth"
+	"mlsport/internal/tenant"
+)
+
+const (
+	DefaultUsageDays = 30
+	MaxUsageDays     = 366
+)
+
+// APIKeyService administra las claves de la tienda del contexto; las de otras tiendas se tratan
+// como inexistentes.
+type APIKeyService struct {
+	Repo domain.APIKeyRepository
+
+	now func() time.Time
+}
+
+func NewAPIKeyService(repo domain.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{Repo: repo, now: func() time.Time { return time.Now().UTC() }}
+}
+
+// Create genera la clave y guarda solo su hash; k.Key queda con la clave en claro, que no se puede
+// volver a consultar. Si hay un usuario en ctx, no puede dar permisos que él mismo no tiene.
+func (s *APIKeyService) Create(ctx context.Context, k *domain.APIKey) error {
+	k.Name = strings.TrimSpace(k.Name)
+	if k.Name == "" || len(k.Scopes) == 0 {
+		return domain.ErrInvalidAPIKey
+	}
+	seen := map[auth.Permission]bool{}
+	scopes := k.Scopes[:0]
+	for _, scope := range k.Scopes {
+		scope = auth.Permission(strings.TrimSpace(string(scope)))
+		if !auth.KnownPermission(scope) {
+			return fmt.Errorf("%w: %s", domain.ErrUnknownScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		for _, scope := range scopes {
+			if !p.Can(scope) {
+				return fmt.Errorf("%w: %s", auth.ErrForbidden, scope)
+			}
+		}
+	}
+	now := s.now()
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(now) {
+		return domain.ErrInvalidExpiry
+	}
+
+	key, err := newKey()
+	if err != nil {
+		return err
+	}
+	k.Tenant = tenant.ID(ctx)
+	k.Scopes = scopes
+	k.Key = key
+	k.Prefix = key[:len(domain.KeyPrefix)+8]
+	k.Hash = domain.HashKey(key)
+	k.CreatedAt = now
+	k.CreatedBy = auth.Subject(ctx)
+	k.RevokedAt, k.LastUsedAt, k.UsageCount = nil, nil, 0
+	return s.Repo.Create(k)
+}
+
+func (s *APIKeyService) GetAll(ctx context.Context) ([]domain.APIKey, error) {
+	return s.Repo.FindAll(tenant.ID(ctx))
+}
+
+func (s *APIKeyService) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	k, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if k.Tenant != tenant.ID(ctx) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	return k, nil
+}
+
+// Revoke inhabilita la clave; se conserva para la auditoría y sus contadores.
+func (s *APIKeyService) Revoke(ctx context.Context, id string) (*domain.APIKey, error) {
+	k, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if k.RevokedAt == nil {
+		now := s.now()
+		if err := s.Repo.Revoke(id, now); err != nil {
+			return nil, err
+		}
+		k.RevokedAt = &now
+	}
+	return k, nil
+}
+
+// Usage devuelve el uso diario de la clave en los últimos days días (hoy incluido).
+func (s *APIKeyService) Usage(ctx context.Context, id string, days int) ([]domain.DailyUsage, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if days <= 0 {
+		days = DefaultUsageDays
+	}
+	if days > MaxUsageDays {
+		days = MaxUsageDays
+	}
+	since := domain.Day(s.now().AddDate(0, 0, -(days - 1)))
+	return s.Repo.Usage(id, since)
+}
+
+func newKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return domain.KeyPrefix + hex.EncodeToString(b), nil
+}
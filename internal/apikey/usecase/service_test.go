@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mlsport/internal/apikey/domain"
+	"mlsport/internal/auth"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRepo struct {
+	mu      sync.Mutex
+	keys    map[string]*domain.APIKey
+	daily   map[string]int64
+	failing bool
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{keys: map[string]*domain.APIKey{}, daily: map[string]int64{}}
+}
+
+func (m *memoryRepo) Create(k *domain.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k.ObjectID = primitive.NewObjectID()
+	k.ID = k.ObjectID.Hex()
+	stored := *k
+	stored.Key = ""
+	m.keys[k.ID] = &stored
+	return nil
+}
+
+func (m *memoryRepo) FindAll(tenantID string) ([]domain.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []domain.APIKey
+	for _, k := range m.keys {
+		if k.Tenant == tenantID {
+			list = append(list, *k)
+		}
+	}
+	return list, nil
+}
+
+func (m *memoryRepo) FindByID(id string) (*domain.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if k, ok := m.keys[id]; ok {
+		copied := *k
+		return &copied, nil
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (m *memoryRepo) FindByHash(hash string) (*domain.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range m.keys {
+		if k.Hash == hash {
+			copied := *k
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (m *memoryRepo) Revoke(id string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[id].RevokedAt = &at
+	return nil
+}
+
+func (m *memoryRepo) RecordUsage(id, day string, count int64, lastUsed time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failing {
+		return errors.New("mongo caído")
+	}
+	m.daily[id+"/"+day] += count
+	k := m.keys[id]
+	k.UsageCount += count
+	if k.LastUsedAt == nil || lastUsed.After(*k.LastUsedAt) {
+		k.LastUsedAt = &lastUsed
+	}
+	return nil
+}
+
+func (m *memoryRepo) Usage(id, since string) ([]domain.DailyUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []domain.DailyUsage
+	for k, count := range m.daily {
+		if strings.HasPrefix(k, id+"/") && k[len(id)+1:] >= since {
+			list = append(list, domain.DailyUsage{Day: k[len(id)+1:], Count: count})
+		}
+	}
+	return list, nil
+}
+
+func TestAPIKeyService_CreateStoresOnlyHash(t *testing.T) {
+	repo := newMemoryRepo()
+	service := NewAPIKeyService(repo)
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ana", Roles: []string{auth.RoleAdmin}})
+
+	k := &domain.APIKey{Name: " escaner-1 ", Scopes: []auth.Permission{auth.PermProductRead, auth.PermProductStock, auth.PermProductRead}}
+	require.NoError(t, service.Create(admin, k))
+
+	assert.True(t, strings.HasPrefix(k.Key, domain.KeyPrefix))
+	assert.Equal(t, k.Key[:len(domain.KeyPrefix)+8], k.Prefix)
+	assert.Equal(t, "escaner-1", k.Name)
+	assert.Equal(t, "ana", k.CreatedBy)
+	assert.Equal(t, []auth.Permission{auth.PermProductRead, auth.PermProductStock}, k.Scopes)
+
+	stored, err := repo.FindByID(k.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Key)
+	assert.Equal(t, domain.HashKey(k.Key), stored.Hash)
+	assert.NotContains(t, stored.Hash, k.Key[len(domain.KeyPrefix):])
+}
+
+func TestAPIKeyService_CreateValidates(t *testing.T) {
+	service := NewAPIKeyService(newMemoryRepo())
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+
+	assert.ErrorIs(t, service.Create(ctx, &domain.APIKey{Name: "x"}), domain.ErrInvalidAPIKey)
+	assert.ErrorIs(t, service.Create(ctx, &domain.APIKey{Name: "x", Scopes: []auth.Permission{"product:fly"}}), domain.ErrUnknownScope)
+	assert.ErrorIs(t, service.Create(ctx, &domain.APIKey{Name: "x", Scopes: []auth.Permission{auth.PermProductRead}, ExpiresAt: &past}), domain.ErrInvalidExpiry)
+
+	// Nadie puede dar a una clave permisos que no tiene.
+	merch := auth.WithPrincipal(ctx, &auth.Principal{Subject: "m", Roles: []string{auth.RoleMerchandiser}})
+	assert.ErrorIs(t, service.Create(merch, &domain.APIKey{Name: "x", Scopes: []auth.Permission{auth.PermProductDelete}}), auth.ErrForbidden)
+}
+
+func TestAPIKeyService_ScopedToTenant(t *testing.T) {
+	service := NewAPIKeyService(newMemoryRepo())
+	a := tenant.WithID(context.Background(), "tienda-a")
+	b := tenant.WithID(context.Background(), "tienda-b")
+
+	k := &domain.APIKey{Name: "pos", Scopes: []auth.Permission{auth.PermProductRead}}
+	require.NoError(t, service.Create(a, k))
+	assert.Equal(t, "tienda-a", k.Tenant)
+
+	_, err := service.GetByID(b, k.ID)
+	assert.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+	_, err = service.Revoke(b, k.ID)
+	assert.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+	list, _ := service.GetAll(b)
+	assert.Empty(t, list)
+	list, _ = service.GetAll(a)
+	assert.Len(t, list, 1)
+}
+
+func TestAuthenticator(t *testing.T) {
+	repo := newMemoryRepo()
+	service := NewAPIKeyService(repo)
+	usage := NewUsageRecorder(repo)
+	authn := NewAuthenticator(repo, usage)
+
+	soon := time.Now().UTC().Add(time.Hour)
+	k := &domain.APIKey{Name: "escaner", Scopes: []auth.Permission{auth.PermProductRead, auth.PermProductStock}, ExpiresAt: &soon}
+	require.NoError(t, service.Create(context.Background(), k))
+
+	authenticate := func(key string) (*auth.Principal, error) {
+		r := httptest.NewRequest("GET", "/api/products", nil)
+		if key != "" {
+			r.Header.Set(domain.HeaderAPIKey, key)
+		}
+		return authn.Authenticate(r)
+	}
+
+	_, err := authenticate("")
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+	_, err = authenticate(domain.KeyPrefix + "otra")
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	p, err := authenticate(k.Key)
+	require.NoError(t, err)
+	assert.Equal(t, auth.MethodAPIKey, p.Method)
+	assert.Equal(t, "apikey:"+k.ID, p.Subject)
+	assert.Equal(t, tenant.Default, p.Tenant)
+	assert.True(t, p.Can(auth.PermProductStock))
+	assert.False(t, p.Can(auth.PermProductPrice))
+
+	// Vencida.
+	authn.now = func() time.Time { return soon }
+	_, err = authenticate(k.Key)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	// Revocada.
+	authn.now = func() time.Time { return time.Now().UTC() }
+	_, err = service.Revoke(context.Background(), k.ID)
+	require.NoError(t, err)
+	_, err = authenticate(k.Key)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestUsageRecorder_FlushAggregatesPerDay(t *testing.T) {
+	repo := newMemoryRepo()
+	service := NewAPIKeyService(repo)
+	k := &domain.APIKey{Name: "integracion", Scopes: []auth.Permission{auth.PermProductRead}}
+	require.NoError(t, service.Create(context.Background(), k))
+
+	usage := NewUsageRecorder(repo)
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+	today := time.Now().UTC()
+	usage.Record(k.ID, yesterday)
+	usage.Record(k.ID, today)
+	usage.Record(k.ID, today)
+
+	// Si falla el guardado, los usos se conservan para el próximo intento.
+	repo.failing = true
+	assert.Error(t, usage.Flush())
+	usage.Record(k.ID, today)
+	repo.failing = false
+	require.NoError(t, usage.Flush())
+
+	stored, _ := repo.FindByID(k.ID)
+	assert.Equal(t, int64(4), stored.UsageCount)
+	assert.Equal(t, today, *stored.LastUsedAt)
+
+	daily, err := service.Usage(context.Background(), k.ID, 2)
+	require.NoError(t, err)
+	got := map[string]int64{}
+	for _, d := range daily {
+		got[d.Day] = d.Count
+	}
+	assert.Equal(t, map[string]int64{domain.Day(yesterday): 1, domain.Day(today): 3}, got)
+
+	// Sin usos nuevos no se escribe nada.
+	require.NoError(t, usage.Flush())
+	stored, _ = repo.FindByID(k.ID)
+	assert.Equal(t, int64(4), stored.UsageCount)
+}
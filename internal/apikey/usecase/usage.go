@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"mlsport/internal/apikey/domain"
+	"mlsport/internal/logging"
+)
+
+const DefaultUsageFlushInterval = 10 * time.Second
+
+// UsageRecorder junta los usos de cada clave en memoria y los guarda cada Interval, para no escribir
+// en Mongo en cada petición. Si el proceso se cae entre dos guardados se pierden esos usos.
+type UsageRecorder struct {
+	Repo     domain.APIKeyRepository
+	Interval time.Duration
+
+	mu      sync.Mutex
+	pending map[usageKey]*pendingUsage
+}
+
+type usageKey struct {
+	id, day string
+}
+
+type pendingUsage struct {
+	count    int64
+	lastUsed time.Time
+}
+
+func NewUsageRecorder(repo domain.APIKeyRepository) *UsageRecorder {
+	return &UsageRecorder{Repo: repo, Interval: DefaultUsageFlushInterval, pending: map[usageKey]*pendingUsage{}}
+}
+
+// Record anota un uso de la clave id en at.
+func (u *UsageRecorder) Record(id string, at time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	k := usageKey{id: id, day: domain.Day(at)}
+	p := u.pending[k]
+	if p == nil {
+		p = &pendingUsage{}
+		u.pending[k] = p
+	}
+	p.count++
+	if at.After(p.lastUsed) {
+		p.lastUsed = at
+	}
+}
+
+// Flush guarda los usos acumulados; los que fallan se vuelven a sumar para el próximo intento.
+func (u *UsageRecorder) Flush() error {
+	u.mu.Lock()
+	batch := u.pending
+	u.pending = map[usageKey]*pendingUsage{}
+	u.mu.Unlock()
+
+	var firstErr error
+	for k, p := range batch {
+		if err := u.Repo.RecordUsage(k.id, k.day, p.count, p.lastUsed); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			u.mu.Lock()
+			if cur := u.pending[k]; cur != nil {
+				cur.count += p.count
+				if p.lastUsed.After(cur.lastUsed) {
+					cur.lastUsed = p.lastUsed
+				}
+			} else {
+				u.pending[k] = p
+			}
+			u.mu.Unlock()
+		}
+	}
+	return firstErr
+}
+
+// Run guarda los usos cada Interval y una última vez al cancelarse ctx.
+func (u *UsageRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := u.Flush(); err != nil {
+				slog.Error("Error guardando uso de API keys", logging.Err(err))
+			}
+			return
+		case <-ticker.C:
+			if err := u.Flush(); err != nil {
+				slog.Error("Error guardando uso de API keys", logging.Err(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultLeeway tolera diferencias de reloj al validar exp y nbf.
+const DefaultLeeway = 30 * time.Second
+
+// JWTVerifier valida tokens Bearer firmados con HS256 (Secret) o RS256 (claves públicas por kid,
+// normalmente cargadas de un JWKS local). Los tokens deben tener sub y exp.
+type JWTVerifier struct {
+	Secret   []byte
+	Keys     map[string]*rsa.PublicKey
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// Claims son los claims que entiende la API además de los registrados.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+	Role  string   `json:"role,omitempty"`
+	// Tenant ata el token a una tienda; AllTenants es un usuario de la plataforma y sin él, la
+	// tienda por defecto.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+func NewJWTVerifier(secret []byte, keys map[string]*rsa.PublicKey) *JWTVerifier {
+	return &JWTVerifier{Secret: secret, Keys: keys, Leeway: DefaultLeeway}
+}
+
+// Authenticate lee el token de Authorization: Bearer. WebSocket y EventSource no pueden enviar
+// cabeceras desde el navegador, así que en esas conexiones también se acepta ?access_token=.
+func (v *JWTVerifier) Authenticate(r *http.Request) (*Principal, error) {
+	token := BearerToken(r)
+	if token == "" && isStreaming(r) {
+		token = r.URL.Query().Get("access_token")
+	}
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+	return v.Verify(token)
+}
+
+// Verify valida firma, algoritmo, vencimiento, emisor y audiencia, y devuelve el Principal del token.
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(v.methods()),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.Leeway),
+	}
+	if v.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.Issuer))
+	}
+	if v.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.Audience))
+	}
+
+	var claims Claims
+	if _, err := jwt.ParseWithClaims(token, &claims, v.key, opts...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: falta sub", ErrInvalidToken)
+	}
+
+	roles := claims.Roles
+	if len(roles) == 0 && claims.Role != "" {
+		roles = []string{claims.Role}
+	}
+	return &Principal{Subject: claims.Subject, Roles: roles, Tenant: claims.Tenant, Method: MethodJWT}, nil
+}
+
+func (v *JWTVerifier) methods() []string {
+	var methods []string
+	if len(v.Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(v.Keys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	return methods
+}
+
+// key elige la clave según el algoritmo del token; WithValidMethods ya descartó los no configurados.
+func (v *JWTVerifier) key(t *jwt.Token) (interface{}, error) {
+	if t.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		return v.Secret, nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	if key, ok := v.Keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(v.Keys) == 1 {
+		for _, key := range v.Keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("clave desconocida %q", kid)
+}
+
+// BearerToken devuelve el token de Authorization: Bearer, o "".
+func BearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
+
+func isStreaming(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// LoadJWKS lee las claves RSA de firma de un archivo JWKS, indexadas por kid.
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("JWKS inválido: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if err := errors.Join(errN, errE); err != nil {
+			return nil, fmt.Errorf("clave %q del JWKS inválida: %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("el JWKS no tiene claves RSA de firma")
+	}
+	return keys, nil
+}
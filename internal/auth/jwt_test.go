@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{"sub": "ana", "exp": time.Now().Add(time.Hour).Unix(), "roles": []string{"admin"}}
+}
+
+func writeJWKS(t *testing.T, kid string, pub *rsa.PublicKey) string {
+	t.Helper()
+	set := map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+		"n": base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}}
+	data, _ := json.Marshal(set)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestJWTVerifier_HS256(t *testing.T) {
+	secret := []byte("super-secreto")
+	v := NewJWTVerifier(secret, nil)
+
+	p, err := v.Verify(sign(t, jwt.SigningMethodHS256, secret, "", validClaims()))
+	assert.NoError(t, err)
+	assert.Equal(t, "ana", p.Subject)
+	assert.Equal(t, []string{"admin"}, p.Roles)
+	assert.Equal(t, MethodJWT, p.Method)
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err = v.Verify(sign(t, jwt.SigningMethodHS256, secret, "", expired))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	noExp := jwt.MapClaims{"sub": "ana"}
+	_, err = v.Verify(sign(t, jwt.SigningMethodHS256, secret, "", noExp))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = v.Verify(sign(t, jwt.SigningMethodHS256, []byte("otro"), "", validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// alg "none" nunca se acepta.
+	_, err = v.Verify(sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWTVerifier_RS256FromJWKS(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keys, err := LoadJWKS(writeJWKS(t, "k1", &priv.PublicKey))
+	assert.NoError(t, err)
+
+	v := NewJWTVerifier(nil, keys)
+	v.Issuer = "https://idp.mlsport.test"
+	v.Audience = "mlsport-api"
+
+	claims := validClaims()
+	claims["iss"] = "https://idp.mlsport.test"
+	claims["aud"] = "mlsport-api"
+	p, err := v.Verify(sign(t, jwt.SigningMethodRS256, priv, "k1", claims))
+	assert.NoError(t, err)
+	assert.Equal(t, "ana", p.Subject)
+
+	_, err = v.Verify(sign(t, jwt.SigningMethodRS256, priv, "k2", claims))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	claims["aud"] = "otra-api"
+	_, err = v.Verify(sign(t, jwt.SigningMethodRS256, priv, "k1", claims))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Sin secreto configurado, un HS256 se rechaza aunque use la clave pública como secreto.
+	_, err = v.Verify(sign(t, jwt.SigningMethodHS256, []byte("x"), "k1", validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrincipalKey es la clave del Principal en el gin.Context.
+const PrincipalKey = "auth.principal"
+
+// Required exige credenciales válidas de alguno de los autenticadores. Con publicReads, las
+// peticiones GET y HEAD sin credenciales pasan como anónimas; si traen credenciales se validan igual.
+func Required(publicReads bool, authenticators ...Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, a := range authenticators {
+			p, err := a.Authenticate(c.Request)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				slog.WarnContext(c.Request.Context(), "Autenticación rechazada", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidToken.Error()})
+				return
+			}
+			c.Set(PrincipalKey, p)
+			c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), p))
+			c.Next()
+			return
+		}
+
+		if publicReads && (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) {
+			c.Next()
+			return
+		}
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrUnauthenticated.Error()})
+	}
+}
+
+// Audit registra quién hizo cada escritura y con qué resultado.
+func Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+		slog.InfoContext(c.Request.Context(), "Auditoría", "subject", Subject(c.Request.Context()), "method", c.Request.Method, "path", c.Request.URL.Path, "status", c.Writer.Status())
+	}
+}
+
+// CurrentPrincipal devuelve el Principal de la petición; false si es anónima.
+func CurrentPrincipal(c *gin.Context) (*Principal, bool) {
+	return FromContext(c.Request.Context())
+}
+
+// ClientID identifica a quien llama para límites y claves de idempotencia: la API key, el usuario
+// o, en peticiones anónimas, la IP.
+func ClientID(c *gin.Context) string {
+	if p, ok := CurrentPrincipal(c); ok {
+		if p.Method == MethodAPIKey {
+			return p.Subject
+		}
+		return "user:" + p.Subject
+	}
+	return "ip:" + c.ClientIP()
+}
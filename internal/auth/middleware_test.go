@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthRouter(publicReads bool) (*gin.Engine, []byte) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("super-secreto")
+	r := gin.New()
+	r.Use(Audit(), Required(publicReads, NewJWTVerifier(secret, nil)))
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"subject": Subject(c.Request.Context())})
+	}
+	r.GET("/products", handler)
+	r.DELETE("/products/1", handler)
+	return r, secret
+}
+
+func do(r http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequired(t *testing.T) {
+	r, secret := newAuthRouter(true)
+	token := sign(t, jwt.SigningMethodHS256, secret, "", validClaims())
+
+	w := do(r, http.MethodGet, "/products", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "anonymous")
+
+	w = do(r, http.MethodDelete, "/products/1", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+
+	w = do(r, http.MethodDelete, "/products/1", token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"subject":"ana"`)
+
+	// Un token inválido se rechaza también en lecturas públicas.
+	w = do(r, http.MethodGet, "/products", "basura")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRequired_PrivateReads(t *testing.T) {
+	r, secret := newAuthRouter(false)
+	assert.Equal(t, http.StatusUnauthorized, do(r, http.MethodGet, "/products", "").Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/products?access_token="+sign(t, jwt.SigningMethodHS256, secret, "", validClaims()), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "access_token solo vale para WebSocket y SSE")
+
+	req.Header.Set("Accept", "text/event-stream")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
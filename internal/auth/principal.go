@@ -0,0 +1,68 @@
+// Package auth identifica a quien llama a la API: valida credenciales (JWT, y los autenticadores que
+// se agreguen) y deja el Principal en el contexto de la petición para handlers, servicios y auditoría.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	// ErrNoCredentials indica que la petición no trae credenciales para ese autenticador.
+	ErrNoCredentials = errors.New("sin credenciales")
+	ErrInvalidToken  = errors.New("token inválido")
+)
+
+// Métodos de autenticación.
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+)
+
+// Principal es la identidad autenticada de una petición. Las API keys no tienen roles: sus permisos
+// son los Scopes con que se crearon. Tenant es la tienda a la que está atado: AllTenants para los
+// usuarios de la plataforma, que eligen la tienda por cabecera, y vacío si la credencial no indica
+// ninguna (vale para la tienda por defecto).
+type Principal struct {
+	Subject string       `json:"subject"`
+	Roles   []string     `json:"roles,omitempty"`
+	Scopes  []Permission `json:"scopes,omitempty"`
+	Tenant  string       `json:"tenant,omitempty"`
+	Method  string       `json:"method"`
+}
+
+// AllTenants en el claim tenant marca a un usuario de la plataforma. Tiene que ser explícito: un
+// token sin el claim no da acceso a otras tiendas.
+const AllTenants = "*"
+
+// Platform indica si el Principal puede elegir cualquier tienda.
+func (p *Principal) Platform() bool {
+	return p.Tenant == AllTenants
+}
+
+// Authenticator valida las credenciales de la petición. Devuelve ErrNoCredentials si no le
+// corresponden, para que se pruebe el siguiente.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext devuelve el Principal de la petición; false si es anónima.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// Subject devuelve el sujeto autenticado, o "anonymous".
+func Subject(ctx context.Context) string {
+	if p, ok := FromContext(ctx); ok {
+		return p.Subject
+	}
+	return "anonymous"
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrUnauthenticated = errors.New("se requiere autenticación")
+	ErrForbidden       = errors.New("permiso denegado")
+)
+
+type Permission string
+
+const (
+	PermProductRead   Permission = "product:read"
+	PermProductCreate Permission = "product:create"
+	// PermProductEdit cubre los datos descriptivos (nombre, descripción, categoría, marca...).
+	PermProductEdit   Permission = "product:edit"
+	PermProductPrice  Permission = "product:price"
+	PermProductStock  Permission = "product:stock"
+	PermProductDelete Permission = "product:delete"
+	// PermCatalogManage cubre categorías y marcas.
+	PermCatalogManage  Permission = "catalog:manage"
+	PermWebhooksManage Permission = "webhooks:manage"
+	PermAPIKeysManage  Permission = "apikeys:manage"
+	// PermTenantsManage da de alta y administra tiendas; además exige un usuario de la plataforma.
+	PermTenantsManage Permission = "tenants:manage"
+)
+
+const (
+	RoleViewer         = "viewer"
+	RoleInventoryClerk = "inventory_clerk"
+	RoleMerchandiser   = "merchandiser"
+	RoleAdmin          = "admin"
+)
+
+// DefaultRole aplica a los tokens sin roles.
+const DefaultRole = RoleViewer
+
+// RolePermissions es la matriz de permisos. admin tiene todos.
+var RolePermissions = map[string][]Permission{
+	RoleViewer:         {PermProductRead},
+	RoleInventoryClerk: {PermProductRead, PermProductStock},
+	RoleMerchandiser:   {PermProductRead, PermProductCreate, PermProductEdit, PermProductPrice, PermCatalogManage},
+	RoleAdmin: {
+		PermProductRead, PermProductCreate, PermProductEdit, PermProductPrice, PermProductStock,
+		PermProductDelete, PermCatalogManage, PermWebhooksManage, PermAPIKeysManage, PermTenantsManage,
+	},
+}
+
+// KnownPermission indica si perm existe, por ejemplo al validar los permisos de una API key.
+func KnownPermission(perm Permission) bool {
+	for _, known := range RolePermissions[RoleAdmin] {
+		if known == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Can indica si alguno de los roles del Principal concede perm; para una API key, si está entre
+// sus Scopes.
+func (p *Principal) Can(perm Permission) bool {
+	if p.Method == MethodAPIKey {
+		for _, scope := range p.Scopes {
+			if scope == perm {
+				return true
+			}
+		}
+		return false
+	}
+	roles := p.Roles
+	if len(roles) == 0 {
+		roles = []string{DefaultRole}
+	}
+	for _, role := range roles {
+		for _, granted := range RolePermissions[strings.ToLower(role)] {
+			if granted == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authorize exige que quien llama (el Principal de ctx) tenga todos los permisos. Lo usan tanto el
+// middleware como los servicios, para que quien no pasa por HTTP cumpla las mismas reglas.
+func Authorize(ctx context.Context, perms ...Permission) error {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	for _, perm := range perms {
+		if !p.Can(perm) {
+			return fmt.Errorf("%w: %s", ErrForbidden, perm)
+		}
+	}
+	return nil
+}
+
+// Require corta la petición si el usuario no tiene todos los permisos.
+func Require(perms ...Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := Authorize(c.Request.Context(), perms...); err != nil {
+			AbortWithError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAny deja pasar con cualquiera de los permisos; sirve cuando el servicio decide el detalle,
+// como en un PATCH que según los campos requiere stock, precio o edición.
+func RequireAny(perms ...Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var err error
+		for _, perm := range perms {
+			if err = Authorize(c.Request.Context(), perm); err == nil {
+				c.Next()
+				return
+			}
+		}
+		AbortWithError(c, err)
+	}
+}
+
+// AbortWithError responde 401 o 403 según el error de autorización.
+func AbortWithError(c *gin.Context, err error) {
+	status := http.StatusForbidden
+	if errors.Is(err, ErrUnauthenticated) {
+		status = http.StatusUnauthorized
+		c.Header("WWW-Authenticate", "Bearer")
+	}
+	c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+}
+
+// IsAuthError indica si err es de autenticación o autorización.
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrUnauthenticated) || errors.Is(err, ErrForbidden)
+}
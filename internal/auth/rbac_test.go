@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalCan(t *testing.T) {
+	clerk := &Principal{Roles: []string{RoleInventoryClerk}}
+	assert.True(t, clerk.Can(PermProductStock))
+	assert.False(t, clerk.Can(PermProductPrice))
+
+	merch := &Principal{Roles: []string{"Merchandiser"}}
+	assert.True(t, merch.Can(PermProductPrice))
+	assert.False(t, merch.Can(PermProductDelete))
+
+	// Sin roles se es viewer; varios roles suman permisos.
+	assert.True(t, (&Principal{}).Can(PermProductRead))
+	assert.False(t, (&Principal{}).Can(PermProductCreate))
+	both := &Principal{Roles: []string{RoleInventoryClerk, RoleMerchandiser}}
+	assert.True(t, both.Can(PermProductStock) && both.Can(PermProductPrice))
+	assert.False(t, (&Principal{Roles: []string{"desconocido"}}).Can(PermProductRead))
+
+	// Las API keys se rigen solo por sus scopes, aunque traigan roles.
+	key := &Principal{Method: MethodAPIKey, Roles: []string{RoleAdmin}, Scopes: []Permission{PermProductStock}}
+	assert.True(t, key.Can(PermProductStock))
+	assert.False(t, key.Can(PermProductRead))
+}
+
+func TestRequirePermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if role := c.GetHeader("X-Role"); role != "" {
+			c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), &Principal{Subject: "u", Roles: []string{role}}))
+		}
+	})
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	r.DELETE("/p", Require(PermProductDelete), ok)
+	r.PATCH("/p", RequireAny(PermProductEdit, PermProductStock), ok)
+
+	cases := []struct {
+		method, role string
+		want         int
+	}{
+		{http.MethodDelete, "", http.StatusUnauthorized},
+		{http.MethodDelete, RoleMerchandiser, http.StatusForbidden},
+		{http.MethodDelete, RoleAdmin, http.StatusNoContent},
+		{http.MethodPatch, RoleInventoryClerk, http.StatusNoContent},
+		{http.MethodPatch, RoleViewer, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, "/p", nil)
+		req.Header.Set("X-Role", tc.role)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.want, w.Code, "%s como %q", tc.method, tc.role)
+	}
+}
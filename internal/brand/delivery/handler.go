@@ -0,0 +1,141 @@
+package delivery
+
+import (
+	"errors"
+	"mlsport/internal/brand/domain"
+	"mlsport/internal/brand/usecase"
+	"mlsport/internal/logging"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BrandHandler struct {
+	Service *usecase.BrandService
+}
+
+func NewBrandHandler(s *usecase.BrandService) *BrandHandler {
+	return &BrandHandler{Service: s}
+}
+
+// GetAll godoc
+// @Summary Listar marcas
+// @Description Devuelve las marcas registradas con el número de productos de cada una.
+// @Tags Marcas
+// @Produce json
+// @Success 200 {array} domain.Brand
+// @Router /brands [get]
+func (h *BrandHandler) GetAll(c *gin.Context) {
+	list, err := h.Service.GetAll(c.Request.Context())
+	if err != nil {
+		logging.InternalError(c, "no se pudieron obtener las marcas", err)
+		return
+	}
+
+	if len(list) == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "no hay marcas", "data": []domain.Brand{}})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// GetBySlug godoc
+// @Summary Consultar una marca
+// @Description Retorna una marca a partir de su slug.
+// @Tags Marcas
+// @Produce json
+// @Param slug path string true "Slug de la marca"
+// @Success 200 {object} domain.Brand
+// @Failure 404 {object} map[string]string
+// @Router /brands/{slug} [get]
+func (h *BrandHandler) GetBySlug(c *gin.Context) {
+	brand, err := h.Service.GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		respondError(c, err, "no se pudo obtener la marca")
+		return
+	}
+	c.JSON(http.StatusOK, brand)
+}
+
+// Create godoc
+// @Summary Crear marca
+// @Description Registra una marca canónica; los productos con variantes del nombre se normalizan a ella.
+// @Tags Marcas
+// @Accept json
+// @Produce json
+// @Param marca body domain.Brand true "Marca a registrar"
+// @Success 201 {object} domain.Brand
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /brands [post]
+func (h *BrandHandler) Create(c *gin.Context) {
+	var input domain.Brand
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+		return
+	}
+	if err := h.Service.Create(c.Request.Context(), &input); err != nil {
+		respondError(c, err, "no se pudo crear la marca")
+		return
+	}
+	c.JSON(http.StatusCreated, input)
+}
+
+// Update godoc
+// @Summary Actualizar marca
+// @Description Reemplaza nombre, slug, logo y país de una marca; si cambia el nombre, también actualiza los productos de la tienda.
+// @Tags Marcas
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug de la marca"
+// @Param marca body domain.Brand true "Datos actualizados"
+// @Success 200 {object} domain.Brand
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /brands/{slug} [put]
+func (h *BrandHandler) Update(c *gin.Context) {
+	var input domain.Brand
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+		return
+	}
+	if err := h.Service.Update(c.Request.Context(), c.Param("slug"), &input); err != nil {
+		respondError(c, err, "no se pudo actualizar la marca")
+		return
+	}
+	c.JSON(http.StatusOK, input)
+}
+
+// Delete godoc
+// @Summary Eliminar marca
+// @Description Borra una marca del catálogo; los productos conservan el nombre guardado.
+// @Tags Marcas
+// @Param slug path string true "Slug de la marca"
+// @Success 200 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /brands/{slug} [delete]
+func (h *BrandHandler) Delete(c *gin.Context) {
+	if err := h.Service.Delete(c.Request.Context(), c.Param("slug")); err != nil {
+		respondError(c, err, "no se pudo eliminar la marca")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "eliminado"})
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrBrandNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidBrand):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrBrandExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logging.InternalError(c, fallback, err)
+	}
+}
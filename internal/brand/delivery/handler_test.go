@@ -0,0 +1,129 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mlsport/internal/brand/domain"
+	"mlsport/internal/brand/usecase"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockRepo simula el repo de Mongo: createErr es lo que devuelve el alta (p. ej. la carrera con el
+// índice único) y relabeled, cuántos productos cambia un renombre.
+type mockRepo struct {
+	brands    map[string]domain.Brand
+	createErr error
+	relabeled int64
+}
+
+func (m *mockRepo) Create(ctx context.Context, b *domain.Brand) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	b.ID = b.Slug
+	m.brands[b.Slug] = *b
+	return nil
+}
+func (m *mockRepo) FindAll(ctx context.Context) ([]domain.Brand, error) {
+	var out []domain.Brand
+	for _, b := range m.brands {
+		out = append(out, b)
+	}
+	return out, nil
+}
+func (m *mockRepo) FindBySlug(ctx context.Context, slug string) (*domain.Brand, error) {
+	if b, ok := m.brands[slug]; ok {
+		return &b, nil
+	}
+	return nil, domain.ErrBrandNotFound
+}
+func (m *mockRepo) Update(ctx context.Context, b *domain.Brand, previousName string) (int64, error) {
+	delete(m.brands, b.ID)
+	m.brands[b.Slug] = *b
+	return m.relabeled, nil
+}
+func (m *mockRepo) Delete(ctx context.Context, id string) error { return nil }
+func (m *mockRepo) CountProducts(ctx context.Context) (map[string]int, error) {
+	return map[string]int{"Nike": 2, "NIKE": 1}, nil
+}
+
+func newRouter(repo *mockRepo) (*gin.Engine, *usecase.BrandService) {
+	gin.SetMode(gin.TestMode)
+	service := usecase.NewBrandService(repo)
+	h := NewBrandHandler(service)
+	r := gin.New()
+	r.GET("/api/brands", h.GetAll)
+	r.POST("/api/brands", h.Create)
+	r.PUT("/api/brands/:slug", h.Update)
+	return r, service
+}
+
+func send(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateBrandHandler(t *testing.T) {
+	r, _ := newRouter(&mockRepo{brands: map[string]domain.Brand{}})
+
+	w := send(r, http.MethodPost, "/api/brands", `{"name":" Nike "}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var b domain.Brand
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &b))
+	assert.Equal(t, "Nike", b.Name)
+	assert.Equal(t, "nike", b.Slug)
+
+	assert.Equal(t, http.StatusConflict, send(r, http.MethodPost, "/api/brands", `{"name":"NIKE"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(r, http.MethodPost, "/api/brands", `{"name":""}`).Code)
+}
+
+func TestCreateBrandHandler_ConcurrentDuplicateIsConflict(t *testing.T) {
+	// El servicio no la encontró, pero el índice único rechazó el alta.
+	r, _ := newRouter(&mockRepo{brands: map[string]domain.Brand{}, createErr: domain.ErrBrandExists})
+
+	w := send(r, http.MethodPost, "/api/brands", `{"name":"Nike"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), domain.ErrBrandExists.Error())
+}
+
+func TestCreateBrandHandler_RepoError(t *testing.T) {
+	r, _ := newRouter(&mockRepo{brands: map[string]domain.Brand{}, createErr: errors.New("mongo caído")})
+
+	assert.Equal(t, http.StatusInternalServerError, send(r, http.MethodPost, "/api/brands", `{"name":"Nike"}`).Code)
+}
+
+func TestGetAllBrandsHandler_MergesVariants(t *testing.T) {
+	r, _ := newRouter(&mockRepo{brands: map[string]domain.Brand{"nike": {ID: "nike", Name: "Nike", Slug: "nike"}}})
+
+	w := send(r, http.MethodGet, "/api/brands", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list []domain.Brand
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 3, list[0].ProductCount)
+}
+
+func TestUpdateBrandHandler_RenameNotifiesRelabel(t *testing.T) {
+	repo := &mockRepo{brands: map[string]domain.Brand{"nike": {ID: "nike", Name: "Nike", Slug: "nike"}}, relabeled: 3}
+	r, service := newRouter(repo)
+	relabeled := false
+	service.OnProductsRelabeled = func(context.Context) { relabeled = true }
+
+	w := send(r, http.MethodPut, "/api/brands/nike", `{"name":"Nike Inc"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, relabeled)
+	assert.Equal(t, http.StatusNotFound, send(r, http.MethodPut, "/api/brands/puma", `{"name":"Puma"}`).Code)
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrBrandExists   = errors.New("la marca ya existe")
+	ErrBrandNotFound = errors.New("marca no encontrada")
+	ErrInvalidBrand  = errors.New("el nombre de la marca es obligatorio")
+)
+
+type Brand struct {
+	ID           string             `json:"id" bson:"-"`
+	ObjectID     primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Name         string             `json:"name" bson:"name"`
+	Slug         string             `json:"slug" bson:"slug"`
+	LogoURL      string             `json:"logo_url,omitempty" bson:"logo_url,omitempty"`
+	Country      string             `json:"country,omitempty" bson:"country,omitempty"`
+	ProductCount int                `json:"product_count" bson:"-"`
+	// Tenant es la tienda dueña de la marca; cada tienda registra sus propias marcas.
+	Tenant string `json:"-" bson:"tenant"`
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+// BrandRepository opera siempre sobre las marcas y productos de la tienda de ctx.
+type BrandRepository interface {
+	Create(ctx context.Context, brand *Brand) error
+	FindAll(ctx context.Context) ([]Brand, error)
+	FindBySlug(ctx context.Context, slug string) (*Brand, error)
+	// Update guarda la marca y, si previousName es distinto del nombre nuevo, pasa a él los productos
+	// que usan previousName en la misma operación. Devuelve cuántos productos cambió.
+	Update(ctx context.Context, brand *Brand, previousName string) (int64, error)
+	Delete(ctx context.Context, id string) error
+	// CountProducts agrupa los productos por el valor de marca tal como está guardado.
+	CountProducts(ctx context.Context) (map[string]int, error)
+}
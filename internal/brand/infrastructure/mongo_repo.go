@@ -0,0 +1,279 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mlsport/config"
+	"mlsport/internal/brand/domain"
+	"mlsport/internal/logging"
+	"mlsport/internal/slug"
+	"mlsport/internal/tenant"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoBrandRepo struct {
+	CollectionName         string
+	ProductsCollectionName string
+	// Relabel, si está configurado, pasa los productos al nombre nuevo en lugar del UpdateMany
+	// directo, en la misma transacción; el repo de productos lo usa para dejar sus eventos.
+	Relabel func(ctx context.Context, previousName, name string) (int64, error)
+}
+
+func NewMongoBrandRepo() *MongoBrandRepo {
+	return &MongoBrandRepo{CollectionName: "brands", ProductsCollectionName: "products"}
+}
+
+// EnsureIndexes asigna a la tienda por defecto las marcas anteriores a los tenants
+// e impide slugs repetidos dentro de una misma tienda.
+func (r *MongoBrandRepo) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	if _, err := collection.UpdateMany(ctx,
+		bson.M{"tenant": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"tenant": tenant.Default}},
+	); err != nil {
+		return err
+	}
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "slug", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// CanonicalizeProducts migra una sola vez las marcas de los productos cargados antes de normalizarlas:
+// cada valor pasa al nombre registrado en su tienda ("NIKE" y "nike " a "Nike") o, si la marca no
+// está registrada, solo se recorta. Devuelve cuántos productos cambió.
+func (r *MongoBrandRepo) CanonicalizeProducts(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	db := config.GetDB()
+	var brands []domain.Brand
+	cursor, err := db.Collection(r.CollectionName).Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	if err := cursor.All(ctx, &brands); err != nil {
+		return 0, err
+	}
+	registered := map[string]map[string]string{}
+	for _, b := range brands {
+		if registered[b.Tenant] == nil {
+			registered[b.Tenant] = map[string]string{}
+		}
+		registered[b.Tenant][b.Slug] = b.Name
+	}
+
+	var groups []struct {
+		ID struct {
+			Tenant string `bson:"tenant"`
+			Brand  string `bson:"brand"`
+		} `bson:"_id"`
+	}
+	cursor, err = db.Collection(r.ProductsCollectionName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"brand": bson.M{"$type": "string"}}},
+		{"$group": bson.M{"_id": bson.M{"tenant": "$tenant", "brand": "$brand"}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	products := db.Collection(r.ProductsCollectionName)
+	for _, g := range groups {
+		name := canonicalName(registered[g.ID.Tenant], g.ID.Brand)
+		if name == g.ID.Brand {
+			continue
+		}
+		res, err := products.UpdateMany(ctx,
+			bson.M{"tenant": g.ID.Tenant, "brand": g.ID.Brand},
+			bson.M{"$set": bson.M{"brand": name}},
+		)
+		if err != nil {
+			return total, err
+		}
+		total += res.ModifiedCount
+	}
+	return total, nil
+}
+
+// canonicalName hace lo mismo que BrandService.Canonical con las marcas de una tienda (slug → nombre).
+func canonicalName(names map[string]string, brand string) string {
+	brand = strings.TrimSpace(brand)
+	if name, ok := names[slug.Make(brand)]; ok && brand != "" {
+		return name
+	}
+	return brand
+}
+
+func (r *MongoBrandRepo) Create(ctx context.Context, b *domain.Brand) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	b.Tenant = tenant.ID(ctx)
+	collection := config.GetDB().Collection(r.CollectionName)
+	res, err := collection.InsertOne(ctx, b)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrBrandExists
+	}
+	if err != nil {
+		return err
+	}
+
+	b.ObjectID = res.InsertedID.(primitive.ObjectID)
+	b.ID = b.ObjectID.Hex()
+	return nil
+}
+
+func (r *MongoBrandRepo) FindAll(ctx context.Context) ([]domain.Brand, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result []domain.Brand
+	collection := config.GetDB().Collection(r.CollectionName)
+	cursor, err := collection.Find(ctx, bson.M{"tenant": tenant.ID(ctx)}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var b domain.Brand
+		if err := cursor.Decode(&b); err != nil {
+			continue
+		}
+		b.ID = b.ObjectID.Hex()
+		result = append(result, b)
+	}
+
+	return result, nil
+}
+
+func (r *MongoBrandRepo) FindBySlug(ctx context.Context, slug string) (*domain.Brand, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var b domain.Brand
+	collection := config.GetDB().Collection(r.CollectionName)
+	err := collection.FindOne(ctx, bson.M{"tenant": tenant.ID(ctx), "slug": slug}).Decode(&b)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrBrandNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b.ID = b.ObjectID.Hex()
+	return &b, nil
+}
+
+func (r *MongoBrandRepo) Update(ctx context.Context, b *domain.Brand, previousName string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(b.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	b.Tenant = tenant.ID(ctx)
+	db := config.GetDB()
+	var relabeled int64
+	err = config.WithTransaction(ctx, func(ctx context.Context) error {
+		relabeled = 0
+		_, err := db.Collection(r.CollectionName).ReplaceOne(ctx, bson.M{"_id": objID, "tenant": b.Tenant}, b)
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrBrandExists
+		}
+		if err != nil {
+			return err
+		}
+		if previousName == "" || previousName == b.Name {
+			return nil
+		}
+
+		// Solo los productos de la tienda. Guardan el nombre registrado (variantes como "NIKE" se
+		// normalizan al escribir y al arrancar), así que basta la igualdad y se usa el índice.
+		if r.Relabel != nil {
+			relabeled, err = r.Relabel(ctx, previousName, b.Name)
+			return err
+		}
+		res, err := db.Collection(r.ProductsCollectionName).UpdateMany(ctx,
+			bson.M{"tenant": b.Tenant, "brand": previousName},
+			bson.M{"$set": bson.M{"brand": b.Name}},
+		)
+		if err != nil {
+			return err
+		}
+		relabeled = res.ModifiedCount
+		return nil
+	})
+	return relabeled, err
+}
+
+func (r *MongoBrandRepo) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	_, err = collection.DeleteOne(ctx, bson.M{"_id": objID, "tenant": tenant.ID(ctx)})
+	return err
+}
+
+func (r *MongoBrandRepo) CountProducts(ctx context.Context) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	coll := config.GetDB().Collection(r.ProductsCollectionName)
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"tenant": tenant.ID(ctx)}},
+		{"$group": bson.M{"_id": "$brand", "count": bson.M{"$sum": 1}}},
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	var result []struct {
+		Brand string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(result))
+	for _, item := range result {
+		counts[item.Brand] += item.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,80 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	"mlsport/config"
+	"mlsport/internal/brand/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// newMock arma un Mongo simulado: cada operación consume, en orden, las respuestas que agrega el test.
+func newMock(t *testing.T, name string, fn func(mt *mtest.T)) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run(name, func(mt *mtest.T) {
+		mt.Setenv("MONGO_DB_NAME", "mlsport_test")
+		config.MongoClient = mt.Client
+		fn(mt)
+	})
+}
+
+func TestCreate_DuplicateSlugIsConflict(t *testing.T) {
+	newMock(t, "duplicada", func(mt *mtest.T) {
+		// Otra petición creó la misma marca entre la consulta del servicio y el alta.
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "E11000 duplicate key"}))
+
+		err := NewMongoBrandRepo().Create(tenant.WithID(context.Background(), "tienda-a"), &domain.Brand{Name: "Nike", Slug: "nike"})
+
+		assert.ErrorIs(t, err, domain.ErrBrandExists)
+	})
+}
+
+func TestCanonicalizeProducts(t *testing.T) {
+	newMock(t, "backfill", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "mlsport_test.brands", mtest.FirstBatch,
+				bson.D{{Key: "name", Value: "Nike"}, {Key: "slug", Value: "nike"}, {Key: "tenant", Value: "tienda-a"}},
+			),
+			mtest.CreateCursorResponse(0, "mlsport_test.products", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: bson.D{{Key: "tenant", Value: "tienda-a"}, {Key: "brand", Value: "Nike"}}}},
+				bson.D{{Key: "_id", Value: bson.D{{Key: "tenant", Value: "tienda-a"}, {Key: "brand", Value: "NIKE"}}}},
+				// En otra tienda Nike no está registrada: solo se recorta.
+				bson.D{{Key: "_id", Value: bson.D{{Key: "tenant", Value: "tienda-b"}, {Key: "brand", Value: "nike "}}}},
+			),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}, bson.E{Key: "nModified", Value: 3}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+
+		n, err := NewMongoBrandRepo().CanonicalizeProducts(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), n)
+
+		var updates []bson.Raw
+		for _, e := range mt.GetAllStartedEvents() {
+			if e.CommandName == "update" {
+				updates = append(updates, e.Command.Lookup("updates").Array().Index(0).Value().Document())
+			}
+		}
+		if assert.Len(t, updates, 2) {
+			assert.Equal(t, "NIKE", updates[0].Lookup("q", "brand").StringValue())
+			assert.Equal(t, "tienda-a", updates[0].Lookup("q", "tenant").StringValue())
+			assert.Equal(t, "Nike", updates[0].Lookup("u", "$set", "brand").StringValue())
+			assert.Equal(t, "tienda-b", updates[1].Lookup("q", "tenant").StringValue())
+			assert.Equal(t, "nike", updates[1].Lookup("u", "$set", "brand").StringValue())
+		}
+	})
+}
+
+func TestCanonicalName(t *testing.T) {
+	names := map[string]string{"adidas": "Adidas"}
+
+	assert.Equal(t, "Adidas", canonicalName(names, " ADIDAS"))
+	assert.Equal(t, "Puma", canonicalName(names, "Puma "))
+	assert.Equal(t, "", canonicalName(names, "  "))
+}
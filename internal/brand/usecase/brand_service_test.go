@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mlsport/internal/brand/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepo struct {
+	items []domain.Brand
+	// counts guarda, por tienda, los productos agrupados por marca tal como están cargados.
+	counts map[string]map[string]int
+}
+
+// relabel mueve, como el repo de Mongo, los productos de previousName a name dentro de la tienda
+// de ctx. Los productos guardan la marca normalizada, así que basta la igualdad.
+func (m *memoryRepo) relabel(ctx context.Context, previousName, name string) int64 {
+	counts := m.counts[tenant.ID(ctx)]
+	n := counts[previousName]
+	if n == 0 {
+		return 0
+	}
+	delete(counts, previousName)
+	counts[name] += n
+	return int64(n)
+}
+
+func (m *memoryRepo) Create(ctx context.Context, b *domain.Brand) error {
+	b.ID = b.Slug
+	b.Tenant = tenant.ID(ctx)
+	m.items = append(m.items, *b)
+	return nil
+}
+func (m *memoryRepo) FindAll(ctx context.Context) ([]domain.Brand, error) {
+	var out []domain.Brand
+	for _, b := range m.items {
+		if b.Tenant == tenant.ID(ctx) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+func (m *memoryRepo) FindBySlug(ctx context.Context, slug string) (*domain.Brand, error) {
+	for _, b := range m.items {
+		if b.Tenant == tenant.ID(ctx) && b.Slug == slug {
+			return &b, nil
+		}
+	}
+	return nil, domain.ErrBrandNotFound
+}
+func (m *memoryRepo) Update(ctx context.Context, b *domain.Brand, previousName string) (int64, error) {
+	for i := range m.items {
+		if m.items[i].Tenant == tenant.ID(ctx) && m.items[i].ID == b.ID {
+			b.Tenant = m.items[i].Tenant
+			m.items[i] = *b
+		}
+	}
+	if previousName == "" || previousName == b.Name {
+		return 0, nil
+	}
+	return m.relabel(ctx, previousName, b.Name), nil
+}
+func (m *memoryRepo) Delete(ctx context.Context, id string) error { return nil }
+func (m *memoryRepo) CountProducts(ctx context.Context) (map[string]int, error) {
+	return m.counts[tenant.ID(ctx)], nil
+}
+
+type failingRepo struct{ memoryRepo }
+
+func (f *failingRepo) FindBySlug(ctx context.Context, slug string) (*domain.Brand, error) {
+	return nil, errors.New("error simulado findbyslug")
+}
+
+func newSeededService(t *testing.T) *BrandService {
+	repo := &memoryRepo{counts: map[string]map[string]int{
+		tenant.Default: {"Nike": 2, "NIKE": 1, "nike ": 1, "Adidas": 3},
+	}}
+	service := NewBrandService(repo)
+	assert.NoError(t, service.Create(context.Background(), &domain.Brand{Name: "Nike", Country: "US"}))
+	return service
+}
+
+func TestCreateBrand_Duplicate(t *testing.T) {
+	ctx := context.Background()
+	service := newSeededService(t)
+
+	err := service.Create(ctx, &domain.Brand{Name: " NIKE"})
+
+	assert.ErrorIs(t, err, domain.ErrBrandExists)
+}
+
+func TestGetAllBrands_MergesCounts(t *testing.T) {
+	ctx := context.Background()
+	service := newSeededService(t)
+
+	list, err := service.GetAll(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, 4, list[0].ProductCount)
+}
+
+func TestCanonical(t *testing.T) {
+	ctx := context.Background()
+	service := newSeededService(t)
+
+	known, err := service.Canonical(ctx, "NIKE ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Nike", known)
+
+	unknown, err := service.Canonical(ctx, " Puma ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Puma", unknown)
+}
+
+func TestCanonical_Error(t *testing.T) {
+	ctx := context.Background()
+	service := NewBrandService(&failingRepo{})
+
+	_, err := service.Canonical(ctx, "Nike")
+
+	assert.Error(t, err)
+}
+
+func TestGetAllBrands_CountsOnlyOwnTenant(t *testing.T) {
+	tiendaA := tenant.WithID(context.Background(), "tienda-a")
+	tiendaB := tenant.WithID(context.Background(), "tienda-b")
+	service := NewBrandService(&memoryRepo{counts: map[string]map[string]int{
+		"tienda-a": {"Nike": 2},
+		"tienda-b": {"NIKE": 5, "Puma": 1},
+	}})
+	assert.NoError(t, service.Create(tiendaA, &domain.Brand{Name: "Nike"}))
+	assert.NoError(t, service.Create(tiendaB, &domain.Brand{Name: "Nike"}))
+	assert.NoError(t, service.Create(tiendaB, &domain.Brand{Name: "Puma"}))
+
+	listA, err := service.GetAll(tiendaA)
+	assert.NoError(t, err)
+	assert.Len(t, listA, 1)
+	assert.Equal(t, 2, listA[0].ProductCount)
+
+	listB, err := service.GetAll(tiendaB)
+	assert.NoError(t, err)
+	assert.Len(t, listB, 2)
+	assert.Equal(t, 5, listB[0].ProductCount)
+}
+
+func TestUpdateBrand_RenameRelabelsOwnTenantProducts(t *testing.T) {
+	tiendaA := tenant.WithID(context.Background(), "tienda-a")
+	tiendaB := tenant.WithID(context.Background(), "tienda-b")
+	repo := &memoryRepo{counts: map[string]map[string]int{
+		"tienda-a": {"Nike": 3, "Puma": 4},
+		"tienda-b": {"Nike": 5},
+	}}
+	service := NewBrandService(repo)
+	var relabeled []string
+	service.OnProductsRelabeled = func(ctx context.Context) {
+		relabeled = append(relabeled, tenant.ID(ctx))
+	}
+	assert.NoError(t, service.Create(tiendaA, &domain.Brand{Name: "Nike"}))
+	assert.NoError(t, service.Create(tiendaB, &domain.Brand{Name: "Nike"}))
+
+	assert.NoError(t, service.Update(tiendaA, "nike", &domain.Brand{Name: "Nike Inc"}))
+
+	assert.Equal(t, map[string]int{"Nike Inc": 3, "Puma": 4}, repo.counts["tienda-a"])
+	assert.Equal(t, map[string]int{"Nike": 5}, repo.counts["tienda-b"])
+	assert.Equal(t, []string{"tienda-a"}, relabeled)
+
+	listA, _ := service.GetAll(tiendaA)
+	assert.Equal(t, "Nike Inc", listA[0].Name)
+	assert.Equal(t, 3, listA[0].ProductCount)
+	canonical, _ := service.Canonical(tiendaB, "nike")
+	assert.Equal(t, "Nike", canonical)
+
+	// Sin cambio de nombre no se reetiqueta nada.
+	assert.NoError(t, service.Update(tiendaA, "nike-inc", &domain.Brand{Name: "Nike Inc", Country: "US"}))
+	assert.Len(t, relabeled, 1)
+}
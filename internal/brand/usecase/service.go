@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"mlsport/internal/brand/domain"
+	"mlsport/internal/slug"
+	"strings"
+)
+
+type BrandService struct {
+	Repo domain.BrandRepository
+	// OnProductsRelabeled se invoca cuando renombrar una marca cambió productos de la tienda de ctx
+	// sin pasar por ProductService (p. ej. para invalidar cachés).
+	OnProductsRelabeled func(ctx context.Context)
+}
+
+func NewBrandService(repo domain.BrandRepository) *BrandService {
+	return &BrandService{Repo: repo}
+}
+
+func (s *BrandService) Create(ctx context.Context, b *domain.Brand) error {
+	if err := prepare(b); err != nil {
+		return err
+	}
+
+	_, err := s.Repo.FindBySlug(ctx, b.Slug)
+	if err == nil {
+		return domain.ErrBrandExists
+	}
+	if !errors.Is(err, domain.ErrBrandNotFound) {
+		return err
+	}
+
+	return s.Repo.Create(ctx, b)
+}
+
+// GetAll lista las marcas de la tienda con su número de productos, sumando variantes como "NIKE" o "nike ".
+func (s *BrandService) GetAll(ctx context.Context) ([]domain.Brand, error) {
+	brands, err := s.Repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.Repo.CountProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bySlug := make(map[string]int, len(counts))
+	for name, n := range counts {
+		bySlug[slug.Make(name)] += n
+	}
+	for i := range brands {
+		brands[i].ProductCount = bySlug[brands[i].Slug]
+	}
+	return brands, nil
+}
+
+func (s *BrandService) GetBySlug(ctx context.Context, ref string) (*domain.Brand, error) {
+	return s.Repo.FindBySlug(ctx, slug.Make(ref))
+}
+
+// Update reemplaza los datos de la marca; si cambia el nombre, los productos de la tienda con el
+// nombre anterior pasan al nuevo.
+func (s *BrandService) Update(ctx context.Context, ref string, input *domain.Brand) error {
+	current, err := s.Repo.FindBySlug(ctx, slug.Make(ref))
+	if err != nil {
+		return err
+	}
+
+	if err := prepare(input); err != nil {
+		return err
+	}
+	if input.Slug != current.Slug {
+		_, err := s.Repo.FindBySlug(ctx, input.Slug)
+		if err == nil {
+			return domain.ErrBrandExists
+		}
+		if !errors.Is(err, domain.ErrBrandNotFound) {
+			return err
+		}
+	}
+
+	input.ID = current.ID
+	input.ObjectID = current.ObjectID
+	relabeled, err := s.Repo.Update(ctx, input, current.Name)
+	if err != nil {
+		return err
+	}
+	if relabeled > 0 && s.OnProductsRelabeled != nil {
+		s.OnProductsRelabeled(ctx)
+	}
+	return nil
+}
+
+func (s *BrandService) Delete(ctx context.Context, ref string) error {
+	current, err := s.Repo.FindBySlug(ctx, slug.Make(ref))
+	if err != nil {
+		return err
+	}
+	return s.Repo.Delete(ctx, current.ID)
+}
+
+// Canonical traduce una marca libre a su nombre registrado; si no está registrada solo se recorta.
+func (s *BrandService) Canonical(ctx context.Context, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil
+	}
+
+	b, err := s.Repo.FindBySlug(ctx, slug.Make(name))
+	if errors.Is(err, domain.ErrBrandNotFound) {
+		return name, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return b.Name, nil
+}
+
+func prepare(b *domain.Brand) error {
+	b.Name = strings.TrimSpace(b.Name)
+	if b.Name == "" {
+		return domain.ErrInvalidBrand
+	}
+	if b.Slug = slug.Make(b.Slug); b.Slug == "" {
+		b.Slug = slug.Make(b.Name)
+	}
+	b.Country = strings.TrimSpace(b.Country)
+	b.LogoURL = strings.TrimSpace(b.LogoURL)
+	return nil
+}
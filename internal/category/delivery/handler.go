@@ -0,0 +1,232 @@
+package delivery
+
+import (
+	"errors"
+	"mlsport/internal/category/domain"
+	"mlsport/internal/category/usecase"
+	"mlsport/internal/logging"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	Service *usecase.CategoryService
+}
+
+func NewCategoryHandler(s *usecase.CategoryService) *CategoryHandler {
+	return &CategoryHandler{Service: s}
+}
+
+// GetAll godoc
+// @Summary Listar categorías administradas
+// @Description Devuelve todas las categorías registradas, sin jerarquía.
+// @Tags Categorías
+// @Produce json
+// @Success 200 {array} domain.Category
+// @Router /categories [get]
+func (h *CategoryHandler) GetAll(c *gin.Context) {
+	list, err := h.Service.GetAll(c.Request.Context())
+	if err != nil {
+		logging.InternalError(c, "no se pudieron obtener las categorías", err)
+		return
+	}
+
+	if len(list) == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "no hay categorías", "data": []domain.Category{}})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// GetTree godoc
+// @Summary Árbol de categorías
+// @Description Devuelve la jerarquía de categorías con sus subcategorías anidadas.
+// @Tags Categorías
+// @Produce json
+// @Success 200 {array} domain.Node
+// @Router /categories/tree [get]
+func (h *CategoryHandler) GetTree(c *gin.Context) {
+	roots, err := h.Service.GetTree(c.Request.Context())
+	if err != nil {
+		logging.InternalError(c, "no se pudo construir el árbol de categorías", err)
+		return
+	}
+	if roots == nil {
+		roots = []*domain.Node{}
+	}
+	c.JSON(http.StatusOK, roots)
+}
+
+// GetBySlug godoc
+// @Summary Consultar una categoría
+// @Description Retorna una categoría a partir de su slug.
+// @Tags Categorías
+// @Produce json
+// @Param slug path string true "Slug de la categoría"
+// @Success 200 {object} domain.Category
+// @Failure 404 {object} map[string]string
+// @Router /categories/{slug} [get]
+func (h *CategoryHandler) GetBySlug(c *gin.Context) {
+	category, err := h.Service.GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		respondError(c, err, "no se pudo obtener la categoría")
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// Create godoc
+// @Summary Crear categoría
+// @Description Registra una categoría; el slug se genera a partir del nombre si no se envía.
+// @Tags Categorías
+// @Accept json
+// @Produce json
+// @Param categoria body domain.Category true "Categoría a registrar"
+// @Success 201 {object} domain.Category
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /categories [post]
+func (h *CategoryHandler) Create(c *gin.Context) {
+	var input domain.Category
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+		return
+	}
+	if err := h.Service.Create(c.Request.Context(), &input); err != nil {
+		respondError(c, err, "no se pudo crear la categoría")
+		return
+	}
+	c.JSON(http.StatusCreated, input)
+}
+
+// Delete godoc
+// @Summary Eliminar categoría
+// @Description Borra una categoría sin subcategorías.
+// @Tags Categorías
+// @Param slug path string true "Slug de la categoría"
+// @Success 200 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /categories/{slug} [delete]
+func (h *CategoryHandler) Delete(c *gin.Context) {
+	if err := h.Service.Delete(c.Request.Context(), c.Param("slug")); err != nil {
+		respondError(c, err, "no se pudo eliminar la categoría")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "eliminado"})
+}
+
+type renameRequest struct {
+	Name string `json:"name" binding:"required"`
+	Slug string `json:"slug"`
+}
+
+type mergeRequest struct {
+	Sources []string `json:"sources" binding:"required"`
+	Target  string   `json:"target" binding:"required"`
+}
+
+// Rename godoc
+// @Summary Renombrar categoría
+// @Description Cambia nombre y slug de una categoría y actualiza todos los productos asociados en una sola operación.
+// @Tags Categorías
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug de la categoría"
+// @Param datos body renameRequest true "Nuevo nombre (y slug opcional)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /categories/{slug}/rename [post]
+func (h *CategoryHandler) Rename(c *gin.Context) {
+	var input renameRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+		return
+	}
+
+	category, change, err := h.Service.Rename(c.Request.Context(), c.Param("slug"), input.Name, input.Slug)
+	if err != nil {
+		respondError(c, err, "no se pudo renombrar la categoría")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"category":         category,
+		"products_updated": change.ProductsUpdated,
+		"change":           change,
+	})
+}
+
+// Merge godoc
+// @Summary Fusionar categorías
+// @Description Mueve productos y subcategorías de las categorías origen a la categoría destino y elimina las de origen.
+// @Tags Categorías
+// @Accept json
+// @Produce json
+// @Param datos body mergeRequest true "Slugs de origen y destino"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /categories/merge [post]
+func (h *CategoryHandler) Merge(c *gin.Context) {
+	var input mergeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+		return
+	}
+
+	category, change, err := h.Service.Merge(c.Request.Context(), input.Sources, input.Target)
+	if err != nil {
+		respondError(c, err, "no se pudieron fusionar las categorías")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"category":         category,
+		"products_updated": change.ProductsUpdated,
+		"change":           change,
+	})
+}
+
+// GetHistory godoc
+// @Summary Historial de categorías
+// @Description Lista los renombrados y fusiones realizados, del más reciente al más antiguo.
+// @Tags Categorías
+// @Produce json
+// @Success 200 {array} domain.Change
+// @Router /categories/history [get]
+func (h *CategoryHandler) GetHistory(c *gin.Context) {
+	list, err := h.Service.GetHistory(c.Request.Context())
+	if err != nil {
+		logging.InternalError(c, "no se pudo obtener el historial", err)
+		return
+	}
+	if list == nil {
+		list = []domain.Change{}
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidCategory), errors.Is(err, domain.ErrParentNotFound),
+		errors.Is(err, domain.ErrInvalidMerge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCategoryExists), errors.Is(err, domain.ErrCategoryHasChildren):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logging.InternalError(c, fallback, err)
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrCategoryExists      = errors.New("la categoría ya existe")
+	ErrCategoryNotFound    = errors.New("categoría no encontrada")
+	ErrParentNotFound      = errors.New("la categoría padre no existe")
+	ErrCategoryHasChildren = errors.New("la categoría tiene subcategorías")
+	ErrInvalidCategory     = errors.New("el nombre de la categoría es obligatorio")
+	ErrInvalidMerge        = errors.New("no se puede fusionar una categoría en sí misma o en una subcategoría")
+)
+
+const (
+	ActionRename = "rename"
+	ActionMerge  = "merge"
+)
+
+type Category struct {
+	ID       string             `json:"id" bson:"-"`
+	ObjectID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Name     string             `json:"name" bson:"name"`
+	Slug     string             `json:"slug" bson:"slug"`
+	ParentID string             `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	// Tenant es la tienda dueña de la categoría; cada tienda administra su propia jerarquía.
+	Tenant string `json:"-" bson:"tenant"`
+}
+
+// Change registra en el historial un renombrado o fusión y cuántos productos afectó.
+type Change struct {
+	ID              string             `json:"id" bson:"-"`
+	ObjectID        primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Action          string             `json:"action" bson:"action"`
+	From            []string           `json:"from" bson:"from"`
+	To              string             `json:"to" bson:"to"`
+	ProductsUpdated int64              `json:"products_updated" bson:"products_updated"`
+	At              time.Time          `json:"at" bson:"at"`
+	Tenant          string             `json:"-" bson:"tenant"`
+}
+
+type Breadcrumb struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
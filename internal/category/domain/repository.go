@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+// CategoryRepository opera siempre sobre las categorías de la tienda de ctx.
+type CategoryRepository interface {
+	Create(ctx context.Context, category *Category) error
+	FindAll(ctx context.Context) ([]Category, error)
+	FindBySlug(ctx context.Context, slug string) (*Category, error)
+	Delete(ctx context.Context, id string) error
+	// Rename guarda la categoría y reetiqueta los productos de change.From en una sola operación.
+	Rename(ctx context.Context, category *Category, change *Change) error
+	// Merge elimina sources, mueve sus hijos y productos a target y registra el cambio.
+	Merge(ctx context.Context, target *Category, sources []Category, change *Change) error
+	FindHistory(ctx context.Context) ([]Change, error)
+}
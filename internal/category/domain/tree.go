@@ -0,0 +1,89 @@
+package domain
+
+import "sort"
+
+type Node struct {
+	Category
+	Children []*Node `json:"children"`
+	parent   *Node
+}
+
+// Tree es una vista en memoria de la jerarquía de categorías.
+type Tree struct {
+	Roots  []*Node
+	bySlug map[string]*Node
+}
+
+func NewTree(categories []Category) *Tree {
+	t := &Tree{bySlug: make(map[string]*Node, len(categories))}
+	byID := make(map[string]*Node, len(categories))
+
+	for _, c := range categories {
+		n := &Node{Category: c, Children: []*Node{}}
+		byID[c.ID] = n
+		t.bySlug[c.Slug] = n
+	}
+
+	for _, c := range categories {
+		n := byID[c.ID]
+		if parent, ok := byID[c.ParentID]; ok && c.ParentID != c.ID {
+			n.parent = parent
+			parent.Children = append(parent.Children, n)
+			continue
+		}
+		t.Roots = append(t.Roots, n)
+	}
+
+	sortNodes(t.Roots)
+	return t
+}
+
+func sortNodes(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	for _, n := range nodes {
+		sortNodes(n.Children)
+	}
+}
+
+func (t *Tree) Find(slug string) *Node {
+	return t.bySlug[slug]
+}
+
+// Breadcrumbs devuelve la ruta desde la raíz hasta la categoría, p. ej. Fútbol > Calzado.
+func (t *Tree) Breadcrumbs(slug string) []Breadcrumb {
+	n := t.bySlug[slug]
+	if n == nil {
+		return nil
+	}
+
+	var path []Breadcrumb
+	seen := map[*Node]bool{}
+	for ; n != nil && !seen[n]; n = n.parent {
+		seen[n] = true
+		path = append([]Breadcrumb{{Name: n.Name, Slug: n.Slug}}, path...)
+	}
+	return path
+}
+
+// Descendants devuelve el slug indicado junto con los de todas sus subcategorías.
+func (t *Tree) Descendants(slug string) []string {
+	n := t.bySlug[slug]
+	if n == nil {
+		return []string{slug}
+	}
+
+	var slugs []string
+	stack := []*Node{n}
+	seen := map[*Node]bool{}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[cur] {
+			continue
+		}
+		seen[cur] = true
+		slugs = append(slugs, cur.Slug)
+		stack = append(stack, cur.Children...)
+	}
+	return slugs
+}
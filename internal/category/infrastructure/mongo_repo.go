@@ -0,0 +1,255 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mlsport/config"
+	"mlsport/internal/category/domain"
+	"mlsport/internal/logging"
+	"mlsport/internal/tenant"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoCategoryRepo struct {
+	CollectionName        string
+	HistoryCollectionName string
+	// ProductsCollectionName es la colección reetiquetada al renombrar o fusionar.
+	ProductsCollectionName string
+	// Relabel, si está configurado, reetiqueta los productos en lugar del UpdateMany directo, en la
+	// misma transacción; el repo de productos lo usa para dejar sus eventos y contadores.
+	Relabel func(ctx context.Context, from []string, to string) (int64, error)
+}
+
+func NewMongoCategoryRepo() *MongoCategoryRepo {
+	return &MongoCategoryRepo{
+		CollectionName:         "categories",
+		HistoryCollectionName:  "category_history",
+		ProductsCollectionName: "products",
+	}
+}
+
+// EnsureIndexes asigna a la tienda por defecto las categorías anteriores a los tenants
+// e impide slugs repetidos dentro de una misma tienda.
+func (r *MongoCategoryRepo) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	db := config.GetDB()
+	for _, name := range []string{r.CollectionName, r.HistoryCollectionName} {
+		if _, err := db.Collection(name).UpdateMany(ctx,
+			bson.M{"tenant": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"tenant": tenant.Default}},
+		); err != nil {
+			return err
+		}
+	}
+	_, err := db.Collection(r.CollectionName).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "slug", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *MongoCategoryRepo) Create(ctx context.Context, c *domain.Category) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	c.Tenant = tenant.ID(ctx)
+	collection := config.GetDB().Collection(r.CollectionName)
+	res, err := collection.InsertOne(ctx, c)
+	if mongo.IsDuplicateKeyError(err) {
+		// Otra petición creó el mismo slug entre la comprobación del servicio y el insert.
+		return domain.ErrCategoryExists
+	}
+	if err != nil {
+		return err
+	}
+
+	c.ObjectID = res.InsertedID.(primitive.ObjectID)
+	c.ID = c.ObjectID.Hex()
+	return nil
+}
+
+func (r *MongoCategoryRepo) FindAll(ctx context.Context) ([]domain.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result []domain.Category
+	collection := config.GetDB().Collection(r.CollectionName)
+	cursor, err := collection.Find(ctx, bson.M{"tenant": tenant.ID(ctx)}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var c domain.Category
+		if err := cursor.Decode(&c); err != nil {
+			continue
+		}
+		c.ID = c.ObjectID.Hex()
+		result = append(result, c)
+	}
+
+	return result, nil
+}
+
+func (r *MongoCategoryRepo) FindBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var c domain.Category
+	collection := config.GetDB().Collection(r.CollectionName)
+	err := collection.FindOne(ctx, bson.M{"tenant": tenant.ID(ctx), "slug": slug}).Decode(&c)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.ID = c.ObjectID.Hex()
+	return &c, nil
+}
+
+func (r *MongoCategoryRepo) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	_, err = collection.DeleteOne(ctx, bson.M{"_id": objID, "tenant": tenant.ID(ctx)})
+	return err
+}
+
+func (r *MongoCategoryRepo) Rename(ctx context.Context, c *domain.Category, change *domain.Change) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.ID)
+	if err != nil {
+		return err
+	}
+
+	db := config.GetDB()
+	return config.WithTransaction(ctx, func(ctx context.Context) error {
+		_, err := db.Collection(r.CollectionName).UpdateOne(ctx,
+			bson.M{"_id": objID, "tenant": tenant.ID(ctx)},
+			bson.M{"$set": bson.M{"name": c.Name, "slug": c.Slug}},
+		)
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrCategoryExists
+		}
+		if err != nil {
+			return err
+		}
+
+		return r.relabelProducts(ctx, db, change)
+	})
+}
+
+func (r *MongoCategoryRepo) Merge(ctx context.Context, target *domain.Category, sources []domain.Category, change *domain.Change) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var sourceIDs []primitive.ObjectID
+	var sourceHexIDs []string
+	for _, src := range sources {
+		objID, err := primitive.ObjectIDFromHex(src.ID)
+		if err != nil {
+			return err
+		}
+		sourceIDs = append(sourceIDs, objID)
+		sourceHexIDs = append(sourceHexIDs, src.ID)
+	}
+
+	db := config.GetDB()
+	categories := db.Collection(r.CollectionName)
+	return config.WithTransaction(ctx, func(ctx context.Context) error {
+		_, err := categories.UpdateMany(ctx,
+			bson.M{"tenant": tenant.ID(ctx), "parent_id": bson.M{"$in": sourceHexIDs}},
+			bson.M{"$set": bson.M{"parent_id": target.ID}},
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, err := categories.DeleteMany(ctx, bson.M{"tenant": tenant.ID(ctx), "_id": bson.M{"$in": sourceIDs}}); err != nil {
+			return err
+		}
+
+		return r.relabelProducts(ctx, db, change)
+	})
+}
+
+// relabelProducts mueve los productos de change.From a change.To y guarda el historial. Solo toca
+// productos de la tienda de ctx: otra tienda puede usar el mismo slug para otra categoría.
+func (r *MongoCategoryRepo) relabelProducts(ctx context.Context, db *mongo.Database, change *domain.Change) error {
+	change.Tenant = tenant.ID(ctx)
+	if r.Relabel != nil {
+		n, err := r.Relabel(ctx, change.From, change.To)
+		if err != nil {
+			return err
+		}
+		change.ProductsUpdated = n
+	} else {
+		res, err := db.Collection(r.ProductsCollectionName).UpdateMany(ctx,
+			bson.M{"tenant": change.Tenant, "category": bson.M{"$in": change.From}},
+			bson.M{"$set": bson.M{"category": change.To}},
+		)
+		if err != nil {
+			return err
+		}
+		change.ProductsUpdated = res.ModifiedCount
+	}
+
+	hist, err := db.Collection(r.HistoryCollectionName).InsertOne(ctx, change)
+	if err != nil {
+		return err
+	}
+	change.ObjectID = hist.InsertedID.(primitive.ObjectID)
+	change.ID = change.ObjectID.Hex()
+	return nil
+}
+
+func (r *MongoCategoryRepo) FindHistory(ctx context.Context) ([]domain.Change, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result []domain.Change
+	collection := config.GetDB().Collection(r.HistoryCollectionName)
+	cursor, err := collection.Find(ctx, bson.M{"tenant": tenant.ID(ctx)}, options.Find().SetSort(bson.M{"at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var c domain.Change
+		if err := cursor.Decode(&c); err != nil {
+			continue
+		}
+		c.ID = c.ObjectID.Hex()
+		result = append(result, c)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"mlsport/internal/category/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// product es lo mínimo que el repo en memoria reetiqueta al renombrar o fusionar.
+type product struct {
+	tenant   string
+	category string
+}
+
+type memoryRepo struct {
+	items    []domain.Category
+	history  []domain.Change
+	products []product
+}
+
+func (m *memoryRepo) Create(ctx context.Context, c *domain.Category) error {
+	c.ID = fmt.Sprintf("id-%d", len(m.items)+1)
+	c.Tenant = tenant.ID(ctx)
+	m.items = append(m.items, *c)
+	return nil
+}
+func (m *memoryRepo) FindAll(ctx context.Context) ([]domain.Category, error) {
+	var out []domain.Category
+	for _, c := range m.items {
+		if c.Tenant == tenant.ID(ctx) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+func (m *memoryRepo) FindBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	for _, c := range m.items {
+		if c.Tenant == tenant.ID(ctx) && c.Slug == slug {
+			return &c, nil
+		}
+	}
+	return nil, domain.ErrCategoryNotFound
+}
+func (m *memoryRepo) Delete(ctx context.Context, id string) error {
+	for i, c := range m.items {
+		if c.ID == id && c.Tenant == tenant.ID(ctx) {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *memoryRepo) Rename(ctx context.Context, c *domain.Category, change *domain.Change) error {
+	for i := range m.items {
+		if m.items[i].ID == c.ID && m.items[i].Tenant == tenant.ID(ctx) {
+			m.items[i] = *c
+		}
+	}
+	m.relabel(ctx, change)
+	return nil
+}
+func (m *memoryRepo) Merge(ctx context.Context, target *domain.Category, sources []domain.Category, change *domain.Change) error {
+	for _, src := range sources {
+		for i := range m.items {
+			if m.items[i].Tenant == tenant.ID(ctx) && m.items[i].ParentID == src.ID {
+				m.items[i].ParentID = target.ID
+			}
+		}
+		_ = m.Delete(ctx, src.ID)
+	}
+	m.relabel(ctx, change)
+	return nil
+}
+func (m *memoryRepo) FindHistory(ctx context.Context) ([]domain.Change, error) {
+	var out []domain.Change
+	for _, c := range m.history {
+		if c.Tenant == tenant.ID(ctx) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// relabel replica el filtro del repo de Mongo: solo productos de la tienda de ctx.
+func (m *memoryRepo) relabel(ctx context.Context, change *domain.Change) {
+	change.Tenant = tenant.ID(ctx)
+	for i := range m.products {
+		if m.products[i].tenant == change.Tenant && containsString(change.From, m.products[i].category) {
+			m.products[i].category = change.To
+			change.ProductsUpdated++
+		}
+	}
+	m.history = append(m.history, *change)
+}
+
+func (m *memoryRepo) categories(tenantID string) []string {
+	var out []string
+	for _, p := range m.products {
+		if p.tenant == tenantID {
+			out = append(out, p.category)
+		}
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func seedService(t *testing.T) *CategoryService {
+	service, _ := seedRepo(t, context.Background())
+	return service
+}
+
+// seedRepo crea Fútbol > Calzado > Guayos en la tienda de ctx con tres productos en calzado,
+// uno de ellos cargado con el nombre en texto libre.
+func seedRepo(t *testing.T, ctx context.Context) (*CategoryService, *memoryRepo) {
+	repo := &memoryRepo{}
+	service := NewCategoryService(repo)
+	seed(t, ctx, service, repo)
+	return service, repo
+}
+
+func seed(t *testing.T, ctx context.Context, service *CategoryService, repo *memoryRepo) {
+	futbol := &domain.Category{Name: "Fútbol"}
+	assert.NoError(t, service.Create(ctx, futbol))
+	calzado := &domain.Category{Name: "Calzado", ParentID: futbol.ID}
+	assert.NoError(t, service.Create(ctx, calzado))
+	assert.NoError(t, service.Create(ctx, &domain.Category{Name: "Guayos", ParentID: calzado.ID}))
+
+	for _, cat := range []string{"calzado", "calzado", "Calzado"} {
+		repo.products = append(repo.products, product{tenant: tenant.ID(ctx), category: cat})
+	}
+}
+
+func TestCreateCategory_Duplicate(t *testing.T) {
+	ctx := context.Background()
+	service := seedService(t)
+
+	err := service.Create(ctx, &domain.Category{Name: "calzado "})
+
+	assert.ErrorIs(t, err, domain.ErrCategoryExists)
+}
+
+func TestCreateCategory_UnknownParent(t *testing.T) {
+	ctx := context.Background()
+	service := seedService(t)
+
+	err := service.Create(ctx, &domain.Category{Name: "Medias", ParentID: "nope"})
+
+	assert.ErrorIs(t, err, domain.ErrParentNotFound)
+}
+
+func TestTreeBreadcrumbsAndDescendants(t *testing.T) {
+	ctx := context.Background()
+	service := seedService(t)
+
+	tree, err := service.Tree(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, tree.Roots, 1)
+	assert.Equal(t, []domain.Breadcrumb{
+		{Name: "Fútbol", Slug: "futbol"},
+		{Name: "Calzado", Slug: "calzado"},
+		{Name: "Guayos", Slug: "guayos"},
+	}, tree.Breadcrumbs("guayos"))
+	assert.ElementsMatch(t, []string{"futbol", "calzado", "guayos"}, tree.Descendants("futbol"))
+}
+
+func TestDeleteCategory_WithChildren(t *testing.T) {
+	ctx := context.Background()
+	service := seedService(t)
+
+	err := service.Delete(ctx, "calzado")
+
+	assert.ErrorIs(t, err, domain.ErrCategoryHasChildren)
+	assert.NoError(t, service.Delete(ctx, "guayos"))
+}
+
+func TestRenameCategory(t *testing.T) {
+	ctx := context.Background()
+	service := seedService(t)
+
+	renamed, change, err := service.Rename(ctx, "calzado", "Zapatos", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "zapatos", renamed.Slug)
+	assert.Equal(t, domain.ActionRename, change.Action)
+	assert.Equal(t, []string{"calzado", "Calzado"}, change.From)
+	assert.Equal(t, int64(3), change.ProductsUpdated)
+
+	tree, _ := service.Tree(ctx)
+	assert.Len(t, tree.Breadcrumbs("guayos"), 3)
+	assert.Equal(t, "zapatos", tree.Breadcrumbs("guayos")[1].Slug)
+}
+
+func TestRenameCategory_Conflict(t *testing.T) {
+	ctx := context.Background()
+	service := seedService(t)
+
+	_, _, err := service.Rename(ctx, "calzado", "Guayos", "")
+
+	assert.ErrorIs(t, err, domain.ErrCategoryExists)
+}
+
+func TestMergeCategories(t *testing.T) {
+	ctx := context.Background()
+	service := seedService(t)
+	assert.NoError(t, service.Create(ctx, &domain.Category{Name: "Ropa"}))
+
+	target, change, err := service.Merge(ctx, []string{"calzado", "Calzado"}, "ropa")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ropa", target.Slug)
+	assert.Equal(t, int64(3), change.ProductsUpdated)
+
+	tree, _ := service.Tree(ctx)
+	assert.Nil(t, tree.Find("calzado"))
+	assert.Equal(t, "ropa", tree.Breadcrumbs("guayos")[0].Slug)
+
+	history, _ := service.GetHistory(ctx)
+	assert.Len(t, history, 1)
+}
+
+func TestMergeCategories_IntoDescendant(t *testing.T) {
+	ctx := context.Background()
+	service := seedService(t)
+
+	_, _, err := service.Merge(ctx, []string{"futbol"}, "guayos")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidMerge)
+}
+
+func TestRenameCategory_OnlyRelabelsOwnTenant(t *testing.T) {
+	tiendaA := tenant.WithID(context.Background(), "tienda-a")
+	tiendaB := tenant.WithID(context.Background(), "tienda-b")
+	service, repo := seedRepo(t, tiendaA)
+	seed(t, tiendaB, service, repo)
+
+	_, change, err := service.Rename(tiendaA, "calzado", "Zapatos", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), change.ProductsUpdated)
+	assert.Equal(t, []string{"zapatos", "zapatos", "zapatos"}, repo.categories("tienda-a"))
+	assert.Equal(t, []string{"calzado", "calzado", "Calzado"}, repo.categories("tienda-b"))
+
+	other, err := service.GetBySlug(tiendaB, "calzado")
+	assert.NoError(t, err)
+	assert.Equal(t, "Calzado", other.Name)
+	history, _ := service.GetHistory(tiendaB)
+	assert.Empty(t, history)
+}
+
+func TestMergeCategories_OnlyRelabelsOwnTenant(t *testing.T) {
+	tiendaA := tenant.WithID(context.Background(), "tienda-a")
+	tiendaB := tenant.WithID(context.Background(), "tienda-b")
+	service, repo := seedRepo(t, tiendaA)
+	seed(t, tiendaB, service, repo)
+	assert.NoError(t, service.Create(tiendaA, &domain.Category{Name: "Ropa"}))
+
+	_, change, err := service.Merge(tiendaA, []string{"calzado"}, "ropa")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), change.ProductsUpdated)
+	assert.Equal(t, []string{"ropa", "ropa", "ropa"}, repo.categories("tienda-a"))
+	assert.Equal(t, []string{"calzado", "calzado", "Calzado"}, repo.categories("tienda-b"))
+
+	tree, _ := service.Tree(tiendaB)
+	assert.NotNil(t, tree.Find("calzado"))
+	assert.Equal(t, "calzado", tree.Breadcrumbs("guayos")[1].Slug)
+}
+
+func TestMergeCategories_UnknownInTenant(t *testing.T) {
+	tiendaA := tenant.WithID(context.Background(), "tienda-a")
+	service, _ := seedRepo(t, tiendaA)
+	assert.NoError(t, service.Create(context.Background(), &domain.Category{Name: "Ropa"}))
+
+	_, _, err := service.Merge(tiendaA, []string{"calzado"}, "ropa")
+
+	assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"mlsport/internal/category/domain"
+	"mlsport/internal/slug"
+	"strings"
+	"time"
+)
+
+type CategoryService struct {
+	Repo domain.CategoryRepository
+	// OnProductsRelabeled se invoca tras renombrar o fusionar, cuando los productos de la tienda
+	// de ctx cambiaron sin pasar por ProductService (p. ej. para invalidar cachés).
+	OnProductsRelabeled func(ctx context.Context)
+}
+
+func NewCategoryService(repo domain.CategoryRepository) *CategoryService {
+	return &CategoryService{Repo: repo}
+}
+
+func (s *CategoryService) Create(ctx context.Context, c *domain.Category) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return domain.ErrInvalidCategory
+	}
+
+	if c.Slug = slug.Make(c.Slug); c.Slug == "" {
+		c.Slug = slug.Make(c.Name)
+	}
+
+	_, err := s.Repo.FindBySlug(ctx, c.Slug)
+	if err == nil {
+		return domain.ErrCategoryExists
+	}
+	if !errors.Is(err, domain.ErrCategoryNotFound) {
+		return err
+	}
+
+	if c.ParentID != "" {
+		all, err := s.Repo.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		if !containsID(all, c.ParentID) {
+			return domain.ErrParentNotFound
+		}
+	}
+
+	return s.Repo.Create(ctx, c)
+}
+
+func (s *CategoryService) GetAll(ctx context.Context) ([]domain.Category, error) {
+	return s.Repo.FindAll(ctx)
+}
+
+func (s *CategoryService) GetBySlug(ctx context.Context, ref string) (*domain.Category, error) {
+	return s.Repo.FindBySlug(ctx, slug.Make(ref))
+}
+
+func (s *CategoryService) GetTree(ctx context.Context) ([]*domain.Node, error) {
+	tree, err := s.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Roots, nil
+}
+
+// Tree carga la jerarquía completa de la tienda de ctx; también la usa ProductService para breadcrumbs y descendientes.
+func (s *CategoryService) Tree(ctx context.Context) (*domain.Tree, error) {
+	all, err := s.Repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewTree(all), nil
+}
+
+func (s *CategoryService) Delete(ctx context.Context, ref string) error {
+	tree, err := s.Tree(ctx)
+	if err != nil {
+		return err
+	}
+
+	node := tree.Find(slug.Make(ref))
+	if node == nil {
+		return domain.ErrCategoryNotFound
+	}
+	if len(node.Children) > 0 {
+		return domain.ErrCategoryHasChildren
+	}
+
+	return s.Repo.Delete(ctx, node.ID)
+}
+
+// Rename cambia nombre y slug de una categoría y reetiqueta todos sus productos.
+func (s *CategoryService) Rename(ctx context.Context, ref, name, newSlug string) (*domain.Category, *domain.Change, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil, domain.ErrInvalidCategory
+	}
+	if newSlug = slug.Make(newSlug); newSlug == "" {
+		newSlug = slug.Make(name)
+	}
+
+	current, err := s.Repo.FindBySlug(ctx, slug.Make(ref))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if newSlug != current.Slug {
+		_, err := s.Repo.FindBySlug(ctx, newSlug)
+		if err == nil {
+			return nil, nil, domain.ErrCategoryExists
+		}
+		if !errors.Is(err, domain.ErrCategoryNotFound) {
+			return nil, nil, err
+		}
+	}
+
+	change := &domain.Change{
+		Action: domain.ActionRename,
+		From:   labels(*current),
+		To:     newSlug,
+		At:     time.Now().UTC(),
+	}
+
+	renamed := *current
+	renamed.Name = name
+	renamed.Slug = newSlug
+	if err := s.Repo.Rename(ctx, &renamed, change); err != nil {
+		return nil, nil, err
+	}
+	s.productsRelabeled(ctx)
+	return &renamed, change, nil
+}
+
+// Merge fusiona las categorías sources en target: sus productos y subcategorías pasan a target.
+func (s *CategoryService) Merge(ctx context.Context, sources []string, target string) (*domain.Category, *domain.Change, error) {
+	tree, err := s.Tree(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targetNode := tree.Find(slug.Make(target))
+	if targetNode == nil {
+		return nil, nil, domain.ErrCategoryNotFound
+	}
+
+	change := &domain.Change{
+		Action: domain.ActionMerge,
+		To:     targetNode.Slug,
+		At:     time.Now().UTC(),
+	}
+
+	var merged []domain.Category
+	seen := map[string]bool{}
+	for _, ref := range sources {
+		node := tree.Find(slug.Make(ref))
+		if node == nil {
+			return nil, nil, domain.ErrCategoryNotFound
+		}
+		if seen[node.Slug] {
+			continue
+		}
+		seen[node.Slug] = true
+
+		for _, d := range tree.Descendants(node.Slug) {
+			if d == targetNode.Slug {
+				return nil, nil, domain.ErrInvalidMerge
+			}
+		}
+
+		merged = append(merged, node.Category)
+		change.From = append(change.From, labels(node.Category)...)
+	}
+	if len(merged) == 0 {
+		return nil, nil, domain.ErrInvalidMerge
+	}
+
+	result := targetNode.Category
+	if err := s.Repo.Merge(ctx, &result, merged, change); err != nil {
+		return nil, nil, err
+	}
+	s.productsRelabeled(ctx)
+	return &result, change, nil
+}
+
+func (s *CategoryService) GetHistory(ctx context.Context) ([]domain.Change, error) {
+	return s.Repo.FindHistory(ctx)
+}
+
+func (s *CategoryService) productsRelabeled(ctx context.Context) {
+	if s.OnProductsRelabeled != nil {
+		s.OnProductsRelabeled(ctx)
+	}
+}
+
+// labels incluye el slug y el nombre para alcanzar también productos cargados con texto libre.
+func labels(c domain.Category) []string {
+	out := []string{c.Slug}
+	if c.Name != c.Slug {
+		out = append(out, c.Name)
+	}
+	return out
+}
+
+func containsID(categories []domain.Category, id string) bool {
+	for _, c := range categories {
+		if c.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+// Package events es un bus de eventos en memoria: los servicios publican eventos de dominio y
+// otros módulos (caché, auditoría, webhooks, alertas) se suscriben sin que el publicador los conozca.
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// All suscribe un handler a todos los eventos.
+const All = "*"
+
+const DefaultAsyncQueue = 256
+
+// Event es cualquier evento publicable; EventName identifica el tipo (p. ej. "product.created").
+type Event interface {
+	EventName() string
+}
+
+// Handler procesa un evento. En entrega síncrona su error vuelve a quien publica.
+type Handler func(ctx context.Context, e Event) error
+
+// Publisher es lo que necesitan los servicios para emitir eventos.
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+type Option func(*subscriber)
+
+// Async entrega los eventos desde un goroutine propio del suscriptor, con una cola de queue eventos.
+// Si la cola está llena el evento se descarta (y se cuenta en Dropped) para no frenar a quien publica.
+func Async(queue int) Option {
+	return func(s *subscriber) {
+		if queue <= 0 {
+			queue = DefaultAsyncQueue
+		}
+		s.queue = make(chan delivery, queue)
+	}
+}
+
+// Named identifica al suscriptor en los logs.
+func Named(name string) Option {
+	return func(s *subscriber) { s.name = name }
+}
+
+type delivery struct {
+	ctx   context.Context
+	event Event
+}
+
+type subscriber struct {
+	id      uint64
+	name    string
+	event   string
+	handler Handler
+	queue   chan delivery
+	done    chan struct{}
+}
+
+// Bus registra suscriptores por nombre de evento. Los síncronos corren en el goroutine de Publish,
+// en orden de suscripción; los asíncronos reciben el evento por su cola.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[string][]*subscriber
+	nextID uint64
+	closed bool
+
+	dropped atomic.Uint64
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: map[string][]*subscriber{}}
+}
+
+// Subscribe registra h para el evento name (o All) y devuelve la función para darlo de baja.
+func (b *Bus) Subscribe(name string, h Handler, opts ...Option) (unsubscribe func()) {
+	sub := &subscriber{event: name, handler: h}
+	for _, opt := range opts {
+		opt(sub)
+	}
+	if sub.name == "" {
+		sub.name = name
+	}
+
+	b.mu.Lock()
+	b.nextID++
+	sub.id = b.nextID
+	b.subs[name] = append(b.subs[name], sub)
+	b.mu.Unlock()
+
+	if sub.queue != nil {
+		sub.done = make(chan struct{})
+		go sub.run()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if b.remove(sub) && sub.queue != nil {
+				close(sub.queue)
+				<-sub.done
+			}
+		})
+	}
+}
+
+// Publish entrega los eventos en orden. Devuelve los errores de los suscriptores síncronos; los de los
+// asíncronos solo se registran en el log.
+func (b *Bus) Publish(ctx context.Context, evs ...Event) error {
+	var errs []error
+	for _, e := range evs {
+		b.mu.RLock()
+		if b.closed {
+			b.mu.RUnlock()
+			return errors.New("bus de eventos cerrado")
+		}
+		subs := append(append([]*subscriber(nil), b.subs[e.EventName()]...), b.subs[All]...)
+		for _, sub := range subs {
+			if sub.queue == nil {
+				continue
+			}
+			// Se encola con el lock tomado para no competir con el cierre de la cola.
+			select {
+			case sub.queue <- delivery{ctx: context.WithoutCancel(ctx), event: e}:
+			default:
+				b.dropped.Add(1)
+				slog.WarnContext(ctx, "Evento descartado: cola llena", "event", e.EventName(), "subscriber", sub.name)
+			}
+		}
+		b.mu.RUnlock()
+
+		for _, sub := range subs {
+			if sub.queue != nil {
+				continue
+			}
+			if err := sub.call(ctx, e); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", sub.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Dropped cuenta los eventos descartados por colas asíncronas llenas.
+func (b *Bus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Close deja de aceptar eventos y espera a que los suscriptores asíncronos vacíen su cola.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	var async []*subscriber
+	for _, subs := range b.subs {
+		for _, sub := range subs {
+			if sub.queue != nil {
+				async = append(async, sub)
+			}
+		}
+	}
+	b.subs = map[string][]*subscriber{}
+	b.mu.Unlock()
+
+	for _, sub := range async {
+		close(sub.queue)
+		<-sub.done
+	}
+}
+
+func (b *Bus) remove(sub *subscriber) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs := b.subs[sub.event]
+	for i, s := range subs {
+		if s.id == sub.id {
+			b.subs[sub.event] = append(subs[:i:i], subs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *subscriber) run() {
+	defer close(s.done)
+	for d := range s.queue {
+		if err := s.call(d.ctx, d.event); err != nil {
+			slog.ErrorContext(d.ctx, "Error en suscriptor", "subscriber", s.name, "event", d.event.EventName(), "error", err)
+		}
+	}
+}
+
+// call aísla al bus del pánico de un suscriptor.
+func (s *subscriber) call(ctx context.Context, e Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pánico: %v", r)
+		}
+	}()
+	return s.handler(ctx, e)
+}
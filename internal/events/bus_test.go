@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEvent string
+
+func (e testEvent) EventName() string { return string(e) }
+
+func TestBus_SyncDeliveryInOrder(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	bus.Subscribe("a", func(ctx context.Context, e Event) error {
+		got = append(got, "a1:"+e.EventName())
+		return nil
+	})
+	bus.Subscribe(All, func(ctx context.Context, e Event) error {
+		got = append(got, "all:"+e.EventName())
+		return nil
+	})
+	bus.Subscribe("a", func(ctx context.Context, e Event) error {
+		got = append(got, "a2:"+e.EventName())
+		return nil
+	})
+
+	assert.NoError(t, bus.Publish(context.Background(), testEvent("a"), testEvent("b")))
+	assert.Equal(t, []string{"a1:a", "a2:a", "all:a", "all:b"}, got)
+}
+
+func TestBus_SyncErrorsAndPanicsReturned(t *testing.T) {
+	bus := NewBus()
+	bus.Subscribe("a", func(ctx context.Context, e Event) error {
+		return errors.New("falla")
+	}, Named("auditoria"))
+	bus.Subscribe("a", func(ctx context.Context, e Event) error {
+		panic("explota")
+	})
+	delivered := false
+	bus.Subscribe("a", func(ctx context.Context, e Event) error {
+		delivered = true
+		return nil
+	})
+
+	err := bus.Publish(context.Background(), testEvent("a"))
+	assert.ErrorContains(t, err, "auditoria: falla")
+	assert.ErrorContains(t, err, "pánico: explota")
+	assert.True(t, delivered)
+}
+
+func TestBus_AsyncDeliveryAndClose(t *testing.T) {
+	bus := NewBus()
+	var mu sync.Mutex
+	var got []string
+	release := make(chan struct{})
+	bus.Subscribe("a", func(ctx context.Context, e Event) error {
+		<-release
+		// El contexto de la petición puede cancelarse: el asíncrono recibe uno que no se cancela.
+		assert.NoError(t, ctx.Err())
+		mu.Lock()
+		got = append(got, e.EventName())
+		mu.Unlock()
+		return nil
+	}, Async(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NoError(t, bus.Publish(ctx, testEvent("a")))
+	cancel()
+	// El handler está ocupado con el primero y la cola (1) tiene el segundo: el tercero se descarta.
+	assert.NoError(t, bus.Publish(context.Background(), testEvent("a")))
+	assert.Eventually(t, func() bool {
+		_ = bus.Publish(context.Background(), testEvent("a"))
+		return bus.Dropped() > 0
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	bus.Close()
+	assert.Len(t, got, 2)
+	assert.Error(t, bus.Publish(context.Background(), testEvent("a")))
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	bus := NewBus()
+	calls := 0
+	unsubscribe := bus.Subscribe("a", func(ctx context.Context, e Event) error {
+		calls++
+		return nil
+	})
+
+	_ = bus.Publish(context.Background(), testEvent("a"))
+	unsubscribe()
+	unsubscribe()
+	_ = bus.Publish(context.Background(), testEvent("a"))
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,17 @@
+package events
+
+import "context"
+
+type idKey struct{}
+
+// ContextWithID asocia al ctx de Publish un identificador estable del evento (p. ej. el de su
+// entrada en el outbox), para que los suscriptores descarten entregas repetidas.
+func ContextWithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// IDFromContext devuelve el identificador del evento, o "" si quien publica no lo informó.
+func IDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
@@ -0,0 +1,145 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"mlsport/internal/auth"
+	"mlsport/internal/logging"
+	"mlsport/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	DefaultTTL         = 24 * time.Hour
+	DefaultLockTimeout = time.Minute
+	MaxKeyLength       = 255
+)
+
+// Guard aplica Idempotency-Key a POST y PATCH. Las claves son de cada cliente (usuario, API key o
+// IP), así que dos clientes pueden usar la misma sin pisarse.
+type Guard struct {
+	Store Store
+	// TTL es la ventana en que se repite la respuesta guardada.
+	TTL time.Duration
+	// LockTimeout es cuánto se espera a una petición en curso antes de dar la clave por abandonada.
+	LockTimeout time.Duration
+
+	now func() time.Time
+}
+
+func NewGuard(store Store) *Guard {
+	return &Guard{Store: store, TTL: DefaultTTL, LockTimeout: DefaultLockTimeout, now: time.Now}
+}
+
+func (g *Guard) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderKey)
+		if key == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch) {
+			c.Next()
+			return
+		}
+		if len(key) > MaxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key demasiado larga"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "no se pudo leer el cuerpo"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := g.now().UTC().Truncate(time.Millisecond)
+		rec := &Record{
+			Key:         auth.ClientID(c) + "|" + tenant.ID(c.Request.Context()) + "|" + key,
+			Fingerprint: fingerprint(c.Request, body),
+			CreatedAt:   now,
+			LockedUntil: now.Add(g.LockTimeout),
+			ExpiresAt:   now.Add(g.TTL),
+		}
+		existing, err := g.Store.Reserve(c.Request.Context(), rec, now)
+		if err != nil {
+			// Sin poder verificar la clave no se ejecuta: podría duplicar la escritura.
+			slog.ErrorContext(c.Request.Context(), "Error reservando Idempotency-Key", logging.Err(err))
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "no se pudo verificar la Idempotency-Key"})
+			return
+		}
+		if existing != nil {
+			replay(c, existing, rec.Fingerprint)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Un reintento con el mismo cuerpo puede tener otro resultado tras un error del servidor o
+		// de credenciales, o al pasar el límite de uso: esas respuestas no se guardan.
+		ctx := c.Request.Context()
+		switch status := recorder.Status(); {
+		case status >= 500, status == http.StatusUnauthorized, status == http.StatusForbidden, status == http.StatusTooManyRequests:
+			if err := g.Store.Release(ctx, rec); err != nil {
+				slog.ErrorContext(ctx, "Error liberando Idempotency-Key", logging.Err(err))
+			}
+		default:
+			rec.Completed = true
+			rec.Status = status
+			rec.ContentType = recorder.Header().Get("Content-Type")
+			rec.Body = recorder.body.Bytes()
+			if err := g.Store.Complete(ctx, rec); err != nil {
+				slog.ErrorContext(ctx, "Error guardando respuesta de Idempotency-Key", logging.Err(err))
+			}
+		}
+	}
+}
+
+// replay responde a un reintento con la respuesta guardada, o explica por qué no puede.
+func replay(c *gin.Context, rec *Record, fingerprint string) {
+	switch {
+	case rec.Fingerprint != fingerprint:
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "la Idempotency-Key ya se usó con otra petición"})
+	case !rec.Completed:
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "hay una petición en curso con esta Idempotency-Key"})
+	default:
+		c.Header(HeaderReplayed, "true")
+		c.Data(rec.Status, rec.ContentType, rec.Body)
+		c.Abort()
+	}
+}
+
+// fingerprint resume método, ruta y cuerpo: con la misma clave deben repetirse los tres.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copia el cuerpo de la respuesta mientras se escribe.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,146 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingStore struct{ MemoryStore }
+
+func (*failingStore) Reserve(context.Context, *Record, time.Time) (*Record, error) {
+	return nil, errors.New("mongo caído")
+}
+
+type fixture struct {
+	router  *gin.Engine
+	guard   *Guard
+	now     time.Time
+	created int
+	status  int
+}
+
+func newFixture(store Store) *fixture {
+	gin.SetMode(gin.TestMode)
+	f := &fixture{now: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), status: http.StatusCreated}
+	f.guard = NewGuard(store)
+	f.guard.now = func() time.Time { return f.now }
+
+	f.router = gin.New()
+	f.router.Use(f.guard.Middleware())
+	handler := func(c *gin.Context) {
+		var body map[string]interface{}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+			return
+		}
+		f.created++
+		c.JSON(f.status, gin.H{"id": f.created, "name": body["name"]})
+	}
+	f.router.POST("/products", handler)
+	f.router.PATCH("/products/:id", handler)
+	return f
+}
+
+func (f *fixture) do(method, path, key, body, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":1234"
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGuard_ReplaysFirstResponse(t *testing.T) {
+	f := newFixture(NewMemoryStore())
+
+	first := f.do(http.MethodPost, "/products", "k1", `{"name":"Pelota"}`, "10.0.0.1")
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	retry := f.do(http.MethodPost, "/products", "k1", `{"name":"Pelota"}`, "10.0.0.1")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get(HeaderReplayed))
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+	assert.Equal(t, 1, f.created)
+
+	// Otro cuerpo, otra ruta u otro método con la misma clave es un error del cliente.
+	assert.Equal(t, http.StatusUnprocessableEntity, f.do(http.MethodPost, "/products", "k1", `{"name":"Red"}`, "10.0.0.1").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, f.do(http.MethodPatch, "/products/1", "k1", `{"name":"Pelota"}`, "10.0.0.1").Code)
+
+	// La clave es de cada cliente, y sin clave no hay idempotencia.
+	assert.Equal(t, http.StatusCreated, f.do(http.MethodPost, "/products", "k1", `{"name":"Pelota"}`, "10.0.0.2").Code)
+	assert.Equal(t, http.StatusCreated, f.do(http.MethodPost, "/products", "", `{"name":"Pelota"}`, "10.0.0.1").Code)
+	assert.Equal(t, 3, f.created)
+
+	// Pasada la ventana, la clave se puede reutilizar.
+	f.now = f.now.Add(DefaultTTL)
+	assert.Empty(t, f.do(http.MethodPost, "/products", "k1", `{"name":"Red"}`, "10.0.0.1").Header().Get(HeaderReplayed))
+	assert.Equal(t, 4, f.created)
+}
+
+func TestGuard_StoresClientErrorsButReleasesServerErrors(t *testing.T) {
+	f := newFixture(NewMemoryStore())
+
+	assert.Equal(t, http.StatusBadRequest, f.do(http.MethodPost, "/products", "bad", `{`, "10.0.0.1").Code)
+	w := f.do(http.MethodPost, "/products", "bad", `{`, "10.0.0.1")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "true", w.Header().Get(HeaderReplayed))
+
+	f.status = http.StatusInternalServerError
+	assert.Equal(t, http.StatusInternalServerError, f.do(http.MethodPatch, "/products/1", "k2", `{"stock":3}`, "10.0.0.1").Code)
+	f.status = http.StatusOK
+	w = f.do(http.MethodPatch, "/products/1", "k2", `{"stock":3}`, "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(HeaderReplayed))
+	assert.Equal(t, 2, f.created)
+}
+
+func TestGuard_InFlightAndAbandonedReservations(t *testing.T) {
+	store := NewMemoryStore()
+	f := newFixture(store)
+
+	// Otra petición con la misma clave sigue en curso.
+	rec := &Record{
+		Key:         "ip:10.0.0.1|default|k3",
+		Fingerprint: fingerprint(httptest.NewRequest(http.MethodPost, "/products", nil), []byte(`{"name":"Pelota"}`)),
+		CreatedAt:   f.now,
+		LockedUntil: f.now.Add(DefaultLockTimeout),
+		ExpiresAt:   f.now.Add(DefaultTTL),
+	}
+	existing, err := store.Reserve(context.Background(), rec, f.now)
+	require.NoError(t, err)
+	require.Nil(t, existing)
+
+	w := f.do(http.MethodPost, "/products", "k3", `{"name":"Pelota"}`, "10.0.0.1")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, 0, f.created)
+
+	// Si nunca termina, la reserva vence y el reintento se ejecuta; la original ya no puede guardar.
+	f.now = f.now.Add(DefaultLockTimeout)
+	assert.Equal(t, http.StatusCreated, f.do(http.MethodPost, "/products", "k3", `{"name":"Pelota"}`, "10.0.0.1").Code)
+	assert.ErrorIs(t, store.Complete(context.Background(), rec), ErrNotFound)
+	assert.Equal(t, "true", f.do(http.MethodPost, "/products", "k3", `{"name":"Pelota"}`, "10.0.0.1").Header().Get(HeaderReplayed))
+	assert.Equal(t, 1, f.created)
+}
+
+func TestGuard_RejectsWhenStoreFails(t *testing.T) {
+	f := newFixture(&failingStore{})
+	assert.Equal(t, http.StatusServiceUnavailable, f.do(http.MethodPost, "/products", "k", `{"name":"Pelota"}`, "10.0.0.1").Code)
+	assert.Equal(t, 0, f.created)
+
+	f.guard.Store = NewMemoryStore()
+	assert.Equal(t, http.StatusBadRequest, f.do(http.MethodPost, "/products", strings.Repeat("x", MaxKeyLength+1), `{}`, "10.0.0.1").Code)
+}
@@ -0,0 +1,94 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"mlsport/config"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore guarda los registros en idempotency_keys; un índice TTL sobre expires_at los borra al
+// vencer (con hasta un minuto de demora, por eso también se comprueba la fecha al leerlos).
+type MongoStore struct {
+	CollectionName string
+}
+
+func NewMongoStore() *MongoStore {
+	return &MongoStore{CollectionName: "idempotency_keys"}
+}
+
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := config.GetDB().Collection(s.CollectionName).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (s *MongoStore) Reserve(ctx context.Context, rec *Record, now time.Time) (*Record, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(s.CollectionName)
+	_, err := collection.InsertOne(ctx, rec)
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	// La clave existe: se toma solo si venció o quedó abandonada, en una sola operación para que
+	// no la tomen dos reintentos a la vez.
+	reusable := bson.M{"_id": rec.Key, "$or": bson.A{
+		bson.M{"expires_at": bson.M{"$lte": now}},
+		bson.M{"completed": false, "locked_until": bson.M{"$lte": now}},
+	}}
+	res, err := collection.ReplaceOne(ctx, reusable, rec)
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 1 {
+		return nil, nil
+	}
+
+	var existing Record
+	err = collection.FindOne(ctx, bson.M{"_id": rec.Key}).Decode(&existing)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Se liberó entre medio: se vuelve a intentar la reserva.
+		return s.Reserve(ctx, rec, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+func (s *MongoStore) Complete(ctx context.Context, rec *Record) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := config.GetDB().Collection(s.CollectionName).ReplaceOne(ctx, bson.M{"_id": rec.Key, "created_at": rec.CreatedAt}, rec)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) Release(ctx context.Context, rec *Record) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := config.GetDB().Collection(s.CollectionName).DeleteOne(ctx, bson.M{"_id": rec.Key, "created_at": rec.CreatedAt})
+	return err
+}
@@ -0,0 +1,104 @@
+// Package idempotency hace seguros los reintentos de escrituras con la cabecera Idempotency-Key:
+// la primera respuesta se guarda junto a una huella de la petición y se repite, sin volver a
+// ejecutarla, a los reintentos con la misma clave dentro de la ventana.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound indica que la clave no tiene registro (o ya no es de quien la liberaba).
+var ErrNotFound = errors.New("clave de idempotencia no encontrada")
+
+// Record es una clave en curso (sin Completed) o con su respuesta guardada.
+type Record struct {
+	Key         string    `bson:"_id"`
+	Fingerprint string    `bson:"fingerprint"`
+	Completed   bool      `bson:"completed"`
+	Status      int       `bson:"status,omitempty"`
+	ContentType string    `bson:"content_type,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
+	// LockedUntil vence la reserva de una petición en curso que no terminó (p. ej. se cayó el proceso).
+	LockedUntil time.Time `bson:"locked_until"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
+
+// reusable indica si el registro ya no cuenta en now: venció o quedó una reserva abandonada.
+func (r *Record) reusable(now time.Time) bool {
+	return !now.Before(r.ExpiresAt) || (!r.Completed && !now.Before(r.LockedUntil))
+}
+
+// Store guarda los registros. Reserve debe ser atómico para que dos reintentos simultáneos no
+// ejecuten la petición dos veces.
+type Store interface {
+	// Reserve guarda rec como en curso si la clave está libre y devuelve nil; si no, devuelve el
+	// registro existente.
+	Reserve(ctx context.Context, rec *Record, now time.Time) (*Record, error)
+	// Complete guarda la respuesta de la reserva rec. Si otra petición tomó la clave (la reserva
+	// venció), devuelve ErrNotFound; las reservas se distinguen por CreatedAt.
+	Complete(ctx context.Context, rec *Record) error
+	// Release libera la reserva rec, por ejemplo tras un error del servidor, para poder reintentar.
+	Release(ctx context.Context, rec *Record) error
+}
+
+// MemoryStore guarda los registros en el proceso; sirve para una sola instancia y para pruebas.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]Record
+	lastSweep time.Time
+}
+
+const sweepEvery = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]Record{}}
+}
+
+func (s *MemoryStore) Reserve(_ context.Context, rec *Record, now time.Time) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	if existing, ok := s.records[rec.Key]; ok && !existing.reusable(now) {
+		return &existing, nil
+	}
+	s.records[rec.Key] = *rec
+	return nil, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, rec *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[rec.Key]; !ok || !existing.CreatedAt.Equal(rec.CreatedAt) {
+		return ErrNotFound
+	}
+	s.records[rec.Key] = *rec
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, rec *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[rec.Key]; ok && existing.CreatedAt.Equal(rec.CreatedAt) {
+		delete(s.records, rec.Key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepEvery {
+		return
+	}
+	s.lastSweep = now
+	for key, rec := range s.records {
+		if !now.Before(rec.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const redacted = "[REDACTED]"
+
+// DefaultSensitiveHeaders son las cabeceras con credenciales, que nunca se escriben en los logs.
+var DefaultSensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-API-Key", "Proxy-Authorization"}
+
+// DefaultSensitiveQuery son los parámetros con credenciales (WebSocket y SSE mandan el token en la URL).
+var DefaultSensitiveQuery = []string{"access_token", "token"}
+
+// Redactor oculta los valores de las cabeceras y parámetros de query sensibles.
+type Redactor struct {
+	sensitive map[string]bool
+	query     map[string]bool
+}
+
+// NewRedactor oculta DefaultSensitiveHeaders más las cabeceras extra indicadas, y
+// DefaultSensitiveQuery en la query string.
+func NewRedactor(extra ...string) *Redactor {
+	r := &Redactor{sensitive: map[string]bool{}, query: map[string]bool{}}
+	for _, name := range append(append([]string{}, DefaultSensitiveHeaders...), extra...) {
+		if name = strings.TrimSpace(name); name != "" {
+			r.sensitive[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+	return r.WithQuery(DefaultSensitiveQuery...)
+}
+
+// WithQuery suma parámetros de query a ocultar; se comparan sin distinguir mayúsculas.
+func (r *Redactor) WithQuery(names ...string) *Redactor {
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			r.query[strings.ToLower(name)] = true
+		}
+	}
+	return r
+}
+
+// Headers devuelve las cabeceras como atributos, con los valores sensibles ocultos.
+func (r *Redactor) Headers(h http.Header) slog.Value {
+	attrs := make([]slog.Attr, 0, len(h))
+	for name, values := range h {
+		value := strings.Join(values, ", ")
+		if r.sensitive[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Query devuelve la query string con los parámetros de credenciales ocultos.
+func (r *Redactor) Query(u *url.URL) string {
+	if u.RawQuery == "" {
+		return ""
+	}
+	q := u.Query()
+	for name := range q {
+		if r.query[strings.ToLower(name)] {
+			q.Set(name, redacted)
+		}
+	}
+	return q.Encode()
+}
+
+// AccessLog registra cada petición al terminar, en reemplazo del logger de gin: warn para 4xx,
+// error para 5xx. Con nivel debug incluye las cabeceras, siempre pasadas por el Redactor.
+func AccessLog(logger *slog.Logger, redactor *Redactor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		ctx := c.Request.Context()
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "sin ruta"
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if q := redactor.Query(c.Request.URL); q != "" {
+			attrs = append(attrs, slog.String("query", q))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		if logger.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs, slog.Any("headers", redactor.Headers(c.Request.Header)))
+		}
+		logger.LogAttrs(ctx, level, "petición", attrs...)
+	}
+}
+
+// Recovery responde 500 ante un panic y lo registra con el ID de la petición, en reemplazo del
+// recovery de gin.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		logger.ErrorContext(c.Request.Context(), "panic atendiendo la petición",
+			slog.Any("panic", recovered), slog.String("path", c.Request.URL.Path), slog.String("stack", string(debug.Stack())))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+	})
+}
+
+// InternalError responde 500 con msg y registra la causa, que no se muestra al cliente, junto al
+// ID de la petición.
+func InternalError(c *gin.Context, msg string, err error) {
+	slog.ErrorContext(c.Request.Context(), msg, Err(err), slog.String("path", c.Request.URL.Path))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+}
@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(buf *bytes.Buffer, level slog.Level) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := New(buf, level, FormatJSON)
+	r := gin.New()
+	r.Use(RequestIDMiddleware(), AccessLog(logger, NewRedactor("X-Partner-Secret")), Recovery(logger))
+	r.GET("/products/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, RequestID(c.Request.Context()))
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		InternalError(c, "no se pudo", errors.New("mongo caído"))
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	return r
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	r := newRouter(&bytes.Buffer{}, slog.LevelInfo)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+	req.Header.Set(HeaderRequestID, "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "abc-123", w.Body.String())
+	assert.Equal(t, "abc-123", w.Header().Get(HeaderRequestID))
+
+	// Un ID con caracteres raros (p. ej. saltos de línea) se reemplaza.
+	req = httptest.NewRequest(http.MethodGet, "/products/1", nil)
+	req.Header.Set(HeaderRequestID, "x\ny")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Len(t, w.Body.String(), 32)
+	assert.Equal(t, w.Body.String(), w.Header().Get(HeaderRequestID))
+}
+
+func TestAccessLog_RedactsCredentials(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRouter(&buf, slog.LevelDebug)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1?access_token=secreto&fields=name", nil)
+	req.Header.Set("Authorization", "Bearer secreto")
+	req.Header.Set("X-API-Key", "mlk_secreto")
+	req.Header.Set("X-Partner-Secret", "secreto")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(HeaderRequestID, "req-9")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.NotContains(t, buf.String(), "secreto")
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	line := lines[0]
+	assert.Equal(t, "req-9", line["request_id"])
+	assert.Equal(t, "/products/:id", line["route"])
+	assert.Equal(t, float64(http.StatusOK), line["status"])
+	assert.Equal(t, "access_token=%5BREDACTED%5D&fields=name", line["query"])
+	headers := line["headers"].(map[string]any)
+	assert.Equal(t, redacted, headers["Authorization"])
+	assert.Equal(t, redacted, headers["X-Api-Key"])
+	assert.Equal(t, redacted, headers["X-Partner-Secret"])
+	assert.Equal(t, "application/json", headers["Accept"])
+}
+
+func TestRedactor_Query(t *testing.T) {
+	redactor := NewRedactor().WithQuery(" signature", "")
+
+	u, _ := url.Parse("/api/products/stock/ws?token=secreto&Signature=secreto&ACCESS_TOKEN=secreto&sku=A1")
+	q := redactor.Query(u)
+
+	assert.NotContains(t, q, "secreto")
+	assert.Equal(t, "ACCESS_TOKEN=%5BREDACTED%5D&Signature=%5BREDACTED%5D&sku=A1&token=%5BREDACTED%5D", q)
+	assert.Empty(t, redactor.Query(&url.URL{Path: "/api/products"}))
+}
+
+func TestAccessLog_ErrorsCarryRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRouter(&buf, slog.LevelInfo)
+	// InternalError escribe con el logger por defecto, como el resto del servicio.
+	previous := slog.Default()
+	slog.SetDefault(New(&buf, slog.LevelInfo, FormatJSON))
+	defer slog.SetDefault(previous)
+
+	for _, path := range []string{"/fail", "/panic"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(HeaderRequestID, "req-"+path[1:])
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	}
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 4)
+	assert.Equal(t, "mongo caído", lines[0]["error"])
+	assert.Equal(t, "req-fail", lines[0]["request_id"])
+	assert.Equal(t, "ERROR", lines[1]["level"])
+	assert.Equal(t, "req-fail", lines[1]["request_id"])
+	assert.Equal(t, "boom", lines[2]["panic"])
+	assert.Equal(t, "req-panic", lines[3]["request_id"])
+	// Sin nivel debug no se escriben las cabeceras.
+	assert.NotContains(t, lines[1], "headers")
+}
@@ -0,0 +1,76 @@
+// Package logging arma el logger estructurado (log/slog) del servicio: cada línea escrita con un
+// contexto lleva el ID de la petición y la tienda, así los errores de repositorios y procesos de
+// fondo se pueden seguir hasta la petición que los originó.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"mlsport/internal/tenant"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// ParseLevel acepta debug, info, warn o error (sin distinguir mayúsculas).
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return slog.LevelInfo, fmt.Errorf("nivel de log %q inválido: use debug, info, warn o error", s)
+	}
+	return level, nil
+}
+
+// New crea un logger en JSON (o texto, para leerlo en la terminal) que agrega el contexto de la
+// petición a cada línea.
+func New(w io.Writer, level slog.Leveler, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	if format == FormatText {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{h})
+}
+
+// contextHandler copia request_id, tenant y la traza activa del contexto a cada registro.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if id, ok := tenant.FromContext(ctx); ok {
+			r.AddAttrs(slog.String("tenant", id))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Err es el atributo estándar para un error.
+func Err(err error) slog.Attr {
+	return slog.Any("error", err)
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var line map[string]any
+		require.NoError(t, dec.Decode(&line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("DEBUG")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	level, err = ParseLevel("warn")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestLogger_AddsRequestContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo, FormatJSON).With("component", "repo")
+
+	ctx := tenant.WithID(WithRequestID(context.Background(), "req-1"), "tienda-a")
+	logger.ErrorContext(ctx, "Error closing cursor")
+	logger.Info("sin petición")
+	logger.DebugContext(ctx, "descartado por nivel")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "req-1", lines[0]["request_id"])
+	assert.Equal(t, "tienda-a", lines[0]["tenant"])
+	assert.Equal(t, "repo", lines[0]["component"])
+	assert.NotContains(t, lines[1], "request_id")
+	assert.NotContains(t, lines[1], "tenant")
+}
+
+func TestLogger_AddsTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo, FormatJSON)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9},
+		SpanID:     trace.SpanID{0x00, 0xf0},
+		TraceFlags: trace.FlagsSampled,
+	})
+	logger.InfoContext(trace.ContextWithSpanContext(context.Background(), sc), "con traza")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, sc.TraceID().String(), lines[0]["trace_id"])
+	assert.Equal(t, sc.SpanID().String(), lines[0]["span_id"])
+}
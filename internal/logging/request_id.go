@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderRequestID identifica la petición en los logs; se respeta el que manda el cliente o un
+// proxy y se devuelve en la respuesta.
+const HeaderRequestID = "X-Request-ID"
+
+// Los IDs recibidos van a los logs tal cual: solo se aceptan los cortos y sin caracteres raros.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID devuelve el ID de la petición del contexto, o "" fuera de una petición.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID genera un ID aleatorio de 32 caracteres hexadecimales.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestIDMiddleware toma X-Request-ID de la petición o genera uno, lo pone en el contexto y lo
+// devuelve en la respuesta. Va primero, para que todo lo demás lo registre.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if !validRequestID.MatchString(id) {
+			id = NewRequestID()
+		}
+		c.Header(HeaderRequestID, id)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package monitoring
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"mlsport/internal/logging"
+	"mlsport/internal/tenant"
+)
+
+// DefaultCatalogInterval es cada cuánto se actualizan los gauges del catálogo.
+const DefaultCatalogInterval = time.Minute
+
+// CatalogTotals devuelve la cantidad de productos y el stock total de una tienda.
+type CatalogTotals func(tenantID string) (products, stock int, err error)
+
+// RefreshCatalog actualiza los gauges del catálogo de cada tienda activa; las tiendas que dejaron
+// de estar activas se quitan.
+func (m *Metrics) RefreshCatalog(ctx context.Context, tenants tenant.Lister, totals CatalogTotals) error {
+	seen := map[string]bool{}
+	err := tenant.Each(ctx, tenants, func(ctx context.Context) error {
+		id := tenant.ID(ctx)
+		products, stock, err := totals(id)
+		if err != nil {
+			return err
+		}
+		seen[id] = true
+		m.catalogProds.WithLabelValues(id).Set(float64(products))
+		m.catalogStock.WithLabelValues(id).Set(float64(stock))
+		return nil
+	})
+
+	m.mu.Lock()
+	for id := range m.catalogTenants {
+		if !seen[id] && err == nil {
+			m.catalogProds.DeleteLabelValues(id)
+			m.catalogStock.DeleteLabelValues(id)
+			delete(m.catalogTenants, id)
+		}
+	}
+	for id := range seen {
+		m.catalogTenants[id] = true
+	}
+	m.mu.Unlock()
+	return err
+}
+
+// RunCatalog actualiza los gauges del catálogo cada interval hasta que se cancele ctx.
+func (m *Metrics) RunCatalog(ctx context.Context, interval time.Duration, tenants tenant.Lister, totals CatalogTotals) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.RefreshCatalog(ctx, tenants, totals); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Error actualizando las métricas del catálogo", logging.Err(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package monitoring expone las métricas operativas del servicio en formato Prometheus: peticiones
+// HTTP, operaciones de Mongo, widgets del dashboard y el tamaño del catálogo de cada tienda.
+package monitoring
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "mlsport"
+
+// Metrics agrupa los colectores en un registro propio (no el global de Prometheus), así los tests
+// pueden crear uno nuevo cada vez.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	mongoDuration  *prometheus.HistogramVec
+	mongoErrors    *prometheus.CounterVec
+	widgetDuration *prometheus.HistogramVec
+	catalogProds   *prometheus.GaugeVec
+	catalogStock   *prometheus.GaugeVec
+
+	mu             sync.Mutex
+	catalogTenants map[string]bool
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry:       prometheus.NewRegistry(),
+		catalogTenants: map[string]bool{},
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Peticiones HTTP atendidas, por método, ruta y estado.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latencia de las peticiones HTTP, por método, ruta y estado.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		mongoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "mongo_operation_duration_seconds",
+			Help:      "Latencia de las operaciones del repositorio de productos en Mongo, por método.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"method"}),
+		mongoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mongo_operation_errors_total",
+			Help:      "Operaciones del repositorio de productos que fallaron, por método.",
+		}, []string{"method"}),
+		widgetDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "dashboard_widget_duration_seconds",
+			Help:      "Tiempo de cada widget del dashboard, por widget y resultado (ok, error, timeout).",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"widget", "outcome"}),
+		catalogProds: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "catalog_products",
+			Help:      "Productos del catálogo, por tienda.",
+		}, []string{"tenant"}),
+		catalogStock: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "catalog_stock_units",
+			Help:      "Unidades en stock sumando todos los productos, por tienda.",
+		}, []string{"tenant"}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.mongoDuration, m.mongoErrors, m.widgetDuration,
+		m.catalogProds, m.catalogStock,
+	)
+	return m
+}
+
+// Handler sirve /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// Middleware mide cada petición. La ruta es la plantilla de gin (/api/products/:id), no la URL,
+// para que los IDs no creen series nuevas.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveMongo registra una operación del repositorio; failed indica un error de la base, no un
+// resultado vacío.
+func (m *Metrics) ObserveMongo(method string, elapsed time.Duration, failed bool) {
+	m.mongoDuration.WithLabelValues(method).Observe(elapsed.Seconds())
+	if failed {
+		m.mongoErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// ObserveWidget registra el tiempo de un widget del dashboard.
+func (m *Metrics) ObserveWidget(widget, outcome string, elapsed time.Duration) {
+	m.widgetDuration.WithLabelValues(widget, outcome).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,79 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenants []string
+
+func (t tenants) ActiveIDs() ([]string, error) { return t, nil }
+
+func TestMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/api/products/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+
+	for _, path := range []string{"/api/products/1", "/api/products/2", "/nada"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/products/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "unmatched", "404")))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `mlsport_http_request_duration_seconds_count{method="GET",route="/api/products/:id",status="200"} 2`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestObserveMongo_CountsErrors(t *testing.T) {
+	m := New()
+	m.ObserveMongo("FindAll", 10*time.Millisecond, false)
+	m.ObserveMongo("FindAll", 20*time.Millisecond, true)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.mongoErrors.WithLabelValues("FindAll")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.mongoDuration))
+}
+
+func TestRefreshCatalog_PerTenant(t *testing.T) {
+	m := New()
+	totals := map[string][2]int{"default": {3, 40}, "tienda-a": {1, 5}}
+	get := func(id string) (int, int, error) {
+		v, ok := totals[id]
+		if !ok {
+			return 0, 0, errors.New("sin contadores")
+		}
+		return v[0], v[1], nil
+	}
+
+	require.NoError(t, m.RefreshCatalog(context.Background(), tenants{"default", "tienda-a"}, get))
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.catalogProds.WithLabelValues("default")))
+	assert.Equal(t, 5.0, testutil.ToFloat64(m.catalogStock.WithLabelValues("tienda-a")))
+
+	// Una tienda deshabilitada deja de aparecer.
+	require.NoError(t, m.RefreshCatalog(context.Background(), tenants{"default"}, get))
+	expected := `
+# HELP mlsport_catalog_products Productos del catálogo, por tienda.
+# TYPE mlsport_catalog_products gauge
+mlsport_catalog_products{tenant="default"} 3
+`
+	assert.NoError(t, testutil.CollectAndCompare(m.catalogProds, strings.NewReader(expected)))
+
+	assert.Error(t, m.RefreshCatalog(context.Background(), tenants{"default", "tienda-z"}, get))
+}
@@ -0,0 +1,27 @@
+package delivery
+
+import (
+	"mlsport/internal/product/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CacheHandler struct {
+	Cache domain.CacheStatsProvider
+}
+
+func NewCacheHandler(cache domain.CacheStatsProvider) *CacheHandler {
+	return &CacheHandler{Cache: cache}
+}
+
+// GetStats godoc
+// @Summary Estadísticas de la caché
+// @Description Aciertos, fallos, consultas compartidas e invalidaciones de la caché de métricas y listados.
+// @Tags Productos
+// @Produce json
+// @Success 200 {object} domain.CacheStats
+// @Router /products/cache/stats [get]
+func (h *CacheHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Cache.Stats())
+}
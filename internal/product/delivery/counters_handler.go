@@ -0,0 +1,58 @@
+package delivery
+
+import (
+	"mlsport/internal/logging"
+	"mlsport/internal/product/usecase"
+	"mlsport/internal/tenant"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CountersHandler struct {
+	Service *usecase.CountersService
+}
+
+func NewCountersHandler(s *usecase.CountersService) *CountersHandler {
+	return &CountersHandler{Service: s}
+}
+
+// Get godoc
+// @Summary Contadores de métricas
+// @Description Modelo de lectura actualizado en cada escritura: totales, stock, suma de precios y productos por categoría.
+// @Tags Productos
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /products/metrics/counters [get]
+func (h *CountersHandler) Get(c *gin.Context) {
+	counters, err := h.Service.Get(tenant.ID(c.Request.Context()))
+	if err != nil {
+		logging.InternalError(c, "no se pudieron obtener los contadores", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total_products": counters.TotalProducts,
+		"total_stock":    counters.TotalStock,
+		"price_sum":      counters.PriceSum,
+		"average_price":  counters.AveragePrice(),
+		"categories":     counters.Categories,
+		"updated_at":     counters.UpdatedAt,
+	})
+}
+
+// Check godoc
+// @Summary Verificar contadores de métricas
+// @Description Compara los contadores guardados con la agregación en vivo y lista las diferencias.
+// @Tags Productos
+// @Produce json
+// @Success 200 {object} domain.CounterCheck
+// @Router /products/metrics/counters/check [get]
+func (h *CountersHandler) Check(c *gin.Context) {
+	check, err := h.Service.Check(tenant.ID(c.Request.Context()))
+	if err != nil {
+		logging.InternalError(c, "no se pudieron verificar los contadores", err)
+		return
+	}
+	c.JSON(http.StatusOK, check)
+}
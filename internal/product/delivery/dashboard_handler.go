@@ -0,0 +1,49 @@
+package delivery
+
+import (
+	"errors"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/product/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DashboardHandler struct {
+	Service *usecase.DashboardService
+}
+
+func NewDashboardHandler(s *usecase.DashboardService) *DashboardHandler {
+	return &DashboardHandler{Service: s}
+}
+
+// GetDashboard godoc
+// @Summary Dashboard de productos y métricas
+// @Description Consulta los widgets en paralelo, cada uno con su propio tiempo límite. Si un widget falla
+// @Description se devuelven los demás y el fallido trae su campo error (partial = true).
+// @Description Widgets: products, metrics, top_categories, low_stock, recent_changes.
+// @Tags Productos
+// @Produce json
+// @Param widgets query string false "Widgets separados por comas (por defecto todos)"
+// @Success 200 {object} domain.Dashboard
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} domain.Dashboard
+// @Router /products/dashboard [get]
+func (h *DashboardHandler) GetDashboard(c *gin.Context) {
+	dash, err := h.Service.Build(c.Request.Context(), queryList(c, "widgets"))
+	if errors.Is(err, domain.ErrUnknownWidget) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logging.InternalError(c, "no se pudo construir el dashboard", err)
+		return
+	}
+
+	if dash.Failed() {
+		c.JSON(http.StatusInternalServerError, dash)
+		return
+	}
+	c.JSON(http.StatusOK, dash)
+}
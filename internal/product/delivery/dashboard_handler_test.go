@@ -1,9 +1,14 @@
 package delivery
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"mlsport/internal/product/domain"
 	"mlsport/internal/product/usecase"
@@ -14,13 +19,22 @@ import (
 
 type mockDashboardRepo struct{}
 
-func (m *mockDashboardRepo) FindAll() ([]domain.Product, error) {
+func (m *mockDashboardRepo) FindAll(ctx context.Context) ([]domain.Product, error) {
 	return []domain.Product{
 		{ID: "1", Name: "Balón"},
 		{ID: "2", Name: "Guayos"},
 	}, nil
 }
-func (m *mockDashboardRepo) GetMetrics() (map[string]interface{}, error) {
+func (m *mockDashboardRepo) GetPriceDistribution(ctx context.Context, q domain.PriceQuery) (*domain.PriceDistribution, error) {
+	return nil, nil
+}
+func (m *mockDashboardRepo) FindLowStock(ctx context.Context, threshold int) ([]domain.Product, error) {
+	return nil, nil
+}
+func (m *mockDashboardRepo) FindRecent(ctx context.Context, limit int) ([]domain.Product, error) {
+	return nil, nil
+}
+func (m *mockDashboardRepo) GetMetrics(ctx context.Context, q domain.MetricsQuery) (map[string]interface{}, error) {
 	return map[string]interface{}{
 		"total_products": 2,
 		"total_stock":    100,
@@ -30,25 +44,45 @@ func (m *mockDashboardRepo) GetMetrics() (map[string]interface{}, error) {
 }
 
 // Implementa otros métodos vacíos para cumplir la interfaz:
-func (m *mockDashboardRepo) Create(p *domain.Product) error                       { return nil }
-func (m *mockDashboardRepo) FindByID(id string) (*domain.Product, error)          { return nil, nil }
-func (m *mockDashboardRepo) FindByCategory(cat string) ([]domain.Product, error)  { return nil, nil }
-func (m *mockDashboardRepo) Update(p *domain.Product) error                       { return nil }
-func (m *mockDashboardRepo) Patch(id string, fields map[string]interface{}) error { return nil }
-func (m *mockDashboardRepo) Delete(id string) error                               { return nil }
-func (m *mockDashboardRepo) GetCategories() ([]string, error)                     { return nil, nil }
+func (m *mockDashboardRepo) Create(ctx context.Context, p *domain.Product) error { return nil }
+func (m *mockDashboardRepo) Find(ctx context.Context, f domain.ProductFilter) ([]domain.Product, error) {
+	return nil, nil
+}
+func (m *mockDashboardRepo) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	return nil, nil
+}
+func (m *mockDashboardRepo) FindByCategory(ctx context.Context, cats ...string) ([]domain.Product, error) {
+	return nil, nil
+}
+func (m *mockDashboardRepo) Update(ctx context.Context, p *domain.Product) error { return nil }
+func (m *mockDashboardRepo) Patch(ctx context.Context, id string, fields map[string]interface{}) error {
+	return nil
+}
+func (m *mockDashboardRepo) Delete(ctx context.Context, id string) error         { return nil }
+func (m *mockDashboardRepo) GetCategories(ctx context.Context) ([]string, error) { return nil, nil }
 
-func newMockDashboardHandler() *ProductHandler {
-	repo := &mockDashboardRepo{}
-	svc := usecase.NewProductService(repo)
-	return NewProductHandler(svc)
+// failingDashboardRepo falla en métricas y tarda más que el plazo del widget en stock bajo.
+type failingDashboardRepo struct {
+	mockDashboardRepo
 }
 
-func TestGetDashboardSuccess(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	handler := newMockDashboardHandler()
+func (m *failingDashboardRepo) GetMetrics(ctx context.Context, q domain.MetricsQuery) (map[string]interface{}, error) {
+	return nil, errors.New("error simulado metrics")
+}
+
+func (m *failingDashboardRepo) FindLowStock(ctx context.Context, threshold int) ([]domain.Product, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
 
-	req, _ := http.NewRequest("GET", "/api/dashboard", nil)
+func newDashboardHandler(repo domain.ProductRepository) *DashboardHandler {
+	svc := usecase.NewDashboardService(usecase.NewProductService(repo))
+	svc.WidgetTimeout = 50 * time.Millisecond
+	return NewDashboardHandler(svc)
+}
+
+func getDashboard(handler *DashboardHandler, query string) (*httptest.ResponseRecorder, domain.Dashboard) {
+	req, _ := http.NewRequest("GET", "/api/products/dashboard"+query, nil)
 	resp := httptest.NewRecorder()
 
 	c, _ := gin.CreateTestContext(resp)
@@ -56,5 +90,81 @@ func TestGetDashboardSuccess(t *testing.T) {
 
 	handler.GetDashboard(c)
 
+	var dash domain.Dashboard
+	_ = json.Unmarshal(resp.Body.Bytes(), &dash)
+	return resp, dash
+}
+
+func TestGetDashboardSuccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resp, dash := getDashboard(newDashboardHandler(&mockDashboardRepo{}), "")
+
 	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.False(t, dash.Partial)
+	assert.Len(t, dash.Widgets, len(domain.DashboardWidgets))
+	assert.Len(t, dash.Widgets[domain.WidgetProducts].Data, 2)
+	assert.Equal(t, []interface{}{}, dash.Widgets[domain.WidgetLowStock].Data)
+}
+
+func TestGetDashboardSelectedWidgets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resp, dash := getDashboard(newDashboardHandler(&mockDashboardRepo{}), "?widgets=metrics,low_stock")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, dash.Widgets, 2)
+	assert.Contains(t, dash.Widgets, domain.WidgetMetrics)
+	assert.Contains(t, dash.Widgets, domain.WidgetLowStock)
+}
+
+func TestGetDashboardUnknownWidget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resp, _ := getDashboard(newDashboardHandler(&mockDashboardRepo{}), "?widgets=products,ventas")
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestGetDashboardPartialResults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resp, dash := getDashboard(newDashboardHandler(&failingDashboardRepo{}), "?widgets=products,metrics,low_stock")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, dash.Partial)
+	assert.Empty(t, dash.Widgets[domain.WidgetProducts].Error)
+	assert.Len(t, dash.Widgets[domain.WidgetProducts].Data, 2)
+	assert.Equal(t, "no se pudo obtener metrics", dash.Widgets[domain.WidgetMetrics].Error)
+	assert.Nil(t, dash.Widgets[domain.WidgetMetrics].Data)
+	assert.Equal(t, domain.ErrWidgetTimeout.Error(), dash.Widgets[domain.WidgetLowStock].Error)
+}
+
+func TestGetDashboardAllWidgetsFailed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resp, dash := getDashboard(newDashboardHandler(&failingDashboardRepo{}), "?widgets=metrics")
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.True(t, dash.Partial)
+}
+
+func TestGetDashboardReportsWidgetOutcomes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := newDashboardHandler(&failingDashboardRepo{})
+	var mu sync.Mutex
+	outcomes := map[string]string{}
+	handler.Service.OnWidget = func(name, outcome string, elapsed time.Duration) {
+		mu.Lock()
+		outcomes[name] = outcome
+		mu.Unlock()
+	}
+	getDashboard(handler, "?widgets=products,metrics,low_stock")
+
+	assert.Equal(t, map[string]string{
+		domain.WidgetProducts: "ok",
+		domain.WidgetMetrics:  "error",
+		domain.WidgetLowStock: "timeout",
+	}, outcomes)
 }
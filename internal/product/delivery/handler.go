@@ -1,9 +1,14 @@
 package delivery
 
 import (
+	"errors"
+	"mlsport/internal/auth"
+	"mlsport/internal/logging"
 	"mlsport/internal/product/domain"
 	"mlsport/internal/product/usecase"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,12 +26,21 @@ func NewProductHandler(s *usecase.ProductService) *ProductHandler {
 // @Description Devuelve una lista completa de productos registrados en el sistema.
 // @Tags Productos
 // @Produce json
+// @Param brand query []string false "Filtrar por marca (se admite una lista separada por comas)" collectionFormat(csv)
+// @Param category query []string false "Filtrar por categoría" collectionFormat(csv)
 // @Success 200 {array} domain.Product
+// @Failure 429 {object} map[string]string
 // @Router /products [get]
 func (h *ProductHandler) GetAll(c *gin.Context) {
-	products, err := h.Service.GetAll()
+	var products []domain.Product
+	var err error
+	if filter := parseFilter(c); filter.IsEmpty() {
+		products, err = h.Service.GetAll(c.Request.Context())
+	} else {
+		products, err = h.Service.Search(c.Request.Context(), filter)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error obteniendo productos"})
+		logging.InternalError(c, "error obteniendo productos", err)
 		return
 	}
 
@@ -52,7 +66,7 @@ func (h *ProductHandler) GetAll(c *gin.Context) {
 // @Router /products/{id} [get]
 func (h *ProductHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
-	product, err := h.Service.GetByID(id)
+	product, err := h.Service.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "producto no encontrado"})
 		return
@@ -67,8 +81,14 @@ func (h *ProductHandler) GetByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param producto body domain.Product true "Producto a registrar"
+// @Param Idempotency-Key header string false "Clave para reintentar sin duplicar el producto"
 // @Success 201 {object} domain.Product
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /products [post]
 func (h *ProductHandler) Create(c *gin.Context) {
 	var input domain.Product
@@ -76,9 +96,13 @@ func (h *ProductHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
 		return
 	}
-	err := h.Service.Create(&input)
+	err := h.Service.Create(c.Request.Context(), &input)
+	if auth.IsAuthError(err) {
+		auth.AbortWithError(c, err)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "no se pudo crear el producto"})
+		logging.InternalError(c, "no se pudo crear el producto", err)
 		return
 	}
 	c.JSON(http.StatusCreated, input)
@@ -94,6 +118,10 @@ func (h *ProductHandler) Create(c *gin.Context) {
 // @Param producto body domain.Product true "Datos actualizados del producto"
 // @Success 200 {object} domain.Product
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /products/{id} [put]
 func (h *ProductHandler) Update(c *gin.Context) {
 	var input domain.Product
@@ -102,9 +130,17 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		return
 	}
 	input.ID = c.Param("id")
-	err := h.Service.Update(&input)
+	err := h.Service.Update(c.Request.Context(), &input)
+	if auth.IsAuthError(err) {
+		auth.AbortWithError(c, err)
+		return
+	}
+	if errors.Is(err, domain.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "producto no encontrado"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "no se pudo actualizar"})
+		logging.InternalError(c, "no se pudo actualizar", err)
 		return
 	}
 	c.JSON(http.StatusOK, input)
@@ -118,7 +154,14 @@ func (h *ProductHandler) Update(c *gin.Context) {
 // @Produce json
 // @Param id path string true "ID del producto"
 // @Param fields body object true "Campos a modificar (por ejemplo: stock, price)"
+// @Param Idempotency-Key header string false "Clave para reintentar el ajuste sin aplicarlo dos veces"
 // @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /products/{id} [patch]
 func (h *ProductHandler) Patch(c *gin.Context) {
 	id := c.Param("id")
@@ -127,9 +170,17 @@ func (h *ProductHandler) Patch(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
 		return
 	}
-	err := h.Service.Patch(id, fields)
+	err := h.Service.Patch(c.Request.Context(), id, fields)
+	if auth.IsAuthError(err) {
+		auth.AbortWithError(c, err)
+		return
+	}
+	if errors.Is(err, domain.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "producto no encontrado"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "no se pudo aplicar el patch"})
+		logging.InternalError(c, "no se pudo aplicar el patch", err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "actualizado"})
@@ -141,12 +192,24 @@ func (h *ProductHandler) Patch(c *gin.Context) {
 // @Tags Productos
 // @Param id path string true "ID del producto"
 // @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /products/{id} [delete]
 func (h *ProductHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
-	err := h.Service.Delete(id)
+	err := h.Service.Delete(c.Request.Context(), id)
+	if auth.IsAuthError(err) {
+		auth.AbortWithError(c, err)
+		return
+	}
+	if errors.Is(err, domain.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "producto no encontrado"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "no se pudo eliminar"})
+		logging.InternalError(c, "no se pudo eliminar", err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "eliminado"})
@@ -158,13 +221,20 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 // @Tags Productos
 // @Produce json
 // @Param category path string true "Nombre de la categoría"
+// @Param descendants query bool false "Incluir productos de las subcategorías"
 // @Success 200 {array} domain.Product
 // @Router /products/categories/{category} [get]
 func (h *ProductHandler) GetByCategory(c *gin.Context) {
 	cat := c.Param("category")
-	products, err := h.Service.GetByCategory(cat)
+	var products []domain.Product
+	var err error
+	if c.Query("descendants") == "true" {
+		products, err = h.Service.GetByCategoryTree(c.Request.Context(), cat)
+	} else {
+		products, err = h.Service.GetByCategory(c.Request.Context(), cat)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "no se pudieron filtrar los productos"})
+		logging.InternalError(c, "no se pudieron filtrar los productos", err)
 		return
 	}
 	c.JSON(http.StatusOK, products)
@@ -178,9 +248,9 @@ func (h *ProductHandler) GetByCategory(c *gin.Context) {
 // @Success 200 {array} string
 // @Router /products/categories [get]
 func (h *ProductHandler) GetCategories(c *gin.Context) {
-	list, err := h.Service.GetCategories()
+	list, err := h.Service.GetCategories(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "no se pudieron obtener las categorías"})
+		logging.InternalError(c, "no se pudieron obtener las categorías", err)
 		return
 	}
 
@@ -194,73 +264,127 @@ func (h *ProductHandler) GetCategories(c *gin.Context) {
 
 // GetMetrics godoc
 // @Summary Métricas de productos
-// @Description Devuelve métricas agregadas como total de productos, promedio de precios y stock acumulado.
+// @Description Devuelve métricas agregadas como total de productos, promedio de precios y stock acumulado,
+// @Description junto con el desglose por categoría y por marca (cantidad, stock, valor del stock y precios).
 // @Tags Productos
 // @Produce json
+// @Param top query int false "Cantidad de categorías y marcas en el desglose (por defecto 5)"
+// @Param brand query []string false "Filtrar por marca" collectionFormat(csv)
+// @Param category query []string false "Filtrar por categoría" collectionFormat(csv)
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
 // @Router /products/metrics [get]
 func (h *ProductHandler) GetMetrics(c *gin.Context) {
-	data, err := h.Service.GetMetrics()
+	query := domain.MetricsQuery{Filter: parseFilter(c)}
+	if raw := c.Query("top"); raw != "" {
+		top, err := strconv.Atoi(raw)
+		if err != nil || top <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "el parámetro top debe ser un entero positivo"})
+			return
+		}
+		query.TopN = top
+	}
+
+	data, err := h.Service.QueryMetrics(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "no se pudieron calcular las métricas"})
+		logging.InternalError(c, "no se pudieron calcular las métricas", err)
 		return
 	}
 	c.JSON(http.StatusOK, data)
 }
 
-// GetDashboard godoc
-// @Summary Dashboard de productos y métricas
-// @Description Consulta combinada que obtiene productos y métricas en paralelo.
+// GetPriceDistribution godoc
+// @Summary Distribución de precios
+// @Description Devuelve mediana, percentiles 25/75/90 e histograma de precios. Con boundaries se usan
+// @Description límites fijos; si no, los precios se reparten en buckets grupos automáticos.
 // @Tags Productos
 // @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /products/dashboard [get]
-func (h *ProductHandler) GetDashboard(c *gin.Context) {
-	type result struct {
-		Products []domain.Product
-		Metrics  map[string]interface{}
-		Err      error
-	}
-
-	productCh := make(chan []domain.Product)
-	metricCh := make(chan map[string]interface{})
-	errorCh := make(chan error, 2)
+// @Param boundaries query []number false "Límites del histograma en orden ascendente, p. ej. 0,50,100,200" collectionFormat(csv)
+// @Param buckets query int false "Cantidad de grupos automáticos (por defecto 10)"
+// @Param brand query []string false "Filtrar por marca" collectionFormat(csv)
+// @Param category query []string false "Filtrar por categoría" collectionFormat(csv)
+// @Success 200 {object} domain.PriceDistribution
+// @Failure 400 {object} map[string]string
+// @Router /products/metrics/prices [get]
+func (h *ProductHandler) GetPriceDistribution(c *gin.Context) {
+	query := domain.PriceQuery{Filter: parseFilter(c)}
 
-	go func() {
-		list, err := h.Service.GetAll()
+	for _, raw := range queryList(c, "boundaries") {
+		v, err := strconv.ParseFloat(raw, 64)
 		if err != nil {
-			errorCh <- err
+			c.JSON(http.StatusBadRequest, gin.H{"error": "límites del histograma inválidos"})
 			return
 		}
-		productCh <- list
-	}()
-
-	go func() {
-		metrics, err := h.Service.GetMetrics()
-		if err != nil {
-			errorCh <- err
+		query.Boundaries = append(query.Boundaries, v)
+	}
+	if raw := c.Query("buckets"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "el parámetro buckets debe ser un entero positivo"})
 			return
 		}
-		metricCh <- metrics
-	}()
+		query.Buckets = n
+	}
 
-	var res result
+	data, err := h.Service.GetPriceDistribution(c.Request.Context(), query)
+	if errors.Is(err, domain.ErrInvalidPriceBuckets) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logging.InternalError(c, "no se pudo calcular la distribución de precios", err)
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
 
-	for i := 0; i < 2; i++ {
-		select {
-		case products := <-productCh:
-			res.Products = products
-		case metrics := <-metricCh:
-			res.Metrics = metrics
-		case err := <-errorCh:
-			res.Err = err
+// GetLowStock godoc
+// @Summary Productos con stock bajo
+// @Description Lista los productos cuyo stock no supera su umbral de reposición (o el umbral general).
+// @Tags Productos
+// @Produce json
+// @Param threshold query int false "Umbral general a usar para productos sin umbral propio"
+// @Success 200 {array} domain.Product
+// @Failure 400 {object} map[string]string
+// @Router /products/low-stock [get]
+func (h *ProductHandler) GetLowStock(c *gin.Context) {
+	threshold := 0
+	if raw := c.Query("threshold"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "el parámetro threshold debe ser un entero positivo"})
+			return
 		}
+		threshold = n
 	}
 
-	if res.Err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": res.Err.Error()})
+	products, err := h.Service.GetLowStock(c.Request.Context(), threshold)
+	if err != nil {
+		logging.InternalError(c, "no se pudo obtener el reporte de stock bajo", err)
 		return
 	}
+	if products == nil {
+		products = []domain.Product{}
+	}
+	c.JSON(http.StatusOK, products)
+}
 
-	c.JSON(http.StatusOK, res)
+// parseFilter lee ?brand= y ?category=, repetidos o separados por comas.
+func parseFilter(c *gin.Context) domain.ProductFilter {
+	return domain.ProductFilter{
+		Categories: queryList(c, "category"),
+		Brands:     queryList(c, "brand"),
+	}
+}
+
+func queryList(c *gin.Context, key string) []string {
+	var out []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
 }
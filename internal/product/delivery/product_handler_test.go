@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"mlsport/internal/product/domain"
@@ -16,23 +17,39 @@ import (
 
 type mockRepo struct{}
 
-func (m *mockRepo) Create(p *domain.Product) error { return nil }
-func (m *mockRepo) FindAll() ([]domain.Product, error) {
+func (m *mockRepo) Create(ctx context.Context, p *domain.Product) error { return nil }
+func (m *mockRepo) FindAll(ctx context.Context) ([]domain.Product, error) {
 	return []domain.Product{{ID: "1", Name: "Balón"}}, nil
 }
-func (m *mockRepo) FindByID(id string) (*domain.Product, error) {
+func (m *mockRepo) Find(ctx context.Context, f domain.ProductFilter) ([]domain.Product, error) {
+	return []domain.Product{{ID: "2", Name: "Guayos", Brand: f.Brands[0]}}, nil
+}
+func (m *mockRepo) FindByID(ctx context.Context, id string) (*domain.Product, error) {
 	return &domain.Product{ID: id, Name: "Balón"}, nil
 }
-func (m *mockRepo) FindByCategory(cat string) ([]domain.Product, error) {
-	return []domain.Product{{Category: cat}}, nil
+func (m *mockRepo) FindByCategory(ctx context.Context, cats ...string) ([]domain.Product, error) {
+	return []domain.Product{{Category: cats[0]}}, nil
+}
+func (m *mockRepo) Update(ctx context.Context, p *domain.Product) error { return nil }
+func (m *mockRepo) Patch(ctx context.Context, id string, fields map[string]interface{}) error {
+	return nil
+}
+func (m *mockRepo) Delete(ctx context.Context, id string) error { return nil }
+func (m *mockRepo) GetPriceDistribution(ctx context.Context, q domain.PriceQuery) (*domain.PriceDistribution, error) {
+	return &domain.PriceDistribution{Count: len(q.Boundaries), Histogram: []domain.PriceBucket{}}, nil
+}
+func (m *mockRepo) FindLowStock(ctx context.Context, threshold int) ([]domain.Product, error) {
+	return []domain.Product{{ID: "1", Name: "Balón", Stock: threshold}}, nil
 }
-func (m *mockRepo) Update(p *domain.Product) error                       { return nil }
-func (m *mockRepo) Patch(id string, fields map[string]interface{}) error { return nil }
-func (m *mockRepo) Delete(id string) error                               { return nil }
-func (m *mockRepo) GetMetrics() (map[string]interface{}, error) {
-	return map[string]interface{}{"total_products": 1}, nil
+func (m *mockRepo) FindRecent(ctx context.Context, limit int) ([]domain.Product, error) {
+	return nil, nil
+}
+func (m *mockRepo) GetMetrics(ctx context.Context, q domain.MetricsQuery) (map[string]interface{}, error) {
+	return map[string]interface{}{"total_products": 1, "top": q.TopN, "brands": q.Filter.Brands}, nil
+}
+func (m *mockRepo) GetCategories(ctx context.Context) ([]string, error) {
+	return []string{"Ropa", "Calzado"}, nil
 }
-func (m *mockRepo) GetCategories() ([]string, error) { return []string{"Ropa", "Calzado"}, nil }
 
 func newMockHandler() *ProductHandler {
 	repo := &mockRepo{}
@@ -82,17 +99,35 @@ func TestGetByIDHandler_NotFound(t *testing.T) {
 
 type notFoundMockRepo struct{}
 
-func (m *notFoundMockRepo) Create(p *domain.Product) error     { return nil }
-func (m *notFoundMockRepo) FindAll() ([]domain.Product, error) { return nil, nil }
-func (m *notFoundMockRepo) FindByID(id string) (*domain.Product, error) {
+func (m *notFoundMockRepo) Create(ctx context.Context, p *domain.Product) error   { return nil }
+func (m *notFoundMockRepo) FindAll(ctx context.Context) ([]domain.Product, error) { return nil, nil }
+func (m *notFoundMockRepo) Find(ctx context.Context, f domain.ProductFilter) ([]domain.Product, error) {
+	return nil, nil
+}
+func (m *notFoundMockRepo) FindByID(ctx context.Context, id string) (*domain.Product, error) {
 	return nil, errors.New("producto no encontrado")
 }
-func (m *notFoundMockRepo) FindByCategory(cat string) ([]domain.Product, error)  { return nil, nil }
-func (m *notFoundMockRepo) Update(p *domain.Product) error                       { return nil }
-func (m *notFoundMockRepo) Patch(id string, fields map[string]interface{}) error { return nil }
-func (m *notFoundMockRepo) Delete(id string) error                               { return nil }
-func (m *notFoundMockRepo) GetMetrics() (map[string]interface{}, error)          { return nil, nil }
-func (m *notFoundMockRepo) GetCategories() ([]string, error)                     { return nil, nil }
+func (m *notFoundMockRepo) FindByCategory(ctx context.Context, cats ...string) ([]domain.Product, error) {
+	return nil, nil
+}
+func (m *notFoundMockRepo) Update(ctx context.Context, p *domain.Product) error { return nil }
+func (m *notFoundMockRepo) Patch(ctx context.Context, id string, fields map[string]interface{}) error {
+	return nil
+}
+func (m *notFoundMockRepo) Delete(ctx context.Context, id string) error { return nil }
+func (m *notFoundMockRepo) GetPriceDistribution(ctx context.Context, q domain.PriceQuery) (*domain.PriceDistribution, error) {
+	return nil, nil
+}
+func (m *notFoundMockRepo) FindLowStock(ctx context.Context, threshold int) ([]domain.Product, error) {
+	return nil, nil
+}
+func (m *notFoundMockRepo) FindRecent(ctx context.Context, limit int) ([]domain.Product, error) {
+	return nil, nil
+}
+func (m *notFoundMockRepo) GetMetrics(ctx context.Context, q domain.MetricsQuery) (map[string]interface{}, error) {
+	return nil, nil
+}
+func (m *notFoundMockRepo) GetCategories(ctx context.Context) ([]string, error) { return nil, nil }
 
 func TestCreateHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -111,3 +146,81 @@ func TestCreateHandler(t *testing.T) {
 
 	assert.Equal(t, http.StatusCreated, resp.Code)
 }
+
+func TestGetAllHandler_BrandFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newMockHandler()
+
+	req, _ := http.NewRequest("GET", "/api/products?brand=Nike,Adidas", nil)
+	resp := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(resp)
+	c.Request = req
+
+	handler.GetAll(c)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var products []domain.Product
+	err := json.Unmarshal(resp.Body.Bytes(), &products)
+	assert.NoError(t, err)
+	assert.Len(t, products, 1)
+	assert.Equal(t, "Nike", products[0].Brand)
+}
+
+func TestGetMetricsHandler_TopAndFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newMockHandler()
+
+	req, _ := http.NewRequest("GET", "/api/products/metrics?top=3&brand=Nike", nil)
+	resp := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(resp)
+	c.Request = req
+
+	handler.GetMetrics(c)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var data map[string]interface{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &data))
+	assert.Equal(t, float64(3), data["top"])
+	assert.Equal(t, []interface{}{"Nike"}, data["brands"])
+}
+
+func TestGetMetricsHandler_InvalidTop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newMockHandler()
+
+	req, _ := http.NewRequest("GET", "/api/products/metrics?top=abc", nil)
+	resp := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(resp)
+	c.Request = req
+
+	handler.GetMetrics(c)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestGetPriceDistributionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newMockHandler()
+
+	for query, status := range map[string]int{
+		"boundaries=0,50,100": http.StatusOK,
+		"boundaries=100,50":   http.StatusBadRequest,
+		"boundaries=abc":      http.StatusBadRequest,
+		"buckets=0":           http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", "/api/products/metrics/prices?"+query, nil)
+		resp := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(resp)
+		c.Request = req
+
+		handler.GetPriceDistribution(c)
+
+		assert.Equal(t, status, resp.Code, query)
+	}
+}
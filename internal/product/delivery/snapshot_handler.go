@@ -0,0 +1,92 @@
+package delivery
+
+import (
+	"errors"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/product/usecase"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const dateLayout = "2006-01-02"
+
+type SnapshotHandler struct {
+	Service *usecase.SnapshotService
+}
+
+func NewSnapshotHandler(s *usecase.SnapshotService) *SnapshotHandler {
+	return &SnapshotHandler{Service: s}
+}
+
+// GetHistory godoc
+// @Summary Histórico de métricas
+// @Description Serie temporal de los snapshots diarios de métricas (cierre de cada periodo).
+// @Description Con compare_from y compare_to agrega la diferencia entre el cierre de ambos periodos.
+// @Tags Productos
+// @Produce json
+// @Param from query string false "Fecha inicial YYYY-MM-DD (por defecto hace 30 días)"
+// @Param to query string false "Fecha final YYYY-MM-DD (por defecto hoy)"
+// @Param granularity query string false "day, week o month (por defecto day)"
+// @Param compare_from query string false "Inicio del periodo a comparar YYYY-MM-DD"
+// @Param compare_to query string false "Fin del periodo a comparar YYYY-MM-DD"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /products/metrics/history [get]
+func (h *SnapshotHandler) GetHistory(c *gin.Context) {
+	to, err := parseDate(c.Query("to"), time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fecha to inválida, use YYYY-MM-DD"})
+		return
+	}
+	from, err := parseDate(c.Query("from"), to.AddDate(0, 0, -30))
+	if err != nil || from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fecha from inválida, use YYYY-MM-DD y anterior a to"})
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", domain.GranularityDay)
+	series, err := h.Service.History(c.Request.Context(), from, to, granularity)
+	if errors.Is(err, domain.ErrInvalidGranularity) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logging.InternalError(c, "no se pudo obtener el histórico de métricas", err)
+		return
+	}
+
+	res := gin.H{
+		"from":        from.Format(dateLayout),
+		"to":          to.Format(dateLayout),
+		"granularity": granularity,
+		"series":      series,
+	}
+
+	if c.Query("compare_from") != "" || c.Query("compare_to") != "" {
+		prevFrom, errFrom := parseDate(c.Query("compare_from"), time.Time{})
+		prevTo, errTo := parseDate(c.Query("compare_to"), time.Time{})
+		if errFrom != nil || errTo != nil || prevFrom.IsZero() || prevTo.IsZero() || prevFrom.After(prevTo) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "compare_from y compare_to deben ser fechas YYYY-MM-DD válidas"})
+			return
+		}
+
+		cmp, err := h.Service.Compare(c.Request.Context(), from, to, prevFrom, prevTo)
+		if err != nil {
+			logging.InternalError(c, "no se pudo comparar los periodos", err)
+			return
+		}
+		res["comparison"] = cmp
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func parseDate(raw string, fallback time.Time) (time.Time, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	return time.Parse(dateLayout, raw)
+}
@@ -0,0 +1,212 @@
+package delivery
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/product/usecase"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = wsPongWait * 9 / 10
+	wsMaxMessageSize = 64 * 1024
+)
+
+// StockSocketHandler atiende el WebSocket de cambios de stock de las terminales.
+//
+// Mensajes del cliente:
+//
+//	{"action":"subscribe","products":["<id>"],"categories":["calzado"],"since":120}
+//	{"action":"unsubscribe","products":["<id>"]}
+//
+// Mensajes del servidor: "subscribed" (con seq actual y, si no se pudo retomar desde since, resync=true),
+// "stock" (un domain.StockEvent) y "error".
+type StockSocketHandler struct {
+	Feed *usecase.StockFeed
+	// Authorize decide si la petición puede abrir el socket.
+	Authorize func(r *http.Request) bool
+	Upgrader  websocket.Upgrader
+}
+
+func NewStockSocketHandler(feed *usecase.StockFeed, authorize func(r *http.Request) bool) *StockSocketHandler {
+	return &StockSocketHandler{Feed: feed, Authorize: authorize}
+}
+
+type stockClientMessage struct {
+	Action     string   `json:"action"`
+	Products   []string `json:"products"`
+	Categories []string `json:"categories"`
+	Since      *uint64  `json:"since"`
+}
+
+type stockServerMessage struct {
+	Type       string             `json:"type"`
+	Seq        uint64             `json:"seq,omitempty"`
+	Resync     bool               `json:"resync,omitempty"`
+	Products   []string           `json:"products,omitempty"`
+	Categories []string           `json:"categories,omitempty"`
+	Event      *domain.StockEvent `json:"event,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// Serve godoc
+// @Summary Cambios de stock en tiempo real (WebSocket)
+// @Description Canal WebSocket para suscribirse a productos o categorías y recibir cambios de stock y precio.
+// @Description Requiere token (Authorization: Bearer o ?token=). Cada evento trae seq; al reconectar se envía
+// @Description "since" con el último recibido para recuperar lo perdido.
+// @Tags Productos
+// @Param token query string false "Token de acceso"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 401 {object} map[string]string
+// @Router /products/stock/ws [get]
+func (h *StockSocketHandler) Serve(c *gin.Context) {
+	if h.Authorize == nil || !h.Authorize(c.Request) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token inválido"})
+		return
+	}
+
+	conn, err := h.Upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade ya respondió al cliente.
+		return
+	}
+	defer conn.Close()
+
+	client := h.Feed.Connect(c.Request.Context())
+	defer h.Feed.Disconnect(client)
+
+	in := make(chan stockClientMessage)
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go h.read(c.Request.Context(), conn, in, done, stop)
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	// Un solo goroutine escribe y atiende las suscripciones, así lo reenviado al retomar sale antes
+	// que los eventos nuevos; lastSeq descarta los que ya se enviaron en ese reenvío.
+	var lastSeq uint64
+	write := func(v interface{}) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		return conn.WriteJSON(v) == nil
+	}
+	send := func(ev domain.StockEvent) bool {
+		if ev.Seq <= lastSeq {
+			return true
+		}
+		lastSeq = ev.Seq
+		return write(stockServerMessage{Type: "stock", Seq: ev.Seq, Event: &ev})
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-client.Dropped:
+			// Se quedó atrás: que reconecte con since.
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "cliente demasiado lento"),
+				time.Now().Add(wsWriteWait))
+			return
+		case msg := <-in:
+			reply, replay := h.handle(client, msg)
+			if !write(reply) {
+				return
+			}
+			for _, ev := range replay {
+				if !send(ev) {
+					return
+				}
+			}
+		case ev := <-client.Events:
+			if !send(ev) {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// read decodifica los mensajes del cliente y los pasa a Serve.
+func (h *StockSocketHandler) read(ctx context.Context, conn *websocket.Conn, in chan<- stockClientMessage, done chan<- struct{}, stop <-chan struct{}) {
+	defer close(done)
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var msg stockClientMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				slog.WarnContext(ctx, "WebSocket de stock cerrado inesperadamente", logging.Err(err))
+			}
+			return
+		}
+		select {
+		case in <- msg:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (h *StockSocketHandler) handle(client *usecase.StockClient, msg stockClientMessage) (stockServerMessage, []domain.StockEvent) {
+	switch msg.Action {
+	case "subscribe":
+		var since uint64
+		if msg.Since != nil {
+			since = *msg.Since
+		}
+		replay, complete, seq, err := h.Feed.Subscribe(client, msg.Products, msg.Categories, since, msg.Since != nil)
+		if err != nil {
+			return stockServerMessage{Type: "error", Error: err.Error()}, nil
+		}
+		products, categories := h.Feed.Subscriptions(client)
+		return stockServerMessage{Type: "subscribed", Seq: seq, Resync: !complete, Products: products, Categories: categories}, replay
+
+	case "unsubscribe":
+		h.Feed.Unsubscribe(client, msg.Products, msg.Categories)
+		products, categories := h.Feed.Subscriptions(client)
+		return stockServerMessage{Type: "subscribed", Products: products, Categories: categories}, nil
+
+	default:
+		return stockServerMessage{Type: "error", Error: "acción desconocida: use subscribe o unsubscribe"}, nil
+	}
+}
+
+// TokenAuthorizer acepta las peticiones con alguno de los tokens, en Authorization: Bearer o ?token=.
+// Sin tokens configurados no acepta ninguna.
+func TokenAuthorizer(tokens []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		got := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			got = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if got == "" {
+			return false
+		}
+		for _, t := range tokens {
+			if subtle.ConstantTimeCompare([]byte(got), []byte(t)) == 1 {
+				return true
+			}
+		}
+		return false
+	}
+}
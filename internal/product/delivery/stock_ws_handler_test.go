@@ -0,0 +1,103 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mlsport/internal/product/domain"
+	"mlsport/internal/product/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStockSocketServer(t *testing.T) (string, *usecase.StockFeed) {
+	gin.SetMode(gin.TestMode)
+	feed := usecase.NewStockFeed()
+	handler := NewStockSocketHandler(feed, TokenAuthorizer([]string{"secreto"}))
+
+	r := gin.New()
+	r.GET("/ws", handler.Serve)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws", feed
+}
+
+func dialStock(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token=secreto", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	return conn
+}
+
+func TestStockSocketRequiresToken(t *testing.T) {
+	url, _ := newStockSocketServer(t)
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?token=otro", nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestStockSocketSubscribeAndReceive(t *testing.T) {
+	url, feed := newStockSocketServer(t)
+	conn := dialStock(t, url)
+
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"action": "subscribe", "categories": []string{"Calzado"}}))
+	var ack stockServerMessage
+	assert.NoError(t, conn.ReadJSON(&ack))
+	assert.Equal(t, "subscribed", ack.Type)
+	assert.Equal(t, []string{"calzado"}, ack.Categories)
+
+	feed.Publish(context.Background(), domain.ProductChange{Type: domain.ChangeCreated, ProductID: "p1",
+		Product: &domain.Product{ID: "p1", Category: "calzado", Stock: 7, Price: 90}})
+
+	var msg stockServerMessage
+	assert.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "stock", msg.Type)
+	assert.Equal(t, uint64(1), msg.Seq)
+	assert.Equal(t, 7, msg.Event.Stock)
+	assert.Nil(t, msg.Event.PreviousStock)
+}
+
+func TestStockSocketResumeAfterReconnect(t *testing.T) {
+	url, feed := newStockSocketServer(t)
+	for i := 1; i <= 3; i++ {
+		feed.Publish(context.Background(), domain.ProductChange{Type: domain.ChangeUpdated, ProductID: "p1",
+			Previous: &domain.Product{Stock: i}, Product: &domain.Product{Stock: i + 1}})
+	}
+
+	conn := dialStock(t, url)
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"action": "subscribe", "products": []string{"p1"}, "since": 1}))
+
+	var ack stockServerMessage
+	assert.NoError(t, conn.ReadJSON(&ack))
+	assert.Equal(t, uint64(3), ack.Seq)
+	assert.False(t, ack.Resync)
+
+	for _, want := range []uint64{2, 3} {
+		var msg stockServerMessage
+		assert.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, want, msg.Seq)
+	}
+}
+
+func TestStockSocketSubscriptionLimit(t *testing.T) {
+	url, feed := newStockSocketServer(t)
+	feed.MaxSubscriptions = 1
+	conn := dialStock(t, url)
+
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"action": "subscribe", "products": []string{"p1", "p2"}}))
+	var msg stockServerMessage
+	assert.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "error", msg.Type)
+	assert.Equal(t, domain.ErrTooManySubscriptions.Error(), msg.Error)
+}
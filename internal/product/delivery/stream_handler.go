@@ -0,0 +1,143 @@
+package delivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/product/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultHeartbeatInterval = 15 * time.Second
+	streamWriteTimeout       = 10 * time.Second
+	streamRetryMS            = 3000
+)
+
+type StreamHandler struct {
+	Stream    *usecase.DashboardStream
+	Heartbeat time.Duration
+}
+
+func NewStreamHandler(s *usecase.DashboardStream) *StreamHandler {
+	return &StreamHandler{Stream: s, Heartbeat: DefaultHeartbeatInterval}
+}
+
+// GetStream godoc
+// @Summary Dashboard en vivo (Server-Sent Events)
+// @Description Emite un evento "product" por cada alta, modificación o baja y un evento "metrics" con las
+// @Description métricas recalculadas. Al conectarse sin Last-Event-ID se recibe primero el estado actual.
+// @Description Con Last-Event-ID (o ?last_event_id=) se reenvían los eventos perdidos si siguen en el historial.
+// @Description Envía un comentario de heartbeat periódico; un cliente demasiado lento se desconecta y debe reconectarse.
+// @Tags Productos
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "Último evento recibido"
+// @Param last_event_id query string false "Último evento recibido (alternativa al header)"
+// @Success 200 {string} string "stream de eventos"
+// @Router /products/dashboard/stream [get]
+func (h *StreamHandler) GetStream(c *gin.Context) {
+	ctx := c.Request.Context()
+	lastID, resume := lastEventID(c)
+	sub, replay, complete := h.Stream.Subscribe(ctx, lastID, resume)
+	defer h.Stream.Unsubscribe(sub)
+
+	rc := http.NewResponseController(c.Writer)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Un cliente que no lee no debe dejar la escritura bloqueada para siempre.
+	write := func(fn func(w io.Writer) error) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err := fn(c.Writer); err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+
+	if !write(func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetryMS)
+		return err
+	}) {
+		return
+	}
+
+	if !complete {
+		snap, err := h.Stream.Snapshot(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error obteniendo métricas para el stream", logging.Err(err))
+		} else {
+			replay = append([]domain.StreamEvent{snap}, replay...)
+		}
+	}
+	for _, ev := range replay {
+		if !write(eventWriter(ev)) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Dropped:
+			// Se quedó atrás: al reconectar con Last-Event-ID retoma desde el historial.
+			write(func(w io.Writer) error {
+				_, err := io.WriteString(w, ": cliente demasiado lento, reconectar\n\n")
+				return err
+			})
+			return
+		case ev := <-sub.Events:
+			if !write(eventWriter(ev)) {
+				return
+			}
+		case <-heartbeat.C:
+			if !write(func(w io.Writer) error {
+				_, err := io.WriteString(w, ": heartbeat\n\n")
+				return err
+			}) {
+				return
+			}
+		}
+	}
+}
+
+func eventWriter(ev domain.StreamEvent) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		data, err := json.Marshal(ev.Data)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Name, data)
+		return err
+	}
+}
+
+func lastEventID(c *gin.Context) (uint64, bool) {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
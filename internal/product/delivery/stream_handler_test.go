@@ -0,0 +1,120 @@
+package delivery
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mlsport/internal/product/domain"
+	"mlsport/internal/product/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type sseEvent struct {
+	id, name, data string
+}
+
+// readEvents lee n eventos del stream, ignorando comentarios y la línea retry.
+func readEvents(t *testing.T, r *bufio.Reader, n int) []sseEvent {
+	t.Helper()
+	var out []sseEvent
+	var ev sseEvent
+	for len(out) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream cerrado: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			if ev.name != "" {
+				out = append(out, ev)
+			}
+			ev = sseEvent{}
+		case strings.HasPrefix(line, "id: "):
+			ev.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			ev.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			ev.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	return out
+}
+
+func newStreamServer(t *testing.T) (*httptest.Server, *usecase.DashboardStream) {
+	gin.SetMode(gin.TestMode)
+	stream := usecase.NewDashboardStream(usecase.NewProductService(&mockRepo{}))
+	handler := NewStreamHandler(stream)
+	handler.Heartbeat = 10 * time.Millisecond
+
+	r := gin.New()
+	r.GET("/stream", handler.GetStream)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv, stream
+}
+
+func connect(t *testing.T, url, lastID string) *bufio.Reader {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	return bufio.NewReader(resp.Body)
+}
+
+func TestStreamSendsSnapshotThenChanges(t *testing.T) {
+	srv, stream := newStreamServer(t)
+
+	r := connect(t, srv.URL+"/stream", "")
+	first := readEvents(t, r, 1)[0]
+	assert.Equal(t, "metrics", first.name)
+	assert.Contains(t, first.data, `"total_products":1`)
+
+	assert.Eventually(t, func() bool { return stream.Clients() == 1 }, time.Second, time.Millisecond)
+	stream.Publish(context.Background(), domain.ProductChange{Type: domain.ChangeDeleted, ProductID: "7"})
+
+	ev := readEvents(t, r, 1)[0]
+	assert.Equal(t, "product", ev.name)
+	assert.Equal(t, "1", ev.id)
+	assert.Contains(t, ev.data, `"product_id":"7"`)
+}
+
+func TestStreamResumesWithLastEventID(t *testing.T) {
+	srv, stream := newStreamServer(t)
+	for _, id := range []string{"1", "2", "3"} {
+		stream.Publish(context.Background(), domain.ProductChange{Type: domain.ChangeUpdated, ProductID: id})
+	}
+
+	r := connect(t, srv.URL+"/stream", "1")
+	events := readEvents(t, r, 2)
+	assert.Equal(t, "2", events[0].id)
+	assert.Equal(t, "3", events[1].id)
+	assert.Equal(t, "product", events[0].name)
+}
+
+func TestStreamHeartbeat(t *testing.T) {
+	srv, _ := newStreamServer(t)
+
+	r := connect(t, srv.URL+"/stream", "0")
+	for {
+		line, err := r.ReadString('\n')
+		assert.NoError(t, err)
+		if line == ": heartbeat\n" {
+			return
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package domain
+
+// CacheStats resume el uso de la caché de lecturas del catálogo.
+type CacheStats struct {
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	Coalesced     uint64  `json:"coalesced"`
+	Invalidations uint64  `json:"invalidations"`
+	Entries       int     `json:"entries"`
+	TTLSeconds    float64 `json:"ttl_seconds"`
+}
+
+type CacheStatsProvider interface {
+	Stats() CacheStats
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"mlsport/internal/slug"
+)
+
+const uncategorized = "sin-categoria"
+
+// MetricsCounters es el modelo de lectura que ProductService mantiene con cada escritura.
+type MetricsCounters struct {
+	TotalProducts int            `json:"total_products" bson:"total_products"`
+	TotalStock    int            `json:"total_stock" bson:"total_stock"`
+	PriceSum      float64        `json:"price_sum" bson:"price_sum"`
+	Categories    map[string]int `json:"categories" bson:"categories"`
+	UpdatedAt     time.Time      `json:"updated_at" bson:"updated_at"`
+}
+
+// CounterDelta es la variación a sumar atómicamente a los contadores.
+type CounterDelta struct {
+	Products   int
+	Stock      int
+	PriceSum   float64
+	Categories map[string]int
+}
+
+// CounterRepository guarda un juego de contadores por tienda.
+type CounterRepository interface {
+	Get(tenantID string) (*MetricsCounters, error)
+	Apply(tenantID string, delta CounterDelta) error
+	// Compute recalcula los contadores de la tienda desde la colección de productos.
+	Compute(tenantID string) (*MetricsCounters, error)
+	// Rebuild recalcula los contadores y reemplaza los guardados de forma atómica: un Apply
+	// confirmado entre el cálculo y el reemplazo no puede perderse.
+	Rebuild(tenantID string) (*MetricsCounters, error)
+}
+
+// CounterCheck compara los contadores guardados con los calculados en vivo.
+type CounterCheck struct {
+	Consistent  bool             `json:"consistent"`
+	Stored      *MetricsCounters `json:"stored"`
+	Live        *MetricsCounters `json:"live"`
+	Differences []string         `json:"differences"`
+}
+
+// CounterKey es la clave por categoría: el slug, sin puntos ni "$" que Mongo no admite en campos.
+func CounterKey(category string) string {
+	if key := slug.Make(category); key != "" {
+		return key
+	}
+	return uncategorized
+}
+
+func (c MetricsCounters) AveragePrice() float64 {
+	if c.TotalProducts == 0 {
+		return 0
+	}
+	return c.PriceSum / float64(c.TotalProducts)
+}
+
+// ProductDelta devuelve lo que aporta p a los contadores, con signo sign (+1 alta, -1 baja).
+func ProductDelta(p Product, sign int) CounterDelta {
+	return CounterDelta{
+		Products:   sign,
+		Stock:      sign * p.Stock,
+		PriceSum:   float64(sign) * p.Price,
+		Categories: map[string]int{CounterKey(p.Category): sign},
+	}
+}
+
+// ChangeDelta es la variación de una escritura: resta la versión anterior y suma la nueva
+// (cualquiera de las dos puede faltar en altas y bajas).
+func ChangeDelta(before, after *Product) CounterDelta {
+	var delta CounterDelta
+	if before != nil {
+		delta = delta.Plus(ProductDelta(*before, -1))
+	}
+	if after != nil {
+		delta = delta.Plus(ProductDelta(*after, 1))
+	}
+	return delta
+}
+
+// Plus combina dos variaciones, p. ej. quitar la versión anterior y sumar la nueva.
+func (d CounterDelta) Plus(o CounterDelta) CounterDelta {
+	out := CounterDelta{
+		Products:   d.Products + o.Products,
+		Stock:      d.Stock + o.Stock,
+		PriceSum:   d.PriceSum + o.PriceSum,
+		Categories: map[string]int{},
+	}
+	for k, v := range d.Categories {
+		out.Categories[k] += v
+	}
+	for k, v := range o.Categories {
+		out.Categories[k] += v
+	}
+	for k, v := range out.Categories {
+		if v == 0 {
+			delete(out.Categories, k)
+		}
+	}
+	return out
+}
+
+func (d CounterDelta) IsZero() bool {
+	return d.Products == 0 && d.Stock == 0 && d.PriceSum == 0 && len(d.Categories) == 0
+}
+
+// Diff lista las diferencias entre dos conjuntos de contadores (ignorando categorías en cero).
+func (c MetricsCounters) Diff(o MetricsCounters) []string {
+	diffs := []string{}
+	if c.TotalProducts != o.TotalProducts {
+		diffs = append(diffs, fmt.Sprintf("total_products: %d != %d", c.TotalProducts, o.TotalProducts))
+	}
+	if c.TotalStock != o.TotalStock {
+		diffs = append(diffs, fmt.Sprintf("total_stock: %d != %d", c.TotalStock, o.TotalStock))
+	}
+	if math.Abs(c.PriceSum-o.PriceSum) > 0.005 {
+		diffs = append(diffs, fmt.Sprintf("price_sum: %.2f != %.2f", c.PriceSum, o.PriceSum))
+	}
+
+	keys := map[string]bool{}
+	for k := range c.Categories {
+		keys[k] = true
+	}
+	for k := range o.Categories {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		if c.Categories[k] != o.Categories[k] {
+			diffs = append(diffs, fmt.Sprintf("categories.%s: %d != %d", k, c.Categories[k], o.Categories[k]))
+		}
+	}
+	return diffs
+}
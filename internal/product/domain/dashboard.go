@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	WidgetProducts      = "products"
+	WidgetMetrics       = "metrics"
+	WidgetTopCategories = "top_categories"
+	WidgetLowStock      = "low_stock"
+	WidgetRecentChanges = "recent_changes"
+)
+
+// DashboardWidgets son los widgets disponibles, en el orden en que se sirven por defecto.
+var DashboardWidgets = []string{
+	WidgetProducts,
+	WidgetMetrics,
+	WidgetTopCategories,
+	WidgetLowStock,
+	WidgetRecentChanges,
+}
+
+var (
+	ErrUnknownWidget = errors.New("widget desconocido")
+	ErrWidgetTimeout = errors.New("tiempo de espera agotado")
+)
+
+// DashboardWidget es el resultado de una sección; si falla, Data queda vacío y Error explica por qué.
+type DashboardWidget struct {
+	Data      interface{} `json:"data"`
+	Error     string      `json:"error,omitempty"`
+	ElapsedMS int64       `json:"elapsed_ms"`
+}
+
+// Dashboard agrupa los widgets pedidos; Partial indica que al menos uno falló.
+type Dashboard struct {
+	Widgets     map[string]DashboardWidget `json:"widgets"`
+	Partial     bool                       `json:"partial"`
+	GeneratedAt time.Time                  `json:"generated_at"`
+}
+
+// Failed indica que no se pudo obtener ningún widget.
+func (d Dashboard) Failed() bool {
+	for _, w := range d.Widgets {
+		if w.Error == "" {
+			return false
+		}
+	}
+	return len(d.Widgets) > 0
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"time"
+
+	"mlsport/internal/events"
+)
+
+const (
+	EventProductCreated = "product.created"
+	EventProductUpdated = "product.updated"
+	EventProductPatched = "product.patched"
+	EventProductDeleted = "product.deleted"
+	EventStockChanged   = "product.stock_changed"
+	EventPriceChanged   = "product.price_changed"
+)
+
+// ProductEvents son los eventos de ciclo de vida; cada escritura publica exactamente uno de ellos.
+var ProductEvents = []string{EventProductCreated, EventProductUpdated, EventProductPatched, EventProductDeleted}
+
+type ProductCreated struct {
+	Product Product   `json:"product"`
+	At      time.Time `json:"at"`
+}
+
+// ProductUpdated corresponde a un reemplazo completo (PUT).
+type ProductUpdated struct {
+	Before Product   `json:"before"`
+	After  Product   `json:"after"`
+	At     time.Time `json:"at"`
+}
+
+// ProductPatched corresponde a una modificación parcial; Fields son los campos enviados.
+type ProductPatched struct {
+	Before Product                `json:"before"`
+	After  Product                `json:"after"`
+	Fields map[string]interface{} `json:"fields"`
+	At     time.Time              `json:"at"`
+}
+
+type ProductDeleted struct {
+	Product Product   `json:"product"`
+	At      time.Time `json:"at"`
+}
+
+// StockChanged se publica junto a ProductUpdated o ProductPatched cuando cambia el stock.
+type StockChanged struct {
+	ProductID string    `json:"product_id"`
+	Name      string    `json:"name"`
+	Category  string    `json:"category"`
+	Previous  int       `json:"previous"`
+	Current   int       `json:"current"`
+	Threshold int       `json:"reorder_threshold,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// PriceChanged se publica junto a ProductUpdated o ProductPatched cuando cambia el precio.
+type PriceChanged struct {
+	ProductID string    `json:"product_id"`
+	Name      string    `json:"name"`
+	Category  string    `json:"category"`
+	Previous  float64   `json:"previous"`
+	Current   float64   `json:"current"`
+	At        time.Time `json:"at"`
+}
+
+func (ProductCreated) EventName() string { return EventProductCreated }
+func (ProductUpdated) EventName() string { return EventProductUpdated }
+func (ProductPatched) EventName() string { return EventProductPatched }
+func (ProductDeleted) EventName() string { return EventProductDeleted }
+func (StockChanged) EventName() string   { return EventStockChanged }
+func (PriceChanged) EventName() string   { return EventPriceChanged }
+
+// ProductEventsFor arma los eventos de una escritura: el de ciclo de vida y, en modificaciones,
+// StockChanged y PriceChanged si corresponde. fields distingue un PATCH (no nil) de un PUT.
+func ProductEventsFor(before, after *Product, fields map[string]interface{}, at time.Time) []events.Event {
+	switch {
+	case before == nil && after != nil:
+		return []events.Event{ProductCreated{Product: *after, At: at}}
+	case before != nil && after == nil:
+		return []events.Event{ProductDeleted{Product: *before, At: at}}
+	case before == nil:
+		return nil
+	}
+
+	var out []events.Event
+	if fields != nil {
+		out = append(out, ProductPatched{Before: *before, After: *after, Fields: fields, At: at})
+	} else {
+		out = append(out, ProductUpdated{Before: *before, After: *after, At: at})
+	}
+	if before.Stock != after.Stock {
+		out = append(out, StockChanged{
+			ProductID: after.ID, Name: after.Name, Category: after.Category,
+			Previous: before.Stock, Current: after.Stock, Threshold: after.ReorderThreshold, At: at,
+		})
+	}
+	if before.Price != after.Price {
+		out = append(out, PriceChanged{
+			ProductID: after.ID, Name: after.Name, Category: after.Category,
+			Previous: before.Price, Current: after.Price, At: at,
+		})
+	}
+	return out
+}
+
+// ChangeFrom resume un evento de ciclo de vida como ProductChange (el formato de los streams).
+func ChangeFrom(e events.Event) (ProductChange, bool) {
+	switch ev := e.(type) {
+	case ProductCreated:
+		p := ev.Product
+		return ProductChange{Type: ChangeCreated, ProductID: p.ID, Product: &p, At: ev.At}, true
+	case ProductUpdated:
+		before, after := ev.Before, ev.After
+		return ProductChange{Type: ChangeUpdated, ProductID: after.ID, Product: &after, Previous: &before, At: ev.At}, true
+	case ProductPatched:
+		before, after := ev.Before, ev.After
+		return ProductChange{Type: ChangeUpdated, ProductID: after.ID, Product: &after, Previous: &before, At: ev.At}, true
+	case ProductDeleted:
+		p := ev.Product
+		return ProductChange{Type: ChangeDeleted, ProductID: p.ID, Previous: &p, At: ev.At}, true
+	}
+	return ProductChange{}, false
+}
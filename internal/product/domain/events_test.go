@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"mlsport/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func eventNames(evs []events.Event) []string {
+	var out []string
+	for _, e := range evs {
+		out = append(out, e.EventName())
+	}
+	return out
+}
+
+func TestProductEventsFor(t *testing.T) {
+	now := time.Now()
+	before := &Product{ID: "1", Stock: 5, Price: 10}
+
+	created := ProductEventsFor(nil, before, nil, now)
+	assert.Equal(t, []string{EventProductCreated}, eventNames(created))
+
+	deleted := ProductEventsFor(before, nil, nil, now)
+	assert.Equal(t, []string{EventProductDeleted}, eventNames(deleted))
+
+	renamed := &Product{ID: "1", Name: "Nuevo", Stock: 5, Price: 10}
+	assert.Equal(t, []string{EventProductUpdated}, eventNames(ProductEventsFor(before, renamed, nil, now)))
+
+	restocked := &Product{ID: "1", Stock: 2, Price: 12, ReorderThreshold: 3}
+	evs := ProductEventsFor(before, restocked, map[string]interface{}{"stock": 2, "price": 12}, now)
+	assert.Equal(t, []string{EventProductPatched, EventStockChanged, EventPriceChanged}, eventNames(evs))
+
+	stock := evs[1].(StockChanged)
+	assert.Equal(t, 5, stock.Previous)
+	assert.Equal(t, 2, stock.Current)
+	assert.Equal(t, 3, stock.Threshold)
+
+	change, ok := ChangeFrom(evs[0])
+	assert.True(t, ok)
+	assert.Equal(t, ChangeUpdated, change.Type)
+	assert.Equal(t, 5, change.Previous.Stock)
+	_, ok = ChangeFrom(stock)
+	assert.False(t, ok)
+}
+
+func TestOutboxEntryRoundTrip(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	before := &Product{ID: "1", Name: "Balón", Stock: 5, Price: 10}
+	after := &Product{ID: "1", Name: "Balón", Stock: 2, Price: 10}
+
+	for _, e := range ProductEventsFor(before, after, map[string]interface{}{"stock": 2}, now) {
+		entry, err := NewOutboxEntry(e, now)
+		assert.NoError(t, err)
+		assert.Equal(t, e.EventName(), entry.Event)
+
+		decoded, err := DecodeEvent(entry)
+		assert.NoError(t, err)
+		assert.Equal(t, e.EventName(), decoded.EventName())
+	}
+
+	entry, _ := NewOutboxEntry(ProductDeleted{Product: *before, At: now}, now)
+	decoded, _ := DecodeEvent(entry)
+	change, ok := ChangeFrom(decoded)
+	assert.True(t, ok)
+	assert.Equal(t, "1", change.ProductID)
+	assert.Equal(t, 5, change.Previous.Stock)
+
+	_, err := DecodeEvent(OutboxEntry{Event: "product.unknown", Payload: "{}"})
+	assert.Error(t, err)
+}
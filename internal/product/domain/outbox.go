@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"mlsport/internal/events"
+)
+
+// OutboxEntry es un evento guardado en la misma transacción que la escritura del producto, a la
+// espera de que el relay lo publique. Payload es el evento serializado en JSON.
+type OutboxEntry struct {
+	ID string `json:"id" bson:"-"`
+	// Tenant es la tienda de la escritura; el relay publica el evento con ella en el contexto.
+	Tenant        string    `json:"tenant,omitempty" bson:"tenant,omitempty"`
+	Event         string    `json:"event" bson:"event"`
+	Payload       string    `json:"payload" bson:"payload"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+	Attempts      int       `json:"attempts" bson:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at" bson:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty" bson:"last_error,omitempty"`
+}
+
+// OutboxRepository es la cola de eventos pendientes que consume el relay.
+type OutboxRepository interface {
+	// Pending devuelve hasta limit entradas sin entregar ni apartadas, en orden de creación, incluidas
+	// las que esperan un reintento: el relay no debe adelantar a las siguientes.
+	Pending(ctx context.Context, limit int) ([]OutboxEntry, error)
+	MarkDelivered(ctx context.Context, id string, at time.Time) error
+	// MarkFailed registra un intento fallido y reprograma la entrada para retryAt.
+	MarkFailed(ctx context.Context, id string, attempts int, retryAt time.Time, lastErr string) error
+	// MarkDead aparta una entrada que agotó sus intentos para que no bloquee a las siguientes.
+	MarkDead(ctx context.Context, id string, attempts int, lastErr string) error
+}
+
+// NewOutboxEntry serializa un evento de producto para guardarlo en el outbox.
+func NewOutboxEntry(e events.Event, now time.Time) (OutboxEntry, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return OutboxEntry{}, err
+	}
+	return OutboxEntry{Event: e.EventName(), Payload: string(payload), CreatedAt: now, NextAttemptAt: now}, nil
+}
+
+// DecodeEvent reconstruye el evento tipado de una entrada del outbox.
+func DecodeEvent(entry OutboxEntry) (events.Event, error) {
+	switch entry.Event {
+	case EventProductCreated:
+		return decode[ProductCreated](entry.Payload)
+	case EventProductUpdated:
+		return decode[ProductUpdated](entry.Payload)
+	case EventProductPatched:
+		return decode[ProductPatched](entry.Payload)
+	case EventProductDeleted:
+		return decode[ProductDeleted](entry.Payload)
+	case EventStockChanged:
+		return decode[StockChanged](entry.Payload)
+	case EventPriceChanged:
+		return decode[PriceChanged](entry.Payload)
+	}
+	return nil, fmt.Errorf("evento desconocido en el outbox: %s", entry.Event)
+}
+
+func decode[T events.Event](payload string) (events.Event, error) {
+	var ev T
+	if err := json.Unmarshal([]byte(payload), &ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"errors"
+	"math"
+)
+
+const DefaultPriceBuckets = 10
+
+var ErrInvalidPriceBuckets = errors.New("los límites del histograma deben ser al menos dos y estar en orden ascendente")
+
+// PriceQuery define el histograma: Boundaries usa límites fijos ($bucket) y si está vacío
+// se reparten los precios en Buckets grupos automáticos ($bucketAuto).
+type PriceQuery struct {
+	Filter     ProductFilter
+	Boundaries []float64
+	Buckets    int
+}
+
+type PriceBucket struct {
+	Label string  `json:"label"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+type PriceDistribution struct {
+	Count     int           `json:"count"`
+	Min       float64       `json:"min"`
+	Max       float64       `json:"max"`
+	Average   float64       `json:"average"`
+	Median    float64       `json:"median"`
+	P25       float64       `json:"p25"`
+	P75       float64       `json:"p75"`
+	P90       float64       `json:"p90"`
+	Histogram []PriceBucket `json:"histogram"`
+}
+
+func (q PriceQuery) Validate() error {
+	if len(q.Boundaries) == 0 {
+		return nil
+	}
+	if len(q.Boundaries) < 2 {
+		return ErrInvalidPriceBuckets
+	}
+	for i := 1; i < len(q.Boundaries); i++ {
+		if q.Boundaries[i] <= q.Boundaries[i-1] {
+			return ErrInvalidPriceBuckets
+		}
+	}
+	return nil
+}
+
+// PricePercentiles son los percentiles que informa la distribución de precios.
+var PricePercentiles = []float64{25, 50, 75, 90}
+
+// PercentileRank ubica el percentil p (0-100) entre n precios ordenados: las posiciones (desde 0)
+// entre las que se interpola y la fracción entre ambas.
+func PercentileRank(n int, p float64) (lo, hi int, frac float64) {
+	rank := p / 100 * float64(n-1)
+	lo = int(math.Floor(rank))
+	hi = int(math.Ceil(rank))
+	return lo, hi, rank - float64(lo)
+}
+
+// Percentile interpola linealmente el percentil p (0-100) sobre precios ya ordenados.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return PercentileAt(func(i int) float64 { return sorted[i] }, len(sorted), p)
+}
+
+// PercentileAt interpola el percentil p entre n precios ordenados leyendo solo las posiciones
+// que indica PercentileRank, para no tener que cargar todos los precios.
+func PercentileAt(at func(i int) float64, n int, p float64) float64 {
+	if n == 0 {
+		return 0
+	}
+	lo, hi, frac := PercentileRank(n, p)
+	if lo == hi {
+		return at(lo)
+	}
+	return at(lo) + (at(hi)-at(lo))*frac
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	prices := []float64{10, 20, 30, 40, 50}
+
+	assert.Equal(t, 30.0, Percentile(prices, 50))
+	assert.Equal(t, 20.0, Percentile(prices, 25))
+	assert.InDelta(t, 46.0, Percentile(prices, 90), 1e-9)
+	assert.Equal(t, 0.0, Percentile(nil, 50))
+}
+
+func TestPercentileAt_OnlyReadsRankedPositions(t *testing.T) {
+	for n := 1; n <= 40; n++ {
+		sorted := make([]float64, n)
+		for i := range sorted {
+			sorted[i] = float64(i*i) + 0.5
+		}
+
+		for _, p := range PricePercentiles {
+			lo, hi, _ := PercentileRank(n, p)
+			at := func(i int) float64 {
+				if i != lo && i != hi {
+					t.Fatalf("n=%d p=%v leyó la posición %d", n, p, i)
+				}
+				return sorted[i]
+			}
+			assert.InDelta(t, Percentile(sorted, p), PercentileAt(at, n, p), 1e-9)
+		}
+	}
+	assert.Equal(t, 0.0, PercentileAt(nil, 0, 50))
+}
+
+func TestPriceQueryValidate(t *testing.T) {
+	assert.NoError(t, PriceQuery{}.Validate())
+	assert.NoError(t, PriceQuery{Boundaries: []float64{0, 50, 100}}.Validate())
+	assert.ErrorIs(t, PriceQuery{Boundaries: []float64{10}}.Validate(), ErrInvalidPriceBuckets)
+	assert.ErrorIs(t, PriceQuery{Boundaries: []float64{50, 10}}.Validate(), ErrInvalidPriceBuckets)
+}
@@ -1,13 +1,61 @@
 package domain
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"errors"
+	"time"
+
+	category "mlsport/internal/category/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrProductNotFound indica que el producto no existe en la tienda de la petición.
+var ErrProductNotFound = errors.New("producto no encontrado")
 
 type Product struct {
 	ID       string             `json:"id" bson:"-"`
 	ObjectID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
-	Name     string             `json:"name" bson:"name"`
-	Category string             `json:"category" bson:"category"`
-	Price    float64            `json:"price" bson:"price"`
-	Stock    int                `json:"stock" bson:"stock"`
-	Brand    string             `json:"brand" bson:"brand"`
+	// Tenant es la tienda dueña del producto; lo fija el repositorio según el contexto.
+	Tenant   string  `json:"-" bson:"tenant"`
+	Name     string  `json:"name" bson:"name"`
+	Category string  `json:"category" bson:"category"`
+	Price    float64 `json:"price" bson:"price"`
+	Stock    int     `json:"stock" bson:"stock"`
+	Brand    string  `json:"brand" bson:"brand"`
+	// ReorderThreshold es el stock mínimo antes de alertar; 0 usa el umbral general.
+	ReorderThreshold int                   `json:"reorder_threshold,omitempty" bson:"reorder_threshold,omitempty"`
+	Breadcrumbs      []category.Breadcrumb `json:"breadcrumbs,omitempty" bson:"-"`
+	// UpdatedAt lo fija el repositorio en cada alta o modificación.
+	UpdatedAt time.Time `json:"updated_at,omitzero" bson:"updated_at,omitempty"`
+}
+
+// ProductFilter restringe consultas por categoría y marca; los campos vacíos no filtran.
+type ProductFilter struct {
+	Categories []string
+	Brands     []string
+}
+
+func (f ProductFilter) IsEmpty() bool {
+	return len(f.Categories) == 0 && len(f.Brands) == 0
+}
+
+const DefaultMetricsTopN = 5
+
+// MetricsQuery limita el desglose a los TopN grupos con más productos dentro de Filter.
+// LowStockThreshold es el umbral general para contar productos con stock bajo.
+type MetricsQuery struct {
+	TopN              int
+	Filter            ProductFilter
+	LowStockThreshold int
+}
+
+// MetricsBreakdown resume los productos de una categoría o marca.
+type MetricsBreakdown struct {
+	Name       string  `json:"name" bson:"_id"`
+	Count      int     `json:"count" bson:"count"`
+	Stock      int     `json:"stock" bson:"stock"`
+	StockValue float64 `json:"stock_value" bson:"stock_value"`
+	MinPrice   float64 `json:"min_price" bson:"min_price"`
+	MaxPrice   float64 `json:"max_price" bson:"max_price"`
+	AvgPrice   float64 `json:"average_price" bson:"average_price"`
 }
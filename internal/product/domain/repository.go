@@ -1,13 +1,34 @@
 package domain
 
+import (
+	"context"
+	category "mlsport/internal/category/domain"
+)
+
 type ProductRepository interface {
-	Create(product *Product) error
-	FindAll() ([]Product, error)
-	FindByID(id string) (*Product, error)
-	FindByCategory(category string) ([]Product, error)
-	Update(product *Product) error
-	Patch(id string, fields map[string]interface{}) error
-	Delete(id string) error
-	GetMetrics() (map[string]interface{}, error)
-	GetCategories() ([]string, error)
+	Create(ctx context.Context, product *Product) error
+	FindAll(ctx context.Context) ([]Product, error)
+	Find(ctx context.Context, filter ProductFilter) ([]Product, error)
+	FindByID(ctx context.Context, id string) (*Product, error)
+	FindByCategory(ctx context.Context, categories ...string) ([]Product, error)
+	Update(ctx context.Context, product *Product) error
+	Patch(ctx context.Context, id string, fields map[string]interface{}) error
+	Delete(ctx context.Context, id string) error
+	GetMetrics(ctx context.Context, query MetricsQuery) (map[string]interface{}, error)
+	GetPriceDistribution(ctx context.Context, query PriceQuery) (*PriceDistribution, error)
+	// FindLowStock devuelve los productos cuyo stock no supera su umbral (o defaultThreshold).
+	FindLowStock(ctx context.Context, defaultThreshold int) ([]Product, error)
+	GetCategories(ctx context.Context) ([]string, error)
+	// FindRecent devuelve los últimos limit productos creados o modificados.
+	FindRecent(ctx context.Context, limit int) ([]Product, error)
+}
+
+// CategoryResolver expone la jerarquía de categorías administradas de la tienda del contexto.
+type CategoryResolver interface {
+	Tree(ctx context.Context) (*category.Tree, error)
+}
+
+// BrandResolver traduce una marca libre ("NIKE", "nike ") a su nombre canónico.
+type BrandResolver interface {
+	Canonical(ctx context.Context, brand string) (string, error)
 }
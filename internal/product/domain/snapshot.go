@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+var ErrInvalidGranularity = errors.New("granularidad inválida: use day, week o month")
+
+// MetricsSnapshot guarda las métricas de un día; se sobrescribe durante el día y queda
+// con el último valor (cierre) cuando el día termina.
+type MetricsSnapshot struct {
+	ID            string                 `json:"-" bson:"-"`
+	ObjectID      primitive.ObjectID     `json:"-" bson:"_id,omitempty"`
+	Tenant        string                 `json:"-" bson:"tenant"`
+	Date          time.Time              `json:"date" bson:"date"`
+	TakenAt       time.Time              `json:"taken_at" bson:"taken_at"`
+	TotalProducts int                    `json:"total_products" bson:"total_products"`
+	TotalStock    int                    `json:"total_stock" bson:"total_stock"`
+	AveragePrice  float64                `json:"average_price" bson:"average_price"`
+	StockValue    float64                `json:"stock_value" bson:"stock_value"`
+	LowStock      int                    `json:"low_stock" bson:"low_stock"`
+	Metrics       map[string]interface{} `json:"metrics,omitempty" bson:"metrics"`
+}
+
+// SnapshotRepository opera sobre los snapshots de la tienda de ctx.
+type SnapshotRepository interface {
+	// Save crea o reemplaza el snapshot del día de s.Date.
+	Save(ctx context.Context, s *MetricsSnapshot) error
+	FindRange(ctx context.Context, from, to time.Time) ([]MetricsSnapshot, error)
+}
+
+type MetricsPoint struct {
+	Period        time.Time `json:"period"`
+	TotalProducts int       `json:"total_products"`
+	TotalStock    int       `json:"total_stock"`
+	AveragePrice  float64   `json:"average_price"`
+	StockValue    float64   `json:"stock_value"`
+	LowStock      int       `json:"low_stock"`
+}
+
+type MetricsDelta struct {
+	TotalProducts        int      `json:"total_products"`
+	TotalStock           int      `json:"total_stock"`
+	AveragePrice         float64  `json:"average_price"`
+	TotalProductsPercent *float64 `json:"total_products_percent"`
+	TotalStockPercent    *float64 `json:"total_stock_percent"`
+	AveragePricePercent  *float64 `json:"average_price_percent"`
+}
+
+// MetricsComparison compara el cierre de dos periodos; Delta = Current - Previous.
+type MetricsComparison struct {
+	Current  *MetricsPoint `json:"current"`
+	Previous *MetricsPoint `json:"previous"`
+	Delta    *MetricsDelta `json:"delta"`
+}
+
+// Day trunca t al inicio de su día en UTC.
+func Day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// PeriodStart devuelve el inicio del día, semana (lunes) o mes que contiene t.
+func PeriodStart(t time.Time, granularity string) (time.Time, error) {
+	day := Day(t)
+	switch granularity {
+	case GranularityDay, "":
+		return day, nil
+	case GranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset), nil
+	case GranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Time{}, ErrInvalidGranularity
+}
+
+func (s MetricsSnapshot) Point(period time.Time) MetricsPoint {
+	return MetricsPoint{
+		Period:        period,
+		TotalProducts: s.TotalProducts,
+		TotalStock:    s.TotalStock,
+		AveragePrice:  s.AveragePrice,
+		StockValue:    s.StockValue,
+		LowStock:      s.LowStock,
+	}
+}
+
+func NewMetricsDelta(current, previous MetricsPoint) *MetricsDelta {
+	return &MetricsDelta{
+		TotalProducts:        current.TotalProducts - previous.TotalProducts,
+		TotalStock:           current.TotalStock - previous.TotalStock,
+		AveragePrice:         current.AveragePrice - previous.AveragePrice,
+		TotalProductsPercent: percentChange(float64(current.TotalProducts), float64(previous.TotalProducts)),
+		TotalStockPercent:    percentChange(float64(current.TotalStock), float64(previous.TotalStock)),
+		AveragePricePercent:  percentChange(current.AveragePrice, previous.AveragePrice),
+	}
+}
+
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	p := (current - previous) / previous * 100
+	return &p
+}
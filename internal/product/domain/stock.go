@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+const DefaultLowStockThreshold = 5
+
+// Threshold devuelve el umbral de reposición del producto o el general si no tiene uno propio.
+func (p Product) Threshold(fallback int) int {
+	if p.ReorderThreshold > 0 {
+		return p.ReorderThreshold
+	}
+	return fallback
+}
+
+func (p Product) IsLowStock(fallback int) bool {
+	return p.Stock <= p.Threshold(fallback)
+}
+
+type LowStockAlert struct {
+	Tenant    string    `json:"tenant"`
+	ProductID string    `json:"product_id"`
+	Name      string    `json:"name"`
+	Category  string    `json:"category"`
+	Brand     string    `json:"brand"`
+	Stock     int       `json:"stock"`
+	Threshold int       `json:"threshold"`
+	At        time.Time `json:"at"`
+}
+
+// PartialDeliveryError indica que la alerta llegó a algunos canales y falló en otros. Pending
+// agrupa los que fallaron: se reintenta solo en ellos, para no repetirla en los que la recibieron.
+type PartialDeliveryError struct {
+	Pending AlertNotifier
+	Err     error
+}
+
+func (e *PartialDeliveryError) Error() string {
+	return "la alerta no llegó a todos los canales: " + e.Err.Error()
+}
+
+func (e *PartialDeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// AlertNotifier entrega alertas de stock bajo (log, webhook, correo...).
+type AlertNotifier interface {
+	Notify(alert LowStockAlert) error
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrTooManySubscriptions = errors.New("se superó el máximo de suscripciones por conexión")
+
+// StockEvent avisa un cambio de stock o precio (o un alta o baja) a las terminales suscritas.
+// Seq es correlativo para todo el feed: el cliente guarda el último recibido y lo envía al reconectar.
+// Los campos Previous* van vacíos en las altas.
+type StockEvent struct {
+	Seq           uint64    `json:"seq"`
+	Tenant        string    `json:"-"`
+	Type          string    `json:"type"`
+	ProductID     string    `json:"product_id"`
+	Name          string    `json:"name"`
+	Category      string    `json:"category"`
+	Stock         int       `json:"stock"`
+	Price         float64   `json:"price"`
+	PreviousStock *int      `json:"previous_stock,omitempty"`
+	PreviousPrice *float64  `json:"previous_price,omitempty"`
+	At            time.Time `json:"at"`
+}
+
+// StockEventFrom convierte un cambio en evento de stock; ok es false si no cambió ni stock ni precio.
+func StockEventFrom(c ProductChange) (StockEvent, bool) {
+	ev := StockEvent{Type: c.Type, ProductID: c.ProductID, At: c.At}
+
+	current := c.Product
+	if current == nil {
+		current = c.Previous
+	}
+	if current != nil {
+		ev.Name = current.Name
+		ev.Category = current.Category
+	}
+	if c.Product != nil {
+		ev.Stock = c.Product.Stock
+		ev.Price = c.Product.Price
+	}
+	if c.Previous != nil {
+		stock, price := c.Previous.Stock, c.Previous.Price
+		ev.PreviousStock = &stock
+		ev.PreviousPrice = &price
+	}
+
+	if c.Type == ChangeUpdated && c.Product != nil && c.Previous != nil &&
+		c.Product.Stock == c.Previous.Stock && c.Product.Price == c.Previous.Price {
+		return ev, false
+	}
+	return ev, true
+}
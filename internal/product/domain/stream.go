@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// ProductChange resume un evento de ciclo de vida para los streams; Product es el estado posterior
+// (nil en las bajas) y Previous el anterior (nil en las altas).
+type ProductChange struct {
+	Type      string    `json:"type"`
+	ProductID string    `json:"product_id"`
+	Product   *Product  `json:"product,omitempty"`
+	Previous  *Product  `json:"-"`
+	At        time.Time `json:"at"`
+}
+
+const (
+	StreamEventProduct = "product"
+	StreamEventMetrics = "metrics"
+)
+
+// StreamEvent es un evento del stream del dashboard; ID crece de a uno (entre todas las tiendas,
+// así que una tienda puede ver saltos) y permite retomar la conexión con Last-Event-ID.
+type StreamEvent struct {
+	ID     uint64
+	Tenant string
+	Name   string
+	Data   interface{}
+}
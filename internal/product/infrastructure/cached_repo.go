@@ -0,0 +1,217 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CachedProductRepo decora un ProductRepository guardando por TTL las lecturas agregadas
+// (listado, métricas, precios, stock bajo, categorías). Cualquier escritura invalida todo y
+// las lecturas concurrentes de la misma clave comparten una sola consulta a Mongo.
+type CachedProductRepo struct {
+	domain.ProductRepository
+	TTL time.Duration
+
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	generation uint64
+	group      singleflight.Group
+	lastSweep  time.Time
+
+	hits, misses, coalesced, invalidations atomic.Uint64
+}
+
+// cacheSweepEvery es cada cuánto se descartan las entradas vencidas; sin esto, las claves que no
+// se vuelven a pedir (filtros de una sola vez) quedarían en memoria hasta la próxima escritura.
+const cacheSweepEvery = time.Minute
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func NewCachedProductRepo(repo domain.ProductRepository, ttl time.Duration) *CachedProductRepo {
+	return &CachedProductRepo{ProductRepository: repo, TTL: ttl, entries: map[string]cacheEntry{}}
+}
+
+func (r *CachedProductRepo) FindAll(ctx context.Context) ([]domain.Product, error) {
+	v, err := r.get(ctx, "all", func(ctx context.Context) (interface{}, error) {
+		return r.ProductRepository.FindAll(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyProducts(v.([]domain.Product)), nil
+}
+
+func (r *CachedProductRepo) GetMetrics(ctx context.Context, q domain.MetricsQuery) (map[string]interface{}, error) {
+	v, err := r.get(ctx, fmt.Sprintf("metrics:%+v", q), func(ctx context.Context) (interface{}, error) {
+		return r.ProductRepository.GetMetrics(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cached := v.(map[string]interface{})
+	out := make(map[string]interface{}, len(cached))
+	for k, val := range cached {
+		out[k] = val
+	}
+	return out, nil
+}
+
+func (r *CachedProductRepo) GetPriceDistribution(ctx context.Context, q domain.PriceQuery) (*domain.PriceDistribution, error) {
+	v, err := r.get(ctx, fmt.Sprintf("prices:%+v", q), func(ctx context.Context) (interface{}, error) {
+		return r.ProductRepository.GetPriceDistribution(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cached := v.(*domain.PriceDistribution)
+	if cached == nil {
+		return nil, nil
+	}
+	dist := *cached
+	return &dist, nil
+}
+
+func (r *CachedProductRepo) FindLowStock(ctx context.Context, defaultThreshold int) ([]domain.Product, error) {
+	v, err := r.get(ctx, fmt.Sprintf("lowstock:%d", defaultThreshold), func(ctx context.Context) (interface{}, error) {
+		return r.ProductRepository.FindLowStock(ctx, defaultThreshold)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyProducts(v.([]domain.Product)), nil
+}
+
+func (r *CachedProductRepo) GetCategories(ctx context.Context) ([]string, error) {
+	v, err := r.get(ctx, "categories", func(ctx context.Context) (interface{}, error) {
+		return r.ProductRepository.GetCategories(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]string(nil), v.([]string)...), nil
+}
+
+func (r *CachedProductRepo) Create(ctx context.Context, p *domain.Product) error {
+	defer r.Invalidate()
+	return r.ProductRepository.Create(ctx, p)
+}
+
+func (r *CachedProductRepo) Update(ctx context.Context, p *domain.Product) error {
+	defer r.Invalidate()
+	return r.ProductRepository.Update(ctx, p)
+}
+
+func (r *CachedProductRepo) Patch(ctx context.Context, id string, fields map[string]interface{}) error {
+	defer r.Invalidate()
+	return r.ProductRepository.Patch(ctx, id, fields)
+}
+
+func (r *CachedProductRepo) Delete(ctx context.Context, id string) error {
+	defer r.Invalidate()
+	return r.ProductRepository.Delete(ctx, id)
+}
+
+// Invalidate descarta todo lo guardado; también la usan quienes escriben productos por fuera
+// del repositorio (p. ej. renombrar categorías).
+func (r *CachedProductRepo) Invalidate() {
+	r.mu.Lock()
+	r.entries = map[string]cacheEntry{}
+	r.generation++
+	r.mu.Unlock()
+	r.invalidations.Add(1)
+}
+
+func (r *CachedProductRepo) Stats() domain.CacheStats {
+	r.mu.Lock()
+	entries := len(r.entries)
+	r.mu.Unlock()
+
+	return domain.CacheStats{
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Coalesced:     r.coalesced.Load(),
+		Invalidations: r.invalidations.Load(),
+		Entries:       entries,
+		TTLSeconds:    r.TTL.Seconds(),
+	}
+}
+
+// get sirve key desde la caché o la carga una sola vez para todos los que la piden a la vez.
+// La carga compartida no se cancela si quien la inició abandona; cada llamador deja de esperar
+// cuando vence su propio contexto. Las claves llevan la tienda del contexto: cada tienda tiene
+// sus propias entradas y nunca comparte una carga con otra.
+func (r *CachedProductRepo) get(ctx context.Context, key string, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	key = tenant.ID(ctx) + "|" + key
+	now := time.Now()
+	r.mu.Lock()
+	r.sweep(now)
+	if e, ok := r.entries[key]; ok && now.Before(e.expires) {
+		r.mu.Unlock()
+		r.hits.Add(1)
+		return e.value, nil
+	}
+	gen := r.generation
+	r.mu.Unlock()
+
+	// La generación en la clave evita que una lectura iniciada antes de una escritura
+	// se comparta con lecturas posteriores a ella.
+	var leader atomic.Bool
+	loadCtx := context.WithoutCancel(ctx)
+	ch := r.group.DoChan(fmt.Sprintf("%d:%s", gen, key), func() (interface{}, error) {
+		leader.Store(true)
+		r.misses.Add(1)
+		v, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		r.mu.Lock()
+		if r.generation == gen {
+			r.entries[key] = cacheEntry{value: v, expires: time.Now().Add(r.TTL)}
+		}
+		r.mu.Unlock()
+		return v, nil
+	})
+
+	select {
+	case res := <-ch:
+		if res.Shared && !leader.Load() {
+			r.coalesced.Add(1)
+		}
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// sweep descarta las entradas vencidas; se llama con r.mu tomado.
+func (r *CachedProductRepo) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < cacheSweepEvery {
+		return
+	}
+	r.lastSweep = now
+	for key, e := range r.entries {
+		if !now.Before(e.expires) {
+			delete(r.entries, key)
+		}
+	}
+}
+
+func copyProducts(list []domain.Product) []domain.Product {
+	if list == nil {
+		return nil
+	}
+	return append([]domain.Product(nil), list...)
+}
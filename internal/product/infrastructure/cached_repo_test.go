@@ -0,0 +1,146 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingRepo struct {
+	domain.ProductRepository
+	metricsCalls atomic.Int32
+	release      chan struct{}
+}
+
+func (r *countingRepo) GetMetrics(ctx context.Context, q domain.MetricsQuery) (map[string]interface{}, error) {
+	r.metricsCalls.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	return map[string]interface{}{"total_products": 3}, nil
+}
+
+func (r *countingRepo) Patch(ctx context.Context, id string, fields map[string]interface{}) error {
+	return nil
+}
+
+func TestCachedRepo_HitsUntilWrite(t *testing.T) {
+	inner := &countingRepo{}
+	repo := NewCachedProductRepo(inner, time.Minute)
+
+	_, _ = repo.GetMetrics(context.Background(), domain.MetricsQuery{TopN: 5})
+	data, err := repo.GetMetrics(context.Background(), domain.MetricsQuery{TopN: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, data["total_products"])
+	assert.Equal(t, int32(1), inner.metricsCalls.Load())
+
+	// Modificar el resultado devuelto no altera lo guardado.
+	data["total_products"] = 99
+	again, _ := repo.GetMetrics(context.Background(), domain.MetricsQuery{TopN: 5})
+	assert.Equal(t, 3, again["total_products"])
+
+	assert.NoError(t, repo.Patch(context.Background(), "1", map[string]interface{}{"stock": 1}))
+	_, _ = repo.GetMetrics(context.Background(), domain.MetricsQuery{TopN: 5})
+	assert.Equal(t, int32(2), inner.metricsCalls.Load())
+
+	stats := repo.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Invalidations)
+}
+
+func TestCachedRepo_SeparatesTenants(t *testing.T) {
+	inner := &countingRepo{}
+	repo := NewCachedProductRepo(inner, time.Minute)
+	a := tenant.WithID(context.Background(), "tienda-a")
+	b := tenant.WithID(context.Background(), "tienda-b")
+
+	_, _ = repo.GetMetrics(a, domain.MetricsQuery{})
+	_, _ = repo.GetMetrics(b, domain.MetricsQuery{})
+	_, _ = repo.GetMetrics(a, domain.MetricsQuery{})
+
+	assert.Equal(t, int32(2), inner.metricsCalls.Load())
+	assert.Equal(t, 2, repo.Stats().Entries)
+}
+
+func TestCachedRepo_ExpiresAfterTTL(t *testing.T) {
+	inner := &countingRepo{}
+	repo := NewCachedProductRepo(inner, time.Millisecond)
+
+	_, _ = repo.GetMetrics(context.Background(), domain.MetricsQuery{})
+	time.Sleep(5 * time.Millisecond)
+	_, _ = repo.GetMetrics(context.Background(), domain.MetricsQuery{})
+
+	assert.Equal(t, int32(2), inner.metricsCalls.Load())
+}
+
+func TestCachedRepo_SweepsExpiredEntries(t *testing.T) {
+	inner := &countingRepo{}
+	repo := NewCachedProductRepo(inner, time.Millisecond)
+
+	for i := 1; i <= 20; i++ {
+		_, _ = repo.GetMetrics(context.Background(), domain.MetricsQuery{TopN: i})
+	}
+	assert.Equal(t, 20, repo.Stats().Entries)
+
+	time.Sleep(5 * time.Millisecond)
+	repo.mu.Lock()
+	repo.lastSweep = time.Time{}
+	repo.mu.Unlock()
+	_, _ = repo.GetMetrics(context.Background(), domain.MetricsQuery{TopN: 99})
+
+	assert.Equal(t, 1, repo.Stats().Entries)
+}
+
+func TestCachedRepo_CoalescesConcurrentLoads(t *testing.T) {
+	inner := &countingRepo{release: make(chan struct{})}
+	repo := NewCachedProductRepo(inner, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.GetMetrics(context.Background(), domain.MetricsQuery{})
+			assert.NoError(t, err)
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return inner.metricsCalls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), inner.metricsCalls.Load())
+	assert.Equal(t, uint64(1), repo.Stats().Misses)
+}
+
+func TestCachedRepo_CallerCancelDoesNotAbortSharedLoad(t *testing.T) {
+	inner := &countingRepo{release: make(chan struct{})}
+	repo := NewCachedProductRepo(inner, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := repo.GetMetrics(ctx, domain.MetricsQuery{})
+		done <- err
+	}()
+
+	assert.Eventually(t, func() bool { return inner.metricsCalls.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	// La carga sigue y queda guardada para el siguiente.
+	close(inner.release)
+	data, err := repo.GetMetrics(context.Background(), domain.MetricsQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, data["total_products"])
+	assert.Equal(t, int32(1), inner.metricsCalls.Load())
+}
@@ -0,0 +1,319 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mlsport/config"
+	"mlsport/internal/events"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultChangeStreamRetry      = 5 * time.Second
+	DefaultChangeStreamMaxBackoff = time.Minute
+)
+
+// ProductChangeWatcher escucha el change stream de products y publica los mismos eventos de
+// producto para las escrituras hechas por fuera de la API (scripts de datos, consola de Mongo).
+// Las escrituras de la API van en una transacción junto a su entrada del outbox, así que con
+// SkipTransactional se ignoran para no publicarlas dos veces.
+//
+// El resume token se guarda después de publicar: tras un reinicio se retoma desde el último
+// cambio publicado (al menos una vez).
+type ProductChangeWatcher struct {
+	CollectionName       string
+	TokensCollectionName string
+	Publisher            events.Publisher
+	SkipTransactional    bool
+	RetryDelay           time.Duration
+	MaxBackoff           time.Duration
+	// OnExternalChange recibe cada cambio externo con su estado anterior y posterior, por ejemplo
+	// para invalidar la caché y ajustar los contadores; ctx lleva la tienda del producto.
+	OnExternalChange func(ctx context.Context, before, after *domain.Product)
+	// OnResync se llama cuando falta el estado anterior (sin pre-imágenes) y los contadores
+	// deben recalcularse.
+	OnResync func()
+}
+
+// changeEvent son los campos del change stream que se usan.
+type changeEvent struct {
+	ID                       bson.Raw            `bson:"_id"`
+	OperationType            string              `bson:"operationType"`
+	FullDocument             *domain.Product     `bson:"fullDocument"`
+	FullDocumentBeforeChange *domain.Product     `bson:"fullDocumentBeforeChange"`
+	DocumentKey              documentKey         `bson:"documentKey"`
+	UpdateDescription        *updateDescription  `bson:"updateDescription"`
+	TxnNumber                *int64              `bson:"txnNumber"`
+	ClusterTime              primitive.Timestamp `bson:"clusterTime"`
+	WallTime                 time.Time           `bson:"wallTime"`
+}
+
+type documentKey struct {
+	ID primitive.ObjectID `bson:"_id"`
+}
+
+type updateDescription struct {
+	UpdatedFields bson.M   `bson:"updatedFields"`
+	RemovedFields []string `bson:"removedFields"`
+}
+
+type resumeTokenDocument struct {
+	ID        string    `bson:"_id"`
+	Token     bson.Raw  `bson:"token"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+func NewProductChangeWatcher(publisher events.Publisher) *ProductChangeWatcher {
+	return &ProductChangeWatcher{
+		CollectionName:       "products",
+		TokensCollectionName: "change_stream_tokens",
+		Publisher:            publisher,
+		SkipTransactional:    true,
+		RetryDelay:           DefaultChangeStreamRetry,
+		MaxBackoff:           DefaultChangeStreamMaxBackoff,
+	}
+}
+
+// EnablePreImages activa las pre-imágenes de la colección (MongoDB 6.0+), necesarias para conocer
+// el estado anterior en modificaciones y bajas.
+func (w *ProductChangeWatcher) EnablePreImages(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cmd := bson.D{
+		{Key: "collMod", Value: w.CollectionName},
+		{Key: "changeStreamPreAndPostImages", Value: bson.M{"enabled": true}},
+	}
+	return config.GetDB().RunCommand(ctx, cmd).Err()
+}
+
+// Run escucha hasta que se cancela ctx, reabriendo el stream si se corta.
+func (w *ProductChangeWatcher) Run(ctx context.Context) {
+	for {
+		err := w.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.ErrorContext(ctx, "Change stream interrumpido, se reintenta", "collection", w.CollectionName, "retry_in", w.RetryDelay.String(), logging.Err(err))
+		if !sleep(ctx, w.RetryDelay) {
+			return
+		}
+	}
+}
+
+func (w *ProductChangeWatcher) watch(ctx context.Context) error {
+	opts := options.ChangeStream().
+		SetFullDocument(options.UpdateLookup).
+		SetFullDocumentBeforeChange(options.WhenAvailable)
+	token, err := w.loadToken(ctx)
+	if err != nil {
+		return err
+	}
+	if token != nil {
+		opts.SetResumeAfter(token)
+	}
+
+	stream, err := config.GetDB().Collection(w.CollectionName).Watch(ctx, mongo.Pipeline{}, opts)
+	if historyLost(err) {
+		// El token ya salió del oplog: no se puede retomar, se sigue desde ahora.
+		slog.WarnContext(ctx, "Resume token vencido; los cambios intermedios no se publicarán", "collection", w.CollectionName)
+		if w.OnResync != nil {
+			w.OnResync()
+		}
+		return w.saveToken(ctx, nil)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := stream.Close(context.Background()); err != nil {
+			slog.ErrorContext(ctx, "Error closing change stream", logging.Err(err))
+		}
+	}()
+
+	for stream.Next(ctx) {
+		var ev changeEvent
+		if err := stream.Decode(&ev); err != nil {
+			return err
+		}
+		if ev.OperationType == "invalidate" {
+			return w.saveToken(ctx, nil)
+		}
+		if !(w.SkipTransactional && ev.TxnNumber != nil) {
+			if err := w.handle(ctx, ev); err != nil {
+				return err
+			}
+		}
+		if err := w.saveToken(ctx, stream.ResumeToken()); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
+
+// handle publica los eventos del cambio, reintentando hasta lograrlo: el token no avanza mientras
+// el cambio no se haya publicado.
+func (w *ProductChangeWatcher) handle(ctx context.Context, ev changeEvent) error {
+	before, after, evs, complete := changeEvents(ev)
+	if len(evs) == 0 {
+		return nil
+	}
+
+	pctx := events.ContextWithID(tenant.WithID(ctx, productTenant(before, after)), "cs:"+tokenData(ev.ID))
+	wait := w.RetryDelay
+	for {
+		err := w.Publisher.Publish(pctx, evs...)
+		if err == nil {
+			break
+		}
+		slog.ErrorContext(pctx, "Error publicando cambio externo, se reintenta", "product", ev.DocumentKey.ID.Hex(), "retry_in", wait.String(), logging.Err(err))
+		if !sleep(ctx, wait) {
+			return ctx.Err()
+		}
+		if wait *= 2; wait > w.MaxBackoff {
+			wait = w.MaxBackoff
+		}
+	}
+
+	switch {
+	case !complete && w.OnResync != nil:
+		w.OnResync()
+	case complete && w.OnExternalChange != nil:
+		w.OnExternalChange(pctx, before, after)
+	}
+	return nil
+}
+
+// changeEvents traduce un cambio del stream a eventos de producto. complete es false cuando falta
+// el estado anterior (la colección no tiene pre-imágenes): en ese caso Before repite After y no
+// se derivan product.stock_changed ni product.price_changed.
+func changeEvents(ev changeEvent) (before, after *domain.Product, evs []events.Event, complete bool) {
+	at := ev.WallTime
+	if at.IsZero() {
+		at = time.Unix(int64(ev.ClusterTime.T), 0)
+	}
+	at = at.UTC()
+
+	before = withID(ev.FullDocumentBeforeChange)
+	after = withID(ev.FullDocument)
+	complete = true
+
+	switch ev.OperationType {
+	case "insert":
+		return nil, after, domain.ProductEventsFor(nil, after, nil, at), true
+	case "update", "replace":
+		if after == nil {
+			// Se borró antes de poder leerlo; el delete llega como otro cambio.
+			return nil, nil, nil, true
+		}
+		var fields map[string]interface{}
+		if ev.OperationType == "update" {
+			fields = map[string]interface{}{}
+			if ev.UpdateDescription != nil {
+				for k, v := range ev.UpdateDescription.UpdatedFields {
+					fields[k] = v
+				}
+				for _, k := range ev.UpdateDescription.RemovedFields {
+					fields[k] = nil
+				}
+			}
+		}
+		if before == nil {
+			complete = false
+			copied := *after
+			before = &copied
+		}
+		return before, after, domain.ProductEventsFor(before, after, fields, at), complete
+	case "delete":
+		if before == nil {
+			complete = false
+			before = &domain.Product{ID: ev.DocumentKey.ID.Hex(), ObjectID: ev.DocumentKey.ID}
+		}
+		return before, nil, domain.ProductEventsFor(before, nil, nil, at), complete
+	}
+	return nil, nil, nil, true
+}
+
+// productTenant es la tienda del documento cambiado. Un borrado sin pre-imagen no la trae y se
+// publica en la tienda por defecto.
+func productTenant(before, after *domain.Product) string {
+	if after != nil && after.Tenant != "" {
+		return after.Tenant
+	}
+	if before != nil && before.Tenant != "" {
+		return before.Tenant
+	}
+	return tenant.Default
+}
+
+func withID(p *domain.Product) *domain.Product {
+	if p != nil {
+		p.ID = p.ObjectID.Hex()
+	}
+	return p
+}
+
+func tokenData(id bson.Raw) string {
+	if v, err := id.LookupErr("_data"); err == nil {
+		if s, ok := v.StringValueOK(); ok {
+			return s
+		}
+	}
+	return id.String()
+}
+
+func (w *ProductChangeWatcher) loadToken(ctx context.Context) (bson.Raw, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var doc resumeTokenDocument
+	err := config.GetDB().Collection(w.TokensCollectionName).FindOne(ctx, bson.M{"_id": w.CollectionName}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.Token, nil
+}
+
+// saveToken guarda el token; nil lo borra para empezar desde el presente en la próxima apertura.
+func (w *ProductChangeWatcher) saveToken(ctx context.Context, token bson.Raw) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(w.TokensCollectionName)
+	if token == nil {
+		_, err := collection.DeleteOne(ctx, bson.M{"_id": w.CollectionName})
+		return err
+	}
+	doc := resumeTokenDocument{ID: w.CollectionName, Token: token, UpdatedAt: time.Now().UTC()}
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": w.CollectionName}, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+// historyLost reconoce el error de un resume token que ya no está en el oplog.
+func historyLost(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && (cmdErr.Code == 286 || cmdErr.Code == 280)
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"mlsport/internal/events"
+	"mlsport/internal/product/domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// decodeChange arma un cambio como lo entrega el driver, pasando por BSON.
+func decodeChange(t *testing.T, doc bson.M) changeEvent {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	assert.NoError(t, err)
+	var ev changeEvent
+	assert.NoError(t, bson.Unmarshal(raw, &ev))
+	return ev
+}
+
+func TestChangeEvents(t *testing.T) {
+	id := primitive.NewObjectID()
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	product := func(stock int, price float64) bson.M {
+		return bson.M{"_id": id, "name": "Balón", "category": "futbol", "stock": stock, "price": price}
+	}
+	base := func(op string) bson.M {
+		return bson.M{"_id": bson.M{"_data": "8266"}, "operationType": op, "documentKey": bson.M{"_id": id}, "wallTime": at}
+	}
+
+	insert := base("insert")
+	insert["fullDocument"] = product(3, 10)
+	_, after, evs, complete := changeEvents(decodeChange(t, insert))
+	assert.True(t, complete)
+	assert.Equal(t, id.Hex(), after.ID)
+	assert.Equal(t, []string{domain.EventProductCreated}, eventNames(evs))
+	assert.Equal(t, at, evs[0].(domain.ProductCreated).At)
+
+	update := base("update")
+	update["fullDocumentBeforeChange"] = product(3, 10)
+	update["fullDocument"] = product(1, 10)
+	update["updateDescription"] = bson.M{"updatedFields": bson.M{"stock": 1}, "removedFields": []string{"brand"}}
+	before, after, evs, complete := changeEvents(decodeChange(t, update))
+	assert.True(t, complete)
+	assert.Equal(t, 3, before.Stock)
+	assert.Equal(t, []string{domain.EventProductPatched, domain.EventStockChanged}, eventNames(evs))
+	patched := evs[0].(domain.ProductPatched)
+	assert.Contains(t, patched.Fields, "stock")
+	assert.Contains(t, patched.Fields, "brand")
+	assert.Equal(t, id.Hex(), evs[1].(domain.StockChanged).ProductID)
+
+	// Sin pre-imagen no se sabe el stock anterior: solo product.patched y se pide resincronizar.
+	delete(update, "fullDocumentBeforeChange")
+	_, _, evs, complete = changeEvents(decodeChange(t, update))
+	assert.False(t, complete)
+	assert.Equal(t, []string{domain.EventProductPatched}, eventNames(evs))
+
+	replace := base("replace")
+	replace["fullDocumentBeforeChange"] = product(3, 10)
+	replace["fullDocument"] = product(3, 12)
+	_, _, evs, _ = changeEvents(decodeChange(t, replace))
+	assert.Equal(t, []string{domain.EventProductUpdated, domain.EventPriceChanged}, eventNames(evs))
+
+	del := base("delete")
+	_, _, evs, complete = changeEvents(decodeChange(t, del))
+	assert.False(t, complete)
+	assert.Equal(t, id.Hex(), evs[0].(domain.ProductDeleted).Product.ID)
+
+	// Un update cuyo documento ya fue borrado no publica nada; llegará el delete.
+	gone := base("update")
+	_, _, evs, _ = changeEvents(decodeChange(t, gone))
+	assert.Empty(t, evs)
+
+	assert.Equal(t, "8266", tokenData(decodeChange(t, insert).ID))
+}
+
+func eventNames(evs []events.Event) []string {
+	var out []string
+	for _, e := range evs {
+		out = append(out, e.EventName())
+	}
+	return out
+}
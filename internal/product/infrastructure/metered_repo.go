@@ -0,0 +1,120 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"mlsport/internal/product/domain"
+	"time"
+)
+
+// MongoObserver recibe la duración de cada operación del repositorio y si falló.
+type MongoObserver interface {
+	ObserveMongo(method string, elapsed time.Duration, failed bool)
+}
+
+// MeteredProductRepo decora un ProductRepository midiendo cada método. Va debajo de la caché, así
+// solo cuenta las consultas que llegan a Mongo. Un producto inexistente no cuenta como error.
+type MeteredProductRepo struct {
+	Repo     domain.ProductRepository
+	Observer MongoObserver
+}
+
+func NewMeteredProductRepo(repo domain.ProductRepository, observer MongoObserver) *MeteredProductRepo {
+	return &MeteredProductRepo{Repo: repo, Observer: observer}
+}
+
+func (r *MeteredProductRepo) observe(method string, start time.Time, err error) {
+	failed := err != nil && !errors.Is(err, domain.ErrProductNotFound)
+	r.Observer.ObserveMongo(method, time.Since(start), failed)
+}
+
+func (r *MeteredProductRepo) Create(ctx context.Context, product *domain.Product) error {
+	start := time.Now()
+	err := r.Repo.Create(ctx, product)
+	r.observe("Create", start, err)
+	return err
+}
+
+func (r *MeteredProductRepo) FindAll(ctx context.Context) ([]domain.Product, error) {
+	start := time.Now()
+	list, err := r.Repo.FindAll(ctx)
+	r.observe("FindAll", start, err)
+	return list, err
+}
+
+func (r *MeteredProductRepo) Find(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, error) {
+	start := time.Now()
+	list, err := r.Repo.Find(ctx, filter)
+	r.observe("Find", start, err)
+	return list, err
+}
+
+func (r *MeteredProductRepo) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	start := time.Now()
+	p, err := r.Repo.FindByID(ctx, id)
+	r.observe("FindByID", start, err)
+	return p, err
+}
+
+func (r *MeteredProductRepo) FindByCategory(ctx context.Context, categories ...string) ([]domain.Product, error) {
+	start := time.Now()
+	list, err := r.Repo.FindByCategory(ctx, categories...)
+	r.observe("FindByCategory", start, err)
+	return list, err
+}
+
+func (r *MeteredProductRepo) Update(ctx context.Context, product *domain.Product) error {
+	start := time.Now()
+	err := r.Repo.Update(ctx, product)
+	r.observe("Update", start, err)
+	return err
+}
+
+func (r *MeteredProductRepo) Patch(ctx context.Context, id string, fields map[string]interface{}) error {
+	start := time.Now()
+	err := r.Repo.Patch(ctx, id, fields)
+	r.observe("Patch", start, err)
+	return err
+}
+
+func (r *MeteredProductRepo) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.Repo.Delete(ctx, id)
+	r.observe("Delete", start, err)
+	return err
+}
+
+func (r *MeteredProductRepo) GetMetrics(ctx context.Context, query domain.MetricsQuery) (map[string]interface{}, error) {
+	start := time.Now()
+	metrics, err := r.Repo.GetMetrics(ctx, query)
+	r.observe("GetMetrics", start, err)
+	return metrics, err
+}
+
+func (r *MeteredProductRepo) GetPriceDistribution(ctx context.Context, query domain.PriceQuery) (*domain.PriceDistribution, error) {
+	start := time.Now()
+	dist, err := r.Repo.GetPriceDistribution(ctx, query)
+	r.observe("GetPriceDistribution", start, err)
+	return dist, err
+}
+
+func (r *MeteredProductRepo) FindLowStock(ctx context.Context, defaultThreshold int) ([]domain.Product, error) {
+	start := time.Now()
+	list, err := r.Repo.FindLowStock(ctx, defaultThreshold)
+	r.observe("FindLowStock", start, err)
+	return list, err
+}
+
+func (r *MeteredProductRepo) GetCategories(ctx context.Context) ([]string, error) {
+	start := time.Now()
+	list, err := r.Repo.GetCategories(ctx)
+	r.observe("GetCategories", start, err)
+	return list, err
+}
+
+func (r *MeteredProductRepo) FindRecent(ctx context.Context, limit int) ([]domain.Product, error) {
+	start := time.Now()
+	list, err := r.Repo.FindRecent(ctx, limit)
+	r.observe("FindRecent", start, err)
+	return list, err
+}
@@ -0,0 +1,56 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mlsport/internal/product/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingRepo struct {
+	domain.ProductRepository
+}
+
+func (failingRepo) FindAll(ctx context.Context) ([]domain.Product, error) {
+	return nil, errors.New("mongo caído")
+}
+
+func (failingRepo) Delete(ctx context.Context, id string) error {
+	return domain.ErrProductNotFound
+}
+
+type observation struct {
+	method string
+	failed bool
+}
+
+type recordingObserver []observation
+
+func (o *recordingObserver) ObserveMongo(method string, elapsed time.Duration, failed bool) {
+	*o = append(*o, observation{method, failed})
+}
+
+func TestMeteredRepo_ObservesEachMethod(t *testing.T) {
+	var observed recordingObserver
+	repo := NewMeteredProductRepo(&countingRepo{}, &observed)
+
+	_, _ = repo.GetMetrics(context.Background(), domain.MetricsQuery{})
+	_ = repo.Patch(context.Background(), "1", map[string]interface{}{"stock": 1})
+
+	failing := NewMeteredProductRepo(failingRepo{}, &observed)
+	_, err := failing.FindAll(context.Background())
+	assert.Error(t, err)
+	// Un producto inexistente es un resultado, no una falla de Mongo.
+	assert.ErrorIs(t, failing.Delete(context.Background(), "x"), domain.ErrProductNotFound)
+
+	assert.Equal(t, recordingObserver{
+		{"GetMetrics", false},
+		{"Patch", false},
+		{"FindAll", true},
+		{"Delete", false},
+	}, observed)
+}
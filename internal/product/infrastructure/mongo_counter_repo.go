@@ -0,0 +1,166 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mlsport/config"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const countersDocumentID = "products"
+
+// MongoCounterRepo guarda los contadores de cada tienda en un documento actualizado con $inc.
+type MongoCounterRepo struct {
+	CollectionName         string
+	ProductsCollectionName string
+}
+
+func NewMongoCounterRepo() *MongoCounterRepo {
+	return &MongoCounterRepo{CollectionName: "metrics_counters", ProductsCollectionName: "products"}
+}
+
+// documentID conserva el documento anterior a los tenants para la tienda por defecto.
+func documentID(tenantID string) string {
+	if tenantID == tenant.Default {
+		return countersDocumentID
+	}
+	return countersDocumentID + ":" + tenantID
+}
+
+func (r *MongoCounterRepo) Get(tenantID string) (*domain.MetricsCounters, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var c domain.MetricsCounters
+	collection := config.GetDB().Collection(r.CollectionName)
+	err := collection.FindOne(ctx, bson.M{"_id": documentID(tenantID)}).Decode(&c)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return &domain.MetricsCounters{Categories: map[string]int{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Categories == nil {
+		c.Categories = map[string]int{}
+	}
+	for k, v := range c.Categories {
+		if v == 0 {
+			delete(c.Categories, k)
+		}
+	}
+	return &c, nil
+}
+
+func (r *MongoCounterRepo) Apply(tenantID string, d domain.CounterDelta) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.apply(ctx, tenantID, d)
+}
+
+// apply suma d con $inc usando ctx, que puede traer la transacción de la escritura del producto.
+func (r *MongoCounterRepo) apply(ctx context.Context, tenantID string, d domain.CounterDelta) error {
+	inc := bson.M{
+		"total_products": d.Products,
+		"total_stock":    d.Stock,
+		"price_sum":      d.PriceSum,
+	}
+	for k, v := range d.Categories {
+		inc["categories."+k] = v
+	}
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"_id": documentID(tenantID)},
+		bson.M{"$inc": inc, "$currentDate": bson.M{"updated_at": true}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// Rebuild calcula y reemplaza en una transacción. Si una escritura de productos confirma su $inc
+// después de que la agregación tomó su snapshot, el reemplazo choca con ella (write conflict) y la
+// transacción se reintenta con los datos nuevos; si confirma después, su $inc se suma a lo
+// reconstruido, que no la incluía. Sin transacciones (ver config.TransactionsSupported) esa
+// carrera sigue abierta.
+func (r *MongoCounterRepo) Rebuild(tenantID string) (*domain.MetricsCounters, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var live *domain.MetricsCounters
+	err := config.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if live, err = r.compute(ctx, tenantID); err != nil {
+			return err
+		}
+		collection := config.GetDB().Collection(r.CollectionName)
+		_, err = collection.ReplaceOne(ctx, bson.M{"_id": documentID(tenantID)}, live, options.Replace().SetUpsert(true))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return live, nil
+}
+
+func (r *MongoCounterRepo) Compute(tenantID string) (*domain.MetricsCounters, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return r.compute(ctx, tenantID)
+}
+
+// compute agrega los productos de la tienda con ctx, que puede traer la transacción de Rebuild.
+func (r *MongoCounterRepo) compute(ctx context.Context, tenantID string) (*domain.MetricsCounters, error) {
+	coll := config.GetDB().Collection(r.ProductsCollectionName)
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"tenant": tenantID}},
+		{
+			"$group": bson.M{
+				"_id":       "$category",
+				"count":     bson.M{"$sum": 1},
+				"stock":     bson.M{"$sum": "$stock"},
+				"price_sum": bson.M{"$sum": "$price"},
+			},
+		},
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	var groups []struct {
+		Category string  `bson:"_id"`
+		Count    int     `bson:"count"`
+		Stock    int     `bson:"stock"`
+		PriceSum float64 `bson:"price_sum"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	c := &domain.MetricsCounters{Categories: map[string]int{}, UpdatedAt: time.Now().UTC()}
+	for _, g := range groups {
+		c.TotalProducts += g.Count
+		c.TotalStock += g.Stock
+		c.PriceSum += g.PriceSum
+		c.Categories[domain.CounterKey(g.Category)] += g.Count
+	}
+	return c, nil
+}
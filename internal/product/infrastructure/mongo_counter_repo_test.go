@@ -0,0 +1,38 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCounterRebuild_ReadsAndReplacesInOneTransaction(t *testing.T) {
+	newMock(t, "rebuild", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "mlsport_test.products", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: "futbol"}, {Key: "count", Value: 2}, {Key: "stock", Value: 7}, {Key: "price_sum", Value: 300.0}},
+			),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		c, err := NewMongoCounterRepo().Rebuild(tenant.Default)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, c.TotalProducts)
+		assert.Equal(t, 2, c.Categories["futbol"])
+
+		aggregate, update := commands(mt, "aggregate"), commands(mt, "update")
+		if assert.Len(t, aggregate, 1) && assert.Len(t, update, 1) {
+			txn, err := aggregate[0].LookupErr("txnNumber")
+			assert.NoError(t, err, "la agregación debe ir en la transacción")
+			assert.Equal(t, txn, update[0].Lookup("txnNumber"))
+			assert.Equal(t, aggregate[0].Lookup("lsid"), update[0].Lookup("lsid"))
+		}
+		assert.Len(t, commands(mt, "commitTransaction"), 1)
+	})
+}
@@ -0,0 +1,112 @@
+package infrastructure
+
+import (
+	"context"
+	"mlsport/config"
+	"mlsport/internal/product/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultOutboxRetention es cuánto se conservan las entradas ya entregadas antes de que las borre el índice TTL.
+const DefaultOutboxRetention = 7 * 24 * time.Hour
+
+// MongoOutboxRepo guarda los eventos pendientes de publicar. MongoProductRepo inserta las entradas
+// dentro de la transacción de la escritura; el relay las lee y marca desde aquí.
+type MongoOutboxRepo struct {
+	CollectionName string
+	Retention      time.Duration
+}
+
+type outboxDocument struct {
+	ObjectID           primitive.ObjectID `bson:"_id,omitempty"`
+	domain.OutboxEntry `bson:",inline"`
+}
+
+func NewMongoOutboxRepo() *MongoOutboxRepo {
+	return &MongoOutboxRepo{CollectionName: "outbox", Retention: DefaultOutboxRetention}
+}
+
+// EnsureIndexes crea el índice de la consulta de pendientes y el TTL de las entradas entregadas.
+func (r *MongoOutboxRepo) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "delivered_at", Value: 1}, {Key: "dead_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "delivered_at", Value: 1}},
+			Options: options.Index().SetName("delivered_at_ttl").SetExpireAfterSeconds(int32(r.Retention.Seconds())),
+		},
+	})
+	return err
+}
+
+// insert guarda los eventos con el ctx de la transacción de quien escribe el producto.
+func (r *MongoOutboxRepo) insert(ctx context.Context, entries []domain.OutboxEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(entries))
+	for i, e := range entries {
+		docs[i] = outboxDocument{OutboxEntry: e}
+	}
+	_, err := config.GetDB().Collection(r.CollectionName).InsertMany(ctx, docs)
+	return err
+}
+
+func (r *MongoOutboxRepo) Pending(ctx context.Context, limit int) ([]domain.OutboxEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"delivered_at": bson.M{"$exists": false},
+		"dead_at":      bson.M{"$exists": false},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := config.GetDB().Collection(r.CollectionName).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []outboxDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	entries := make([]domain.OutboxEntry, len(docs))
+	for i, d := range docs {
+		entries[i] = d.OutboxEntry
+		entries[i].ID = d.ObjectID.Hex()
+	}
+	return entries, nil
+}
+
+func (r *MongoOutboxRepo) MarkDelivered(ctx context.Context, id string, at time.Time) error {
+	return r.set(ctx, id, bson.M{"delivered_at": at})
+}
+
+func (r *MongoOutboxRepo) MarkFailed(ctx context.Context, id string, attempts int, retryAt time.Time, lastErr string) error {
+	return r.set(ctx, id, bson.M{"attempts": attempts, "next_attempt_at": retryAt, "last_error": lastErr})
+}
+
+func (r *MongoOutboxRepo) MarkDead(ctx context.Context, id string, attempts int, lastErr string) error {
+	return r.set(ctx, id, bson.M{"attempts": attempts, "last_error": lastErr, "dead_at": time.Now().UTC()})
+}
+
+func (r *MongoOutboxRepo) set(ctx context.Context, id string, fields bson.M) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = config.GetDB().Collection(r.CollectionName).UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": fields})
+	return err
+}
@@ -2,50 +2,213 @@ package infrastructure
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"mlsport/config"
+	"mlsport/internal/logging"
 	"mlsport/internal/product/domain"
+	"mlsport/internal/slug"
+	"mlsport/internal/tenant"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// MongoProductRepo guarda los productos de todas las tiendas en una colección; cada consulta y
+// escritura se limita a la tienda del contexto (tenant.ID), así una tienda no ve ni modifica
+// productos de otra aunque conozca sus IDs.
 type MongoProductRepo struct {
 	CollectionName string
+	// Outbox, si está configurado, recibe los eventos de cada escritura en la misma transacción
+	// que el cambio del producto. OnOutbox se llama después de confirmar, para despertar al relay.
+	Outbox   *MongoOutboxRepo
+	OnOutbox func()
+	// Counters, si está configurado, recibe con $inc la diferencia de cada escritura en la misma
+	// transacción, calculada con las lecturas de antes y después hechas dentro de ella: dos
+	// escrituras concurrentes no pueden contar dos veces el mismo estado.
+	Counters *MongoCounterRepo
 }
 
 func NewMongoProductRepo() *MongoProductRepo {
 	return &MongoProductRepo{CollectionName: "products"}
 }
 
-func (r *MongoProductRepo) Create(p *domain.Product) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// EnsureIndexes crea los índices por tienda que usan los listados y filtros.
+func (r *MongoProductRepo) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	collection := config.GetDB().Collection(r.CollectionName)
-	res, err := collection.InsertOne(ctx, p)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "category", Value: 1}}},
+		{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "brand", Value: 1}}},
+		{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "updated_at", Value: -1}}},
+	})
+	return err
+}
+
+// AssignDefaultTenant pasa a la tienda por defecto los productos creados antes de los tenants.
+func (r *MongoProductRepo) AssignDefaultTenant(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	res, err := collection.UpdateMany(ctx,
+		bson.M{"tenant": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"tenant": tenant.Default}},
+	)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// SlugifyCategories migra una sola vez los productos cargados antes de administrar las categorías,
+// con la categoría en texto libre ("Fútbol "), a su slug ("futbol"), que es lo que buscan los
+// filtros. Los valores que no producen slug se dejan como están. Devuelve cuántos cambió.
+func (r *MongoProductRepo) SlugifyCategories(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	values, err := collection.Distinct(ctx, "category", bson.M{})
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, v := range values {
+		label, ok := v.(string)
+		if !ok {
+			continue
+		}
+		s := slug.Make(label)
+		if s == "" || s == label {
+			continue
+		}
+		res, err := collection.UpdateMany(ctx, bson.M{"category": label}, bson.M{"$set": bson.M{"category": s}})
+		if err != nil {
+			return total, err
+		}
+		total += res.ModifiedCount
+	}
+	return total, nil
+}
+
+func (r *MongoProductRepo) Create(ctx context.Context, p *domain.Product) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	p.UpdatedAt = time.Now().UTC()
+	p.Tenant = tenant.ID(ctx)
+	collection := config.GetDB().Collection(r.CollectionName)
+	return r.write(ctx, func(ctx context.Context) (written, error) {
+		res, err := collection.InsertOne(ctx, p)
+		if err != nil {
+			return written{}, err
+		}
+
+		p.ID = res.InsertedID.(primitive.ObjectID).Hex()
+		after := *p
+		return written{after: &after, at: p.UpdatedAt}, nil
+	})
+}
+
+func (r *MongoProductRepo) FindAll(ctx context.Context) ([]domain.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result []domain.Product
+	collection := config.GetDB().Collection(r.CollectionName)
+	cursor, err := collection.Find(ctx, scoped(ctx, bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var p domain.Product
+		if err := cursor.Decode(&p); err != nil {
+			continue
+		}
+		p.ID = p.ObjectID.Hex()
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+func (r *MongoProductRepo) Find(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result []domain.Product
+	collection := config.GetDB().Collection(r.CollectionName)
+	cursor, err := collection.Find(ctx, scoped(ctx, filterQuery(filter)))
+	if err != nil {
+		return nil, err
 	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
 
-	p.ID = res.InsertedID.(primitive.ObjectID).Hex()
-	return nil
+	for cursor.Next(ctx) {
+		var p domain.Product
+		if err := cursor.Decode(&p); err != nil {
+			continue
+		}
+		p.ID = p.ObjectID.Hex()
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+// filterQuery compara las marcas tal como están guardadas, sin collation, para que las consultas usen
+// los índices tenant+brand y tenant+category: las marcas ya llegan normalizadas (ProductService al
+// escribir y al filtrar, y CanonicalizeProducts para los productos anteriores).
+func filterQuery(f domain.ProductFilter) bson.M {
+	query := bson.M{}
+	if len(f.Categories) > 0 {
+		query["category"] = bson.M{"$in": f.Categories}
+	}
+	if len(f.Brands) > 0 {
+		query["brand"] = bson.M{"$in": f.Brands}
+	}
+	return query
 }
 
-func (r *MongoProductRepo) FindAll() ([]domain.Product, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// scoped limita query a la tienda del contexto.
+func scoped(ctx context.Context, query bson.M) bson.M {
+	query["tenant"] = tenant.ID(ctx)
+	return query
+}
+
+func (r *MongoProductRepo) FindLowStock(ctx context.Context, defaultThreshold int) ([]domain.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	var result []domain.Product
 	collection := config.GetDB().Collection(r.CollectionName)
-	cursor, err := collection.Find(ctx, bson.M{})
+	opts := options.Find().SetSort(bson.D{{Key: "stock", Value: 1}, {Key: "name", Value: 1}})
+	cursor, err := collection.Find(ctx, scoped(ctx, lowStockQuery(defaultThreshold)), opts)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
 		if err := cursor.Close(ctx); err != nil {
-			log.Printf("Error closing cursor: %v", err)
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
 		}
 	}()
 
@@ -61,8 +224,20 @@ func (r *MongoProductRepo) FindAll() ([]domain.Product, error) {
 	return result, nil
 }
 
-func (r *MongoProductRepo) FindByID(id string) (*domain.Product, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// lowStockQuery compara el stock con el umbral propio del producto o, si no tiene, con defaultThreshold.
+func lowStockQuery(defaultThreshold int) bson.M {
+	return bson.M{"$expr": bson.M{"$lte": bson.A{
+		"$stock",
+		bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$reorder_threshold", 0}},
+			"$reorder_threshold",
+			defaultThreshold,
+		}},
+	}}}
+}
+
+func (r *MongoProductRepo) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -72,7 +247,7 @@ func (r *MongoProductRepo) FindByID(id string) (*domain.Product, error) {
 
 	var p domain.Product
 	collection := config.GetDB().Collection(r.CollectionName)
-	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&p)
+	err = collection.FindOne(ctx, scoped(ctx, bson.M{"_id": objID})).Decode(&p)
 	if err != nil {
 		return nil, err
 	}
@@ -81,19 +256,19 @@ func (r *MongoProductRepo) FindByID(id string) (*domain.Product, error) {
 	return &p, nil
 }
 
-func (r *MongoProductRepo) FindByCategory(cat string) ([]domain.Product, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *MongoProductRepo) FindByCategory(ctx context.Context, categories ...string) ([]domain.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	var result []domain.Product
 	collection := config.GetDB().Collection(r.CollectionName)
-	cursor, err := collection.Find(ctx, bson.M{"category": cat})
+	cursor, err := collection.Find(ctx, scoped(ctx, bson.M{"category": bson.M{"$in": categories}}))
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
 		if err := cursor.Close(ctx); err != nil {
-			log.Printf("Error closing cursor: %v", err)
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
 		}
 	}()
 
@@ -109,13 +284,14 @@ func (r *MongoProductRepo) FindByCategory(cat string) ([]domain.Product, error)
 	return result, nil
 }
 
-func (r *MongoProductRepo) GetCategories() ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *MongoProductRepo) GetCategories(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	coll := config.GetDB().Collection(r.CollectionName)
 
 	pipeline := []bson.M{
+		{"$match": scoped(ctx, bson.M{})},
 		{"$group": bson.M{"_id": "$category"}},
 		{"$sort": bson.M{"_id": 1}},
 	}
@@ -126,7 +302,7 @@ func (r *MongoProductRepo) GetCategories() ([]string, error) {
 	}
 	defer func() {
 		if err := cursor.Close(ctx); err != nil {
-			log.Printf("Error closing cursor: %v", err)
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
 		}
 	}()
 
@@ -145,8 +321,37 @@ func (r *MongoProductRepo) GetCategories() ([]string, error) {
 	return categories, nil
 }
 
-func (r *MongoProductRepo) Update(p *domain.Product) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *MongoProductRepo) FindRecent(ctx context.Context, limit int) ([]domain.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result []domain.Product
+	collection := config.GetDB().Collection(r.CollectionName)
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, scoped(ctx, bson.M{"updated_at": bson.M{"$exists": true}}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var p domain.Product
+		if err := cursor.Decode(&p); err != nil {
+			continue
+		}
+		p.ID = p.ObjectID.Hex()
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+func (r *MongoProductRepo) Update(ctx context.Context, p *domain.Product) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(p.ID)
@@ -154,13 +359,31 @@ func (r *MongoProductRepo) Update(p *domain.Product) error {
 		return err
 	}
 
+	p.UpdatedAt = time.Now().UTC()
+	p.Tenant = tenant.ID(ctx)
 	collection := config.GetDB().Collection(r.CollectionName)
-	_, err = collection.ReplaceOne(ctx, bson.M{"_id": objID}, p)
-	return err
+	return r.write(ctx, func(ctx context.Context) (written, error) {
+		before, err := r.snapshot(ctx, objID)
+		if err != nil {
+			return written{}, err
+		}
+		res, err := collection.ReplaceOne(ctx, scoped(ctx, bson.M{"_id": objID}), p)
+		if err != nil {
+			return written{}, err
+		}
+		if res.MatchedCount == 0 {
+			return written{}, domain.ErrProductNotFound
+		}
+		if before == nil {
+			return written{}, nil
+		}
+		after := *p
+		return written{before: before, after: &after, at: p.UpdatedAt}, nil
+	})
 }
 
-func (r *MongoProductRepo) Patch(id string, fields map[string]interface{}) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *MongoProductRepo) Patch(ctx context.Context, id string, fields map[string]interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -168,13 +391,41 @@ func (r *MongoProductRepo) Patch(id string, fields map[string]interface{}) error
 		return err
 	}
 
+	now := time.Now().UTC()
+	set := bson.M{"updated_at": now}
+	for k, v := range fields {
+		// La tienda y el ID no se pueden cambiar con un PATCH.
+		if k != "updated_at" && k != "tenant" && k != "_id" {
+			set[k] = v
+		}
+	}
+
 	collection := config.GetDB().Collection(r.CollectionName)
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": fields})
-	return err
+	return r.write(ctx, func(ctx context.Context) (written, error) {
+		before, err := r.snapshot(ctx, objID)
+		if err != nil {
+			return written{}, err
+		}
+		res, err := collection.UpdateOne(ctx, scoped(ctx, bson.M{"_id": objID}), bson.M{"$set": set})
+		if err != nil {
+			return written{}, err
+		}
+		if res.MatchedCount == 0 {
+			return written{}, domain.ErrProductNotFound
+		}
+		if before == nil {
+			return written{}, nil
+		}
+		after, err := r.snapshot(ctx, objID)
+		if err != nil || after == nil {
+			return written{}, err
+		}
+		return written{before: before, after: after, fields: fields, at: now}, nil
+	})
 }
 
-func (r *MongoProductRepo) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *MongoProductRepo) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -183,23 +434,196 @@ func (r *MongoProductRepo) Delete(id string) error {
 	}
 
 	collection := config.GetDB().Collection(r.CollectionName)
-	_, err = collection.DeleteOne(ctx, bson.M{"_id": objID})
+	return r.write(ctx, func(ctx context.Context) (written, error) {
+		before, err := r.snapshot(ctx, objID)
+		if err != nil {
+			return written{}, err
+		}
+		res, err := collection.DeleteOne(ctx, scoped(ctx, bson.M{"_id": objID}))
+		if err != nil {
+			return written{}, err
+		}
+		if res.DeletedCount == 0 {
+			return written{}, domain.ErrProductNotFound
+		}
+		return written{before: before, at: time.Now().UTC()}, nil
+	})
+}
+
+// written describe el efecto de una escritura: el producto antes y después (nil = no existía o
+// se borró) y los campos de un PATCH. Queda vacío si no hubo lecturas (sin Outbox ni Counters).
+type written struct {
+	before, after *domain.Product
+	fields        map[string]interface{}
+	at            time.Time
+}
+
+// write ejecuta fn y, en la misma transacción, suma su diferencia a Counters y guarda sus eventos
+// en Outbox: o se confirman el cambio, los contadores y los eventos, o nada.
+func (r *MongoProductRepo) write(ctx context.Context, fn func(ctx context.Context) (written, error)) error {
+	if r.Outbox == nil && r.Counters == nil {
+		_, err := fn(ctx)
+		return err
+	}
+
+	err := config.WithTransaction(ctx, func(ctx context.Context) error {
+		w, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		return r.record(ctx, w)
+	})
+	if err == nil && r.Outbox != nil && r.OnOutbox != nil {
+		r.OnOutbox()
+	}
 	return err
 }
 
-func (r *MongoProductRepo) GetMetrics() (map[string]interface{}, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// record suma a Counters la diferencia de las escrituras y guarda sus eventos en Outbox, en la
+// transacción de ctx.
+func (r *MongoProductRepo) record(ctx context.Context, ws ...written) error {
+	if r.Counters != nil {
+		var delta domain.CounterDelta
+		for _, w := range ws {
+			delta = delta.Plus(domain.ChangeDelta(w.before, w.after))
+		}
+		if !delta.IsZero() {
+			if err := r.Counters.apply(ctx, tenant.ID(ctx), delta); err != nil {
+				return err
+			}
+		}
+	}
+	if r.Outbox == nil {
+		return nil
+	}
+
+	var entries []domain.OutboxEntry
+	now := time.Now().UTC()
+	for _, w := range ws {
+		for _, e := range domain.ProductEventsFor(w.before, w.after, w.fields, w.at) {
+			entry, err := domain.NewOutboxEntry(e, now)
+			if err != nil {
+				return err
+			}
+			entry.Tenant = tenant.ID(ctx)
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.Outbox.insert(ctx, entries)
+}
+
+// RelabelCategories pasa a to los productos de la tienda de ctx con categoría en from, dentro de la
+// transacción de quien llama (renombrar o fusionar categorías). Devuelve cuántos productos cambió.
+func (r *MongoProductRepo) RelabelCategories(ctx context.Context, from []string, to string) (int64, error) {
+	return r.relabel(ctx, "category", from, to, func(p *domain.Product) { p.Category = to })
+}
+
+// RelabelBrand pasa a to los productos de la tienda de ctx con la marca from, dentro de la
+// transacción de quien llama (renombrar una marca). Devuelve cuántos productos cambió.
+func (r *MongoProductRepo) RelabelBrand(ctx context.Context, from, to string) (int64, error) {
+	return r.relabel(ctx, "brand", []string{from}, to, func(p *domain.Product) { p.Brand = to })
+}
+
+// relabel cambia field de los valores de from a to con un único UpdateMany. Como cualquier
+// escritura, suma la diferencia a Counters y deja en Outbox un ProductPatched por producto, así el
+// stream y los webhooks se enteran; sin Outbox no se publican eventos.
+func (r *MongoProductRepo) relabel(ctx context.Context, field string, from []string, to string, set func(*domain.Product)) (int64, error) {
+	collection := config.GetDB().Collection(r.CollectionName)
+	filter := scoped(ctx, bson.M{field: bson.M{"$in": from, "$ne": to}})
+
+	var before []domain.Product
+	if r.Outbox != nil || r.Counters != nil {
+		cursor, err := collection.Find(ctx, filter)
+		if err != nil {
+			return 0, err
+		}
+		if err := cursor.All(ctx, &before); err != nil {
+			return 0, err
+		}
+	}
+
+	res, err := collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{field: to}})
+	if err != nil {
+		return 0, err
+	}
+
+	at := time.Now().UTC()
+	ws := make([]written, 0, len(before))
+	for i := range before {
+		b := &before[i]
+		b.ID = b.ObjectID.Hex()
+		after := *b
+		set(&after)
+		ws = append(ws, written{before: b, after: &after, fields: map[string]interface{}{field: to}, at: at})
+	}
+	if err := r.record(ctx, ws...); err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// snapshot lee el producto dentro de la transacción; devuelve nil si no existe.
+func (r *MongoProductRepo) snapshot(ctx context.Context, objID primitive.ObjectID) (*domain.Product, error) {
+	if r.Outbox == nil && r.Counters == nil {
+		return nil, nil
+	}
+	var p domain.Product
+	err := config.GetDB().Collection(r.CollectionName).FindOne(ctx, scoped(ctx, bson.M{"_id": objID})).Decode(&p)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.ID = p.ObjectID.Hex()
+	return &p, nil
+}
+
+func (r *MongoProductRepo) GetMetrics(ctx context.Context, q domain.MetricsQuery) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	coll := config.GetDB().Collection(r.CollectionName)
 
+	stats := bson.M{
+		"count":         bson.M{"$sum": 1},
+		"stock":         bson.M{"$sum": "$stock"},
+		"stock_value":   bson.M{"$sum": bson.M{"$multiply": bson.A{"$price", "$stock"}}},
+		"min_price":     bson.M{"$min": "$price"},
+		"max_price":     bson.M{"$max": "$price"},
+		"average_price": bson.M{"$avg": "$price"},
+	}
+	breakdown := func(field string) bson.A {
+		group := bson.M{"_id": field}
+		for k, v := range stats {
+			group[k] = v
+		}
+		return bson.A{
+			bson.M{"$group": group},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": q.TopN},
+		}
+	}
+	totals := bson.M{"_id": nil}
+	for k, v := range stats {
+		totals[k] = v
+	}
+
+	// Un solo recorrido de la colección: totales y desgloses por categoría y marca.
 	pipeline := []bson.M{
+		{"$match": scoped(ctx, filterQuery(q.Filter))},
 		{
-			"$group": bson.M{
-				"_id":           nil,
-				"total":         bson.M{"$sum": 1},
-				"stock":         bson.M{"$sum": "$stock"},
-				"average_price": bson.M{"$avg": "$price"},
+			"$facet": bson.M{
+				"totals":     bson.A{bson.M{"$group": totals}},
+				"categories": breakdown("$category"),
+				"brands":     breakdown("$brand"),
+				"low_stock": bson.A{
+					bson.M{"$match": lowStockQuery(q.LowStockThreshold)},
+					bson.M{"$count": "count"},
+				},
 			},
 		},
 	}
@@ -210,54 +634,217 @@ func (r *MongoProductRepo) GetMetrics() (map[string]interface{}, error) {
 	}
 	defer func() {
 		if err := cursor.Close(ctx); err != nil {
-			log.Printf("Error closing cursor: %v", err)
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
 		}
 	}()
 
-	var result []bson.M
-	if err := cursor.All(ctx, &result); err != nil || len(result) == 0 {
+	var result []struct {
+		Totals     []domain.MetricsBreakdown `bson:"totals"`
+		Categories []domain.MetricsBreakdown `bson:"categories"`
+		Brands     []domain.MetricsBreakdown `bson:"brands"`
+		LowStock   []struct {
+			Count int `bson:"count"`
+		} `bson:"low_stock"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
 		return nil, err
 	}
 
-	topCatPipeline := []bson.M{
+	var total domain.MetricsBreakdown
+	lowStock := 0
+	categories := []domain.MetricsBreakdown{}
+	brands := []domain.MetricsBreakdown{}
+	if len(result) > 0 {
+		if len(result[0].Totals) > 0 {
+			total = result[0].Totals[0]
+		}
+		categories = append(categories, result[0].Categories...)
+		brands = append(brands, result[0].Brands...)
+		if len(result[0].LowStock) > 0 {
+			lowStock = result[0].LowStock[0].Count
+		}
+	}
+
+	topCategories := []string{}
+	for _, cat := range categories {
+		topCategories = append(topCategories, cat.Name)
+	}
+
+	data := map[string]interface{}{
+		"total_products": total.Count,
+		"total_stock":    total.Stock,
+		"average_price":  total.AvgPrice,
+		"stock_value":    total.StockValue,
+		"min_price":      total.MinPrice,
+		"max_price":      total.MaxPrice,
+		"top_categories": topCategories,
+		"categories":     categories,
+		"brands":         brands,
+		"low_stock":      lowStock,
+	}
+
+	return data, nil
+}
+
+func (r *MongoProductRepo) GetPriceDistribution(ctx context.Context, q domain.PriceQuery) (*domain.PriceDistribution, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	coll := config.GetDB().Collection(r.CollectionName)
+
+	var histogram bson.M
+	if len(q.Boundaries) > 0 {
+		histogram = bson.M{"$bucket": bson.M{
+			"groupBy":    "$price",
+			"boundaries": q.Boundaries,
+			"default":    "otros",
+			"output":     bson.M{"count": bson.M{"$sum": 1}},
+		}}
+	} else {
+		histogram = bson.M{"$bucketAuto": bson.M{
+			"groupBy": "$price",
+			"buckets": q.Buckets,
+			"output":  bson.M{"count": bson.M{"$sum": 1}},
+		}}
+	}
+
+	pipeline := []bson.M{
+		{"$match": scoped(ctx, filterQuery(q.Filter))},
 		{
-			"$group": bson.M{
-				"_id":   "$category",
-				"count": bson.M{"$sum": 1},
+			"$facet": bson.M{
+				"stats": bson.A{bson.M{"$group": bson.M{
+					"_id":     nil,
+					"count":   bson.M{"$sum": 1},
+					"min":     bson.M{"$min": "$price"},
+					"max":     bson.M{"$max": "$price"},
+					"average": bson.M{"$avg": "$price"},
+				}}},
+				"percentiles": percentileStages(),
+				"histogram":   bson.A{histogram},
 			},
 		},
-		{"$sort": bson.M{"count": -1}},
-		{"$limit": 2},
 	}
 
-	catCursor, err := coll.Aggregate(ctx, topCatPipeline)
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := coll.Aggregate(ctx, pipeline, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
-		if err := catCursor.Close(ctx); err != nil {
-			log.Printf("Error closing catCursor: %v", err)
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
 		}
 	}()
 
-	var categories []bson.M
-	if err := catCursor.All(ctx, &categories); err != nil {
+	var result []struct {
+		Stats []struct {
+			Count   int     `bson:"count"`
+			Min     float64 `bson:"min"`
+			Max     float64 `bson:"max"`
+			Average float64 `bson:"average"`
+		} `bson:"stats"`
+		Percentiles []struct {
+			Rank  int     `bson:"rank"`
+			N     int     `bson:"n"`
+			Price float64 `bson:"price"`
+		} `bson:"percentiles"`
+		Histogram []rawBucket `bson:"histogram"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
 		return nil, err
 	}
 
-	var topCategories []string
-	for _, cat := range categories {
-		if name, ok := cat["_id"].(string); ok {
-			topCategories = append(topCategories, name)
+	dist := &domain.PriceDistribution{Histogram: []domain.PriceBucket{}}
+	if len(result) == 0 {
+		return dist, nil
+	}
+
+	if len(result[0].Stats) > 0 {
+		st := result[0].Stats[0]
+		dist.Count, dist.Min, dist.Max, dist.Average = st.Count, st.Min, st.Max, st.Average
+	}
+	if ranked := result[0].Percentiles; len(ranked) > 0 {
+		prices := make(map[int]float64, len(ranked))
+		for _, r := range ranked {
+			prices[r.Rank] = r.Price
 		}
+		at := func(i int) float64 { return prices[i] }
+		n := ranked[0].N
+		dist.P25 = domain.PercentileAt(at, n, 25)
+		dist.Median = domain.PercentileAt(at, n, 50)
+		dist.P75 = domain.PercentileAt(at, n, 75)
+		dist.P90 = domain.PercentileAt(at, n, 90)
+	}
+	for _, b := range result[0].Histogram {
+		dist.Histogram = append(dist.Histogram, toPriceBucket(b, q.Boundaries))
 	}
 
-	data := map[string]interface{}{
-		"total_products": result[0]["total"],
-		"total_stock":    result[0]["stock"],
-		"average_price":  result[0]["average_price"],
-		"top_categories": topCategories,
+	return dist, nil
+}
+
+// percentileStages ordena los precios con $setWindowFields y devuelve solo los que están en las
+// posiciones que interpolan domain.PricePercentiles (a lo sumo dos por percentil), con la
+// cantidad total: los percentiles salen de unos pocos documentos en lugar de todos los precios.
+func percentileStages() bson.A {
+	var keep bson.A
+	for _, p := range domain.PricePercentiles {
+		rank := bson.M{"$multiply": bson.A{p / 100, bson.M{"$subtract": bson.A{"$n", 1}}}}
+		keep = append(keep,
+			bson.M{"$eq": bson.A{"$rank", bson.M{"$floor": rank}}},
+			bson.M{"$eq": bson.A{"$rank", bson.M{"$ceil": rank}}},
+		)
 	}
 
-	return data, nil
+	return bson.A{
+		bson.M{"$match": bson.M{"price": bson.M{"$type": "number"}}},
+		bson.M{"$setWindowFields": bson.M{
+			"sortBy": bson.M{"price": 1},
+			"output": bson.M{
+				"rank": bson.M{"$documentNumber": bson.M{}},
+				"n":    bson.M{"$count": bson.M{}, "window": bson.M{"documents": bson.A{"unbounded", "unbounded"}}},
+			},
+		}},
+		bson.M{"$set": bson.M{"rank": bson.M{"$subtract": bson.A{"$rank", 1}}}},
+		bson.M{"$match": bson.M{"$expr": bson.M{"$or": keep}}},
+		bson.M{"$project": bson.M{"_id": 0, "rank": 1, "n": 1, "price": 1}},
+	}
+}
+
+type rawBucket struct {
+	ID    bson.RawValue `bson:"_id"`
+	Count int           `bson:"count"`
+}
+
+// toPriceBucket traduce la salida de $bucket (_id = límite inferior o "otros")
+// o de $bucketAuto (_id = {min, max}).
+func toPriceBucket(raw rawBucket, boundaries []float64) domain.PriceBucket {
+	bucket := domain.PriceBucket{Count: raw.Count}
+
+	if label, ok := raw.ID.StringValueOK(); ok {
+		bucket.Label = label
+		return bucket
+	}
+
+	var bounds struct {
+		Min float64 `bson:"min"`
+		Max float64 `bson:"max"`
+	}
+	if raw.ID.Type == bsontype.EmbeddedDocument {
+		if err := raw.ID.Unmarshal(&bounds); err != nil {
+			slog.Warn("Error decoding bucket", logging.Err(err))
+		}
+		bucket.Min, bucket.Max = bounds.Min, bounds.Max
+	} else {
+		if err := raw.ID.Unmarshal(&bucket.Min); err != nil {
+			slog.Warn("Error decoding bucket", logging.Err(err))
+		}
+		for i := 0; i < len(boundaries)-1; i++ {
+			if boundaries[i] == bucket.Min {
+				bucket.Max = boundaries[i+1]
+			}
+		}
+	}
+
+	bucket.Label = fmt.Sprintf("%g - %g", bucket.Min, bucket.Max)
+	return bucket
 }
@@ -0,0 +1,148 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	"mlsport/config"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// newMock arma un Mongo simulado: cada operación consume, en orden, las respuestas que agrega el test.
+func newMock(t *testing.T, name string, fn func(mt *mtest.T)) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run(name, func(mt *mtest.T) {
+		mt.Setenv("MONGO_DB_NAME", "mlsport_test")
+		config.MongoClient = mt.Client
+		fn(mt)
+	})
+}
+
+// commands devuelve los comandos enviados con ese nombre, en orden.
+func commands(mt *mtest.T, name string) []bson.Raw {
+	var out []bson.Raw
+	for _, e := range mt.GetAllStartedEvents() {
+		if e.CommandName == name {
+			out = append(out, e.Command)
+		}
+	}
+	return out
+}
+
+func TestRelabelCategories_RecordsEventsAndCounters(t *testing.T) {
+	newMock(t, "relabel", func(mt *mtest.T) {
+		product := func(name string, price float64) bson.D {
+			return bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: name}, {Key: "category", Value: "futbol"},
+				{Key: "price", Value: price}, {Key: "stock", Value: 3}, {Key: "tenant", Value: "tienda-a"},
+			}
+		}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "mlsport_test.products", mtest.FirstBatch, product("Balón", 100), product("Guayos", 300)),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),
+		)
+		repo := NewMongoProductRepo()
+		repo.Outbox = NewMongoOutboxRepo()
+		repo.Counters = NewMongoCounterRepo()
+
+		n, err := repo.RelabelCategories(tenant.WithID(context.Background(), "tienda-a"), []string{"futbol"}, "soccer")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+
+		updates := commands(mt, "update")
+		if assert.Len(t, updates, 2) {
+			relabel := updates[0].Lookup("updates").Array().Index(0).Value().Document()
+			assert.Equal(t, "tienda-a", relabel.Lookup("q", "tenant").StringValue())
+			assert.Equal(t, "soccer", relabel.Lookup("u", "$set", "category").StringValue())
+
+			// Los productos pasan de una categoría a otra; los totales no cambian.
+			inc := updates[1].Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$inc").Document()
+			assert.Equal(t, int32(-2), inc.Lookup("categories.futbol").Int32())
+			assert.Equal(t, int32(2), inc.Lookup("categories.soccer").Int32())
+			assert.Equal(t, int32(0), inc.Lookup("total_products").Int32())
+		}
+
+		inserts := commands(mt, "insert")
+		if assert.Len(t, inserts, 1) {
+			docs, _ := inserts[0].Lookup("documents").Array().Values()
+			assert.Len(t, docs, 2)
+			for _, d := range docs {
+				assert.Equal(t, domain.EventProductPatched, d.Document().Lookup("event").StringValue())
+				assert.Equal(t, "tienda-a", d.Document().Lookup("tenant").StringValue())
+			}
+		}
+	})
+}
+
+func TestRelabelCategories_NothingToMove(t *testing.T) {
+	newMock(t, "vacío", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "mlsport_test.products", mtest.FirstBatch),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+		)
+		repo := NewMongoProductRepo()
+		repo.Outbox = NewMongoOutboxRepo()
+		repo.Counters = NewMongoCounterRepo()
+
+		n, err := repo.RelabelCategories(context.Background(), []string{"futbol"}, "soccer")
+
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+		assert.Len(t, commands(mt, "update"), 1)
+		assert.Empty(t, commands(mt, "insert"))
+	})
+}
+
+func TestRelabelBrand_RecordsEvents(t *testing.T) {
+	newMock(t, "marca", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "mlsport_test.products", mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: "Guayos"}, {Key: "brand", Value: "Nike"},
+				{Key: "category", Value: "futbol"}, {Key: "tenant", Value: "tienda-a"},
+			}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+		repo := NewMongoProductRepo()
+		repo.Outbox = NewMongoOutboxRepo()
+		repo.Counters = NewMongoCounterRepo()
+
+		n, err := repo.RelabelBrand(tenant.WithID(context.Background(), "tienda-a"), "Nike", "Nike Inc")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		// La marca no cuenta en los contadores: solo se reetiqueta y se guarda el evento.
+		updates := commands(mt, "update")
+		if assert.Len(t, updates, 1) {
+			q := updates[0].Lookup("updates").Array().Index(0).Value().Document().Lookup("q")
+			assert.Equal(t, "Nike", q.Document().Lookup("brand", "$in").Array().Index(0).Value().StringValue())
+		}
+		assert.Len(t, commands(mt, "insert"), 1)
+	})
+}
+
+func TestFind_MatchesStoredBrandWithoutCollation(t *testing.T) {
+	newMock(t, "find", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "mlsport_test.products", mtest.FirstBatch))
+
+		_, err := NewMongoProductRepo().Find(tenant.WithID(context.Background(), "tienda-a"), domain.ProductFilter{Brands: []string{"Nike"}})
+
+		assert.NoError(t, err)
+		find := commands(mt, "find")
+		if assert.Len(t, find, 1) {
+			// Con collation Mongo no podría usar el índice tenant+brand, creado sin ella.
+			_, err := find[0].LookupErr("collation")
+			assert.Error(t, err)
+			assert.Equal(t, "tienda-a", find[0].Lookup("filter", "tenant").StringValue())
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package infrastructure
+
+import (
+	"context"
+	"log/slog"
+	"mlsport/config"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoSnapshotRepo struct {
+	CollectionName string
+}
+
+func NewMongoSnapshotRepo() *MongoSnapshotRepo {
+	return &MongoSnapshotRepo{CollectionName: "metrics_snapshots"}
+}
+
+// EnsureIndexes pasa a la tienda por defecto los snapshots anteriores a los tenants y crea el
+// índice de un snapshot por tienda y día.
+func (r *MongoSnapshotRepo) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	if _, err := collection.UpdateMany(ctx,
+		bson.M{"tenant": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"tenant": tenant.Default}},
+	); err != nil {
+		return err
+	}
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *MongoSnapshotRepo) Save(ctx context.Context, s *domain.MetricsSnapshot) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	s.Tenant = tenant.ID(ctx)
+	collection := config.GetDB().Collection(r.CollectionName)
+	_, err := collection.ReplaceOne(ctx, bson.M{"tenant": s.Tenant, "date": s.Date}, s, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *MongoSnapshotRepo) FindRange(ctx context.Context, from, to time.Time) ([]domain.MetricsSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result []domain.MetricsSnapshot
+	collection := config.GetDB().Collection(r.CollectionName)
+	filter := bson.M{"tenant": tenant.ID(ctx), "date": bson.M{"$gte": from, "$lte": to}}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.M{"date": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var s domain.MetricsSnapshot
+		if err := cursor.Decode(&s); err != nil {
+			continue
+		}
+		s.ID = s.ObjectID.Hex()
+		result = append(result, s)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(a domain.LowStockAlert) error {
+	slog.Warn("⚠️ Stock bajo", "product", a.ProductID, "name", a.Name, "tenant", a.Tenant, "stock", a.Stock, "threshold", a.Threshold)
+	return nil
+}
+
+// WebhookNotifier publica la alerta como JSON en URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (n *WebhookNotifier) Notify(a domain.LowStockAlert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.Client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("Error closing webhook body", logging.Err(err))
+		}
+	}()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook respondió %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SMTPNotifier envía la alerta por correo sin autenticación, pensado para un servidor local (p. ej. MailHog).
+type SMTPNotifier struct {
+	Addr string
+	From string
+	To   []string
+}
+
+func (n *SMTPNotifier) Notify(a domain.LowStockAlert) error {
+	return smtp.SendMail(n.Addr, nil, n.From, n.To, n.message(a))
+}
+
+// message arma el correo. El nombre lo carga cualquiera que edita productos: en el asunto se
+// quitan los saltos de línea, para que no pueda agregar cabeceras, y se codifica como MIME.
+func (n *SMTPNotifier) message(a domain.LowStockAlert) []byte {
+	name := strings.NewReplacer("\r", " ", "\n", " ").Replace(a.Name)
+	subject := mime.QEncoding.Encode("utf-8", fmt.Sprintf("Stock bajo: %s", name))
+	body := fmt.Sprintf("El producto %s (%s) de la tienda %s tiene %d unidades; umbral de reposición %d.\r\n",
+		a.Name, a.ProductID, a.Tenant, a.Stock, a.Threshold)
+	msg := "From: " + n.From + "\r\n" +
+		"To: " + strings.Join(n.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n" + body
+	return []byte(msg)
+}
+
+// MultiNotifier reparte la alerta a todos los notificadores y junta sus errores. Si unos la
+// entregaron y otros no, devuelve un *domain.PartialDeliveryError con los que fallaron.
+type MultiNotifier []domain.AlertNotifier
+
+func (m MultiNotifier) Notify(a domain.LowStockAlert) error {
+	var failed MultiNotifier
+	var errs []error
+	for _, n := range m {
+		err := n.Notify(a)
+		if err == nil {
+			continue
+		}
+		var partial *domain.PartialDeliveryError
+		if errors.As(err, &partial) {
+			failed = append(failed, partial.Pending)
+		} else {
+			failed = append(failed, n)
+		}
+		errs = append(errs, err)
+	}
+
+	err := errors.Join(errs...)
+	if len(failed) > 0 && len(failed) < len(m) {
+		return &domain.PartialDeliveryError{Pending: failed, Err: err}
+	}
+	return err
+}
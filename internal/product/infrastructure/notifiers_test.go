@@ -0,0 +1,67 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"testing"
+
+	"mlsport/internal/product/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var got domain.LowStockAlert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookNotifier(srv.URL).Notify(domain.LowStockAlert{ProductID: "1", Stock: 2, Threshold: 5})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", got.ProductID)
+}
+
+func TestMultiNotifier_JoinsErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	webhook := NewWebhookNotifier(srv.URL)
+
+	err := MultiNotifier{LogNotifier{}, webhook}.Notify(domain.LowStockAlert{ProductID: "1"})
+
+	// El log la registró; queda pendiente solo el webhook.
+	var partial *domain.PartialDeliveryError
+	assert.ErrorAs(t, err, &partial)
+	assert.Equal(t, MultiNotifier{webhook}, partial.Pending)
+
+	err = MultiNotifier{webhook}.Notify(domain.LowStockAlert{ProductID: "1"})
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &partial))
+}
+
+func TestSMTPNotifier_SanitizesSubject(t *testing.T) {
+	n := &SMTPNotifier{From: "alertas@mlsport.local", To: []string{"compras@mlsport.local"}}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(n.message(domain.LowStockAlert{
+		ProductID: "1",
+		Name:      "Guayo Niño\r\nBcc: atacante@example.com",
+		Stock:     1,
+		Threshold: 5,
+	})))
+
+	assert.NoError(t, err)
+	assert.Empty(t, msg.Header.Get("Bcc"))
+	assert.Contains(t, msg.Header.Get("Subject"), "=?utf-8?q?")
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Stock bajo: Guayo Niño  Bcc: atacante@example.com", subject)
+}
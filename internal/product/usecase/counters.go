@@ -0,0 +1,42 @@
+package usecase
+
+import "mlsport/internal/product/domain"
+
+// CountersService expone el modelo de lectura de métricas, su reconstrucción y su verificación,
+// siempre para una tienda.
+type CountersService struct {
+	Repo domain.CounterRepository
+}
+
+func NewCountersService(repo domain.CounterRepository) *CountersService {
+	return &CountersService{Repo: repo}
+}
+
+func (s *CountersService) Get(tenantID string) (*domain.MetricsCounters, error) {
+	return s.Repo.Get(tenantID)
+}
+
+// Rebuild recalcula los contadores de la tienda desde cero y reemplaza los guardados.
+func (s *CountersService) Rebuild(tenantID string) (*domain.MetricsCounters, error) {
+	return s.Repo.Rebuild(tenantID)
+}
+
+// Check compara los contadores guardados con el $group en vivo sobre la colección.
+func (s *CountersService) Check(tenantID string) (*domain.CounterCheck, error) {
+	stored, err := s.Repo.Get(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	live, err := s.Repo.Compute(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := stored.Diff(*live)
+	return &domain.CounterCheck{
+		Consistent:  len(diffs) == 0,
+		Stored:      stored,
+		Live:        live,
+		Differences: diffs,
+	}, nil
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryProductRepo guarda productos por ID para que ProductService pueda leer el estado previo.
+type memoryProductRepo struct {
+	mockRepo
+	byID map[string]domain.Product
+}
+
+func (r *memoryProductRepo) Create(ctx context.Context, p *domain.Product) error {
+	r.byID[p.ID] = *p
+	return nil
+}
+
+func (r *memoryProductRepo) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	p, ok := r.byID[id]
+	if !ok {
+		return nil, errors.New("no encontrado")
+	}
+	return &p, nil
+}
+
+func (r *memoryProductRepo) Update(ctx context.Context, p *domain.Product) error {
+	r.byID[p.ID] = *p
+	return nil
+}
+
+func (r *memoryProductRepo) Patch(ctx context.Context, id string, fields map[string]interface{}) error {
+	p := r.byID[id]
+	if stock, ok := fields["stock"].(int); ok {
+		p.Stock = stock
+	}
+	if cat, ok := fields["category"].(string); ok {
+		p.Category = cat
+	}
+	r.byID[id] = p
+	return nil
+}
+
+func (r *memoryProductRepo) Delete(ctx context.Context, id string) error {
+	delete(r.byID, id)
+	return nil
+}
+
+// memoryCounterRepo aplica las variaciones en memoria; live simula el $group sobre la colección.
+type memoryCounterRepo struct {
+	stored domain.MetricsCounters
+	live   domain.MetricsCounters
+}
+
+func (m *memoryCounterRepo) Get(tenantID string) (*domain.MetricsCounters, error) {
+	c := m.stored
+	return &c, nil
+}
+
+func (m *memoryCounterRepo) Apply(tenantID string, d domain.CounterDelta) error {
+	m.stored.TotalProducts += d.Products
+	m.stored.TotalStock += d.Stock
+	m.stored.PriceSum += d.PriceSum
+	if m.stored.Categories == nil {
+		m.stored.Categories = map[string]int{}
+	}
+	for k, v := range d.Categories {
+		m.stored.Categories[k] += v
+	}
+	return nil
+}
+
+func (m *memoryCounterRepo) Rebuild(tenantID string) (*domain.MetricsCounters, error) {
+	live := m.live
+	m.stored = live
+	return &live, nil
+}
+
+func (m *memoryCounterRepo) Compute(tenantID string) (*domain.MetricsCounters, error) {
+	c := m.live
+	return &c, nil
+}
+
+func TestCounters_TrackWrites(t *testing.T) {
+	counters := &memoryCounterRepo{}
+	service := NewProductService(&memoryProductRepo{byID: map[string]domain.Product{}})
+	service.Counters = counters
+
+	assert.NoError(t, service.Create(context.Background(), &domain.Product{ID: "1", Category: "Calzado", Price: 100, Stock: 4}))
+	assert.NoError(t, service.Create(context.Background(), &domain.Product{ID: "2", Category: "Ropa", Price: 50, Stock: 10}))
+	assert.NoError(t, service.Update(context.Background(), &domain.Product{ID: "1", Category: "Calzado", Price: 120, Stock: 4}))
+	assert.NoError(t, service.Patch(context.Background(), "2", map[string]interface{}{"stock": 3, "category": "Calzado"}))
+
+	assert.Equal(t, 2, counters.stored.TotalProducts)
+	assert.Equal(t, 7, counters.stored.TotalStock)
+	assert.InDelta(t, 170, counters.stored.PriceSum, 0.001)
+	assert.Equal(t, 2, counters.stored.Categories["calzado"])
+	assert.Equal(t, 0, counters.stored.Categories["ropa"])
+
+	assert.NoError(t, service.Delete(context.Background(), "1"))
+	assert.Equal(t, 1, counters.stored.TotalProducts)
+	assert.Equal(t, 3, counters.stored.TotalStock)
+	assert.InDelta(t, 50, counters.stored.PriceSum, 0.001)
+	assert.Equal(t, 1, counters.stored.Categories["calzado"])
+}
+
+func TestCounters_UpdateOfMissingProductIsIgnored(t *testing.T) {
+	counters := &memoryCounterRepo{}
+	service := NewProductService(&memoryProductRepo{byID: map[string]domain.Product{}})
+	service.Counters = counters
+
+	assert.NoError(t, service.Update(context.Background(), &domain.Product{ID: "x", Category: "Ropa", Price: 10, Stock: 1}))
+	assert.Equal(t, 0, counters.stored.TotalProducts)
+}
+
+func TestCountersService_CheckAndRebuild(t *testing.T) {
+	repo := &memoryCounterRepo{
+		stored: domain.MetricsCounters{TotalProducts: 3, TotalStock: 10, PriceSum: 30, Categories: map[string]int{"ropa": 3}},
+		live:   domain.MetricsCounters{TotalProducts: 2, TotalStock: 10, PriceSum: 30, Categories: map[string]int{"ropa": 2}},
+	}
+	service := NewCountersService(repo)
+
+	check, err := service.Check(tenant.Default)
+	assert.NoError(t, err)
+	assert.False(t, check.Consistent)
+	assert.Equal(t, []string{"total_products: 3 != 2", "categories.ropa: 3 != 2"}, check.Differences)
+
+	_, err = service.Rebuild(tenant.Default)
+	assert.NoError(t, err)
+
+	check, err = service.Check(tenant.Default)
+	assert.NoError(t, err)
+	assert.True(t, check.Consistent)
+	assert.Empty(t, check.Differences)
+}
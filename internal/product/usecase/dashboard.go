@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+)
+
+const (
+	DefaultWidgetTimeout = 3 * time.Second
+	DefaultRecentLimit   = 10
+)
+
+// DashboardService arma el dashboard consultando cada widget en paralelo, cada uno con su propio
+// plazo; un widget que falla o vence no descarta a los demás.
+type DashboardService struct {
+	Products      *ProductService
+	WidgetTimeout time.Duration
+	RecentLimit   int
+	// OnWidget recibe el tiempo de cada widget y su resultado (ok, error o timeout), para las
+	// métricas operativas.
+	OnWidget func(name, outcome string, elapsed time.Duration)
+}
+
+func NewDashboardService(products *ProductService) *DashboardService {
+	return &DashboardService{
+		Products:      products,
+		WidgetTimeout: DefaultWidgetTimeout,
+		RecentLimit:   DefaultRecentLimit,
+	}
+}
+
+// Build obtiene los widgets pedidos (todos si widgets está vacío).
+func (s *DashboardService) Build(ctx context.Context, widgets []string) (*domain.Dashboard, error) {
+	if len(widgets) == 0 {
+		widgets = domain.DashboardWidgets
+	}
+	seen := map[string]bool{}
+	var unique []string
+	for _, name := range widgets {
+		if s.loader(name) == nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownWidget, name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	widgets = unique
+
+	dash := &domain.Dashboard{Widgets: make(map[string]domain.DashboardWidget, len(widgets))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range widgets {
+		load := s.loader(name)
+		wg.Add(1)
+		// Cada widget corre con su propio plazo (run); el error queda en el widget y no corta a los demás.
+		go func() {
+			defer wg.Done()
+			w := s.run(ctx, name, load)
+
+			mu.Lock()
+			dash.Widgets[name] = w
+			dash.Partial = dash.Partial || w.Error != ""
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	dash.GeneratedAt = time.Now().UTC()
+	return dash, nil
+}
+
+type widgetLoader func(ctx context.Context) (interface{}, error)
+
+func (s *DashboardService) loader(name string) widgetLoader {
+	switch name {
+	case domain.WidgetProducts:
+		return func(ctx context.Context) (interface{}, error) {
+			return s.Products.GetAll(ctx)
+		}
+	case domain.WidgetMetrics:
+		return func(ctx context.Context) (interface{}, error) {
+			return s.Products.GetMetrics(ctx)
+		}
+	case domain.WidgetTopCategories:
+		return func(ctx context.Context) (interface{}, error) {
+			metrics, err := s.Products.GetMetrics(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return metrics["categories"], nil
+		}
+	case domain.WidgetLowStock:
+		return func(ctx context.Context) (interface{}, error) {
+			return s.Products.GetLowStock(ctx, 0)
+		}
+	case domain.WidgetRecentChanges:
+		return func(ctx context.Context) (interface{}, error) {
+			return s.Products.GetRecent(ctx, s.RecentLimit)
+		}
+	}
+	return nil
+}
+
+// run aplica el plazo del widget aunque la consulta no lo respete (p. ej. el árbol de categorías).
+func (s *DashboardService) run(ctx context.Context, name string, load widgetLoader) domain.DashboardWidget {
+	ctx, cancel := context.WithTimeout(ctx, s.WidgetTimeout)
+	defer cancel()
+
+	type result struct {
+		data interface{}
+		err  error
+	}
+	start := time.Now()
+	ch := make(chan result, 1)
+	go func() {
+		data, err := load(ctx)
+		ch <- result{data, err}
+	}()
+
+	var res result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		res.err = ctx.Err()
+	}
+
+	elapsed := time.Since(start)
+	w := domain.DashboardWidget{Data: res.data, ElapsedMS: elapsed.Milliseconds()}
+	outcome := "ok"
+	switch {
+	case res.err == nil:
+		if list, ok := res.data.([]domain.Product); ok && list == nil {
+			w.Data = []domain.Product{}
+		}
+	case errors.Is(res.err, context.DeadlineExceeded):
+		w.Data, w.Error = nil, domain.ErrWidgetTimeout.Error()
+		outcome = "timeout"
+	default:
+		slog.ErrorContext(ctx, "Error obteniendo un widget del dashboard", "widget", name, logging.Err(res.err))
+		w.Data, w.Error = nil, fmt.Sprintf("no se pudo obtener %s", name)
+		outcome = "error"
+	}
+	if s.OnWidget != nil {
+		s.OnWidget(name, outcome, elapsed)
+	}
+	return w
+}
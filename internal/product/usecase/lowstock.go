@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mlsport/internal/events"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+	"sync"
+	"time"
+)
+
+// LowStockChecker revisa periódicamente el stock de cada tienda y alerta una sola vez por cada
+// producto que cae bajo su umbral; si el producto se repone, una nueva caída vuelve a alertar.
+type LowStockChecker struct {
+	Service  *ProductService
+	Notifier domain.AlertNotifier
+	Interval time.Duration
+	// Tenants, si está configurado, lista las tiendas que revisa Run; sin él, solo la por defecto.
+	Tenants tenant.Lister
+	// Repo, si está configurado, es el que consulta Check en lugar del del servicio. Debe ir sin
+	// caché: StockChanged se publica antes de que se invalide y la lista guardada aún no lo refleja.
+	Repo domain.ProductRepository
+
+	mu sync.Mutex
+	// alerted guarda, por tienda, los productos ya alertados y los canales en que falta
+	// reintentar la alerta (nil si llegó a todos o si una pasada la está enviando).
+	alerted map[string]map[string]domain.AlertNotifier
+}
+
+func NewLowStockChecker(s *ProductService, n domain.AlertNotifier, interval time.Duration) *LowStockChecker {
+	return &LowStockChecker{Service: s, Notifier: n, Interval: interval, alerted: map[string]map[string]domain.AlertNotifier{}}
+}
+
+// delivery es una alerta reservada y los canales a los que hay que enviarla.
+type delivery struct {
+	alert    domain.LowStockAlert
+	notifier domain.AlertNotifier
+}
+
+func (c *LowStockChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := tenant.Each(ctx, c.Tenants, func(ctx context.Context) error {
+			_, err := c.Check(ctx)
+			return err
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Error revisando stock bajo", logging.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Handle se suscribe a StockChanged para alertar en cuanto un producto cae bajo su umbral,
+// sin esperar la próxima pasada periódica.
+func (c *LowStockChecker) Handle(ctx context.Context, e events.Event) error {
+	ev, ok := e.(domain.StockChanged)
+	if !ok {
+		return nil
+	}
+	p := domain.Product{Stock: ev.Current, ReorderThreshold: ev.Threshold}
+	if !p.IsLowStock(c.Service.LowStockThreshold) {
+		return nil
+	}
+	_, err := c.Check(ctx)
+	return err
+}
+
+// Check hace una pasada por la tienda del contexto y devuelve cuántas alertas llegaron a todos sus
+// canales. Las alertas se reservan bajo el lock y se envían fuera de él, para que un canal lento
+// no frene las demás pasadas; una pasada concurrente no repite las reservadas. Si algunos canales
+// fallan, las pasadas siguientes reintentan solo en esos mientras el producto siga bajo su umbral.
+func (c *LowStockChecker) Check(ctx context.Context) (int, error) {
+	tenantID := tenant.ID(ctx)
+	repo := c.Repo
+	if repo == nil {
+		repo = c.Service.Repo
+	}
+	list, err := repo.FindLowStock(ctx, c.Service.LowStockThreshold)
+	if err != nil {
+		return 0, err
+	}
+
+	var pending []delivery
+	c.mu.Lock()
+	alerted := c.alerted[tenantID]
+	current := make(map[string]domain.AlertNotifier, len(list))
+	for _, p := range list {
+		current[p.ID] = nil
+		notifier, seen := alerted[p.ID]
+		switch {
+		case !seen:
+			notifier = c.Notifier
+		case notifier == nil:
+			continue
+		}
+		pending = append(pending, delivery{notifier: notifier, alert: domain.LowStockAlert{
+			Tenant:    tenantID,
+			ProductID: p.ID,
+			Name:      p.Name,
+			Category:  p.Category,
+			Brand:     p.Brand,
+			Stock:     p.Stock,
+			Threshold: p.Threshold(c.Service.LowStockThreshold),
+			At:        time.Now().UTC(),
+		}})
+	}
+	c.alerted[tenantID] = current
+	c.mu.Unlock()
+
+	sent := 0
+	for _, d := range pending {
+		err := d.notifier.Notify(d.alert)
+		if err == nil {
+			sent++
+			continue
+		}
+
+		retry := d.notifier
+		var partial *domain.PartialDeliveryError
+		if errors.As(err, &partial) {
+			retry = partial.Pending
+			slog.WarnContext(ctx, "Alerta de stock entregada solo en parte", "product", d.alert.ProductID, logging.Err(err))
+		} else {
+			slog.ErrorContext(ctx, "Error enviando alerta de stock", "product", d.alert.ProductID, logging.Err(err))
+		}
+
+		c.mu.Lock()
+		// Si entretanto se repuso, la alerta ya no hace falta.
+		if _, ok := c.alerted[tenantID][d.alert.ProductID]; ok {
+			c.alerted[tenantID][d.alert.ProductID] = retry
+		}
+		c.mu.Unlock()
+	}
+	return sent, nil
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mlsport/internal/product/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lowStockRepo struct {
+	mockRepo
+	low []domain.Product
+}
+
+func (r *lowStockRepo) FindLowStock(ctx context.Context, threshold int) ([]domain.Product, error) {
+	return r.low, nil
+}
+
+type recordingNotifier struct {
+	alerts []domain.LowStockAlert
+	fail   bool
+}
+
+func (n *recordingNotifier) Notify(a domain.LowStockAlert) error {
+	if n.fail {
+		return errors.New("notificador caído")
+	}
+	n.alerts = append(n.alerts, a)
+	return nil
+}
+
+// partialNotifier simula un MultiNotifier en el que un canal entregó y el canal failed falló.
+type partialNotifier struct {
+	calls  int
+	failed *recordingNotifier
+}
+
+func (n *partialNotifier) Notify(domain.LowStockAlert) error {
+	n.calls++
+	return &domain.PartialDeliveryError{Pending: n.failed, Err: errors.New("smtp caído")}
+}
+
+// blockingNotifier avisa al entrar en Notify y espera a release para terminar.
+type blockingNotifier struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (n *blockingNotifier) Notify(domain.LowStockAlert) error {
+	close(n.entered)
+	<-n.release
+	return nil
+}
+
+func TestLowStockChecker_Deduplicates(t *testing.T) {
+	repo := &lowStockRepo{low: []domain.Product{{ID: "1", Name: "Balón", Stock: 2, ReorderThreshold: 3}}}
+	notifier := &recordingNotifier{}
+	checker := NewLowStockChecker(NewProductService(repo), notifier, 0)
+
+	sent, err := checker.Check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, 3, notifier.alerts[0].Threshold)
+
+	sent, _ = checker.Check(context.Background())
+	assert.Equal(t, 0, sent)
+
+	// Se repone y vuelve a caer: alerta de nuevo.
+	repo.low = nil
+	_, _ = checker.Check(context.Background())
+	repo.low = []domain.Product{{ID: "1", Name: "Balón", Stock: 1}}
+	sent, _ = checker.Check(context.Background())
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, domain.DefaultLowStockThreshold, notifier.alerts[1].Threshold)
+}
+
+func TestLowStockChecker_RetriesFailedAlerts(t *testing.T) {
+	repo := &lowStockRepo{low: []domain.Product{{ID: "1", Name: "Balón", Stock: 0}}}
+	notifier := &recordingNotifier{fail: true}
+	checker := NewLowStockChecker(NewProductService(repo), notifier, 0)
+
+	sent, _ := checker.Check(context.Background())
+	assert.Equal(t, 0, sent)
+
+	notifier.fail = false
+	sent, _ = checker.Check(context.Background())
+	assert.Equal(t, 1, sent)
+}
+
+func TestLowStockChecker_RetriesOnlyFailedChannels(t *testing.T) {
+	repo := &lowStockRepo{low: []domain.Product{{ID: "1", Name: "Balón", Stock: 0}}}
+	smtp := &recordingNotifier{fail: true}
+	notifier := &partialNotifier{failed: smtp}
+	checker := NewLowStockChecker(NewProductService(repo), notifier, 0)
+
+	sent, _ := checker.Check(context.Background())
+	assert.Equal(t, 0, sent)
+
+	// Sigue caído: se reintenta solo en el canal que falló.
+	sent, _ = checker.Check(context.Background())
+	assert.Equal(t, 0, sent)
+
+	smtp.fail = false
+	sent, _ = checker.Check(context.Background())
+	assert.Equal(t, 1, sent)
+	assert.Len(t, smtp.alerts, 1)
+	assert.Equal(t, 1, notifier.calls)
+
+	sent, _ = checker.Check(context.Background())
+	assert.Equal(t, 0, sent)
+	assert.Len(t, smtp.alerts, 1)
+}
+
+func TestLowStockChecker_DropsRetriesWhenRestocked(t *testing.T) {
+	repo := &lowStockRepo{low: []domain.Product{{ID: "1", Name: "Balón", Stock: 0}}}
+	smtp := &recordingNotifier{fail: true}
+	checker := NewLowStockChecker(NewProductService(repo), &partialNotifier{failed: smtp}, 0)
+
+	_, _ = checker.Check(context.Background())
+	repo.low = nil
+	smtp.fail = false
+	_, _ = checker.Check(context.Background())
+
+	assert.Empty(t, smtp.alerts)
+}
+
+func TestLowStockChecker_NotifiesOutsideLock(t *testing.T) {
+	repo := &lowStockRepo{low: []domain.Product{{ID: "1", Name: "Balón", Stock: 0}}}
+	notifier := &blockingNotifier{entered: make(chan struct{}), release: make(chan struct{})}
+	checker := NewLowStockChecker(NewProductService(repo), notifier, 0)
+
+	done := make(chan int)
+	go func() {
+		sent, _ := checker.Check(context.Background())
+		done <- sent
+	}()
+	<-notifier.entered
+
+	// Con la primera alerta todavía en curso, otra pasada no se bloquea ni la repite.
+	sent, err := checker.Check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	close(notifier.release)
+	assert.Equal(t, 1, <-done)
+}
+
+func TestLowStockChecker_HandleStockChanged(t *testing.T) {
+	repo := &lowStockRepo{low: []domain.Product{{ID: "1", Name: "Balón", Stock: 2}}}
+	notifier := &recordingNotifier{}
+	checker := NewLowStockChecker(NewProductService(repo), notifier, 0)
+
+	// Sigue por encima del umbral: no revisa.
+	assert.NoError(t, checker.Handle(context.Background(), domain.StockChanged{ProductID: "1", Previous: 20, Current: 10}))
+	assert.Empty(t, notifier.alerts)
+
+	assert.NoError(t, checker.Handle(context.Background(), domain.StockChanged{ProductID: "1", Previous: 10, Current: 2}))
+	assert.Len(t, notifier.alerts, 1)
+}
+
+func TestLowStockChecker_HandleReadsUncachedRepo(t *testing.T) {
+	// La caché del servicio todavía guarda la lista de antes de la escritura.
+	cached := &lowStockRepo{}
+	fresh := &lowStockRepo{low: []domain.Product{{ID: "1", Name: "Balón", Stock: 2}}}
+	notifier := &recordingNotifier{}
+	checker := NewLowStockChecker(NewProductService(cached), notifier, 0)
+	checker.Repo = fresh
+
+	assert.NoError(t, checker.Handle(context.Background(), domain.StockChanged{ProductID: "1", Previous: 10, Current: 2}))
+	assert.Len(t, notifier.alerts, 1)
+}
+
+func TestGetLowStock_DefaultThreshold(t *testing.T) {
+	service := NewProductService(&mockRepo{})
+	service.LowStockThreshold = 7
+
+	list, err := service.GetLowStock(context.Background(), 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, list[0].Stock)
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"mlsport/internal/events"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+)
+
+const (
+	DefaultOutboxInterval    = time.Second
+	DefaultOutboxBatch       = 100
+	DefaultOutboxMaxAttempts = 10
+	DefaultOutboxBackoff     = time.Second
+	DefaultOutboxMaxBackoff  = 5 * time.Minute
+)
+
+// OutboxRelay publica las entradas del outbox en orden de creación. Una entrada se marca entregada
+// solo después de que Publish termina sin error, así que ante una caída se vuelve a publicar
+// (al menos una vez): los suscriptores deben tolerar duplicados, identificándolos con
+// events.IDFromContext. Si una entrada falla, se reintenta con espera exponencial y las siguientes
+// esperan detrás para no alterar el orden; tras MaxAttempts se aparta como muerta.
+type OutboxRelay struct {
+	Repo        domain.OutboxRepository
+	Publisher   events.Publisher
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+
+	now  func() time.Time
+	wake chan struct{}
+}
+
+func NewOutboxRelay(repo domain.OutboxRepository, publisher events.Publisher) *OutboxRelay {
+	return &OutboxRelay{
+		Repo:        repo,
+		Publisher:   publisher,
+		Interval:    DefaultOutboxInterval,
+		BatchSize:   DefaultOutboxBatch,
+		MaxAttempts: DefaultOutboxMaxAttempts,
+		Backoff:     DefaultOutboxBackoff,
+		MaxBackoff:  DefaultOutboxMaxBackoff,
+		now:         func() time.Time { return time.Now().UTC() },
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Wake adelanta la próxima vuelta; lo llama el repositorio después de confirmar una escritura.
+func (r *OutboxRelay) Wake() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run procesa el outbox cada Interval (o al despertarlo) hasta que se cancela ctx.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Error procesando el outbox", logging.Err(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// RunOnce publica las entradas pendientes hasta la primera que espera un reintento y devuelve
+// cuántas se entregaron.
+func (r *OutboxRelay) RunOnce(ctx context.Context) (int, error) {
+	entries, err := r.Repo.Pending(ctx, r.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, entry := range entries {
+		if entry.NextAttemptAt.After(r.now()) {
+			break
+		}
+		if err := r.publish(ctx, entry); err != nil {
+			if ok, markErr := r.fail(ctx, entry, err); markErr != nil || !ok {
+				return delivered, markErr
+			}
+			continue
+		}
+		if err := r.Repo.MarkDelivered(ctx, entry.ID, r.now()); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, entry domain.OutboxEntry) error {
+	e, err := domain.DecodeEvent(entry)
+	if err != nil {
+		return err
+	}
+	// Las entradas anteriores a los tenants no tienen tienda: son de la por defecto.
+	ctx = tenant.WithID(ctx, entry.Tenant)
+	return r.Publisher.Publish(events.ContextWithID(ctx, entry.ID), e)
+}
+
+// fail registra el intento. Devuelve true si la entrada se apartó como muerta y se puede seguir
+// con las siguientes; false si quedó reprogramada y la vuelta debe cortarse para respetar el orden.
+func (r *OutboxRelay) fail(ctx context.Context, entry domain.OutboxEntry, cause error) (bool, error) {
+	attempts := entry.Attempts + 1
+	if attempts >= r.MaxAttempts {
+		slog.ErrorContext(ctx, "Evento descartado tras agotar los intentos", "entry", entry.ID, "event", entry.Event, "attempts", attempts, logging.Err(cause))
+		return true, r.Repo.MarkDead(ctx, entry.ID, attempts, cause.Error())
+	}
+
+	wait := r.Backoff << (attempts - 1)
+	if wait <= 0 || wait > r.MaxBackoff {
+		wait = r.MaxBackoff
+	}
+	slog.WarnContext(ctx, "Evento falló, se reintenta", "entry", entry.ID, "event", entry.Event, "attempt", attempts, "retry_in", wait.String(), logging.Err(cause))
+	return false, r.Repo.MarkFailed(ctx, entry.ID, attempts, r.now().Add(wait), cause.Error())
+}
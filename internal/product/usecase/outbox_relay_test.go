@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"mlsport/internal/events"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryOutbox struct {
+	mu        sync.Mutex
+	entries   map[string]*domain.OutboxEntry
+	delivered map[string]bool
+	dead      map[string]bool
+}
+
+func newMemoryOutbox(evs ...events.Event) *memoryOutbox {
+	o := &memoryOutbox{entries: map[string]*domain.OutboxEntry{}, delivered: map[string]bool{}, dead: map[string]bool{}}
+	for i, e := range evs {
+		entry, _ := domain.NewOutboxEntry(e, time.Time{})
+		entry.ID = string(rune('a' + i))
+		o.entries[entry.ID] = &entry
+	}
+	return o
+}
+
+func (o *memoryOutbox) Pending(ctx context.Context, limit int) ([]domain.OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var out []domain.OutboxEntry
+	for id, e := range o.entries {
+		if !o.delivered[id] && !o.dead[id] {
+			out = append(out, *e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (o *memoryOutbox) MarkDelivered(ctx context.Context, id string, at time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.delivered[id] = true
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(ctx context.Context, id string, attempts int, retryAt time.Time, lastErr string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries[id].Attempts = attempts
+	o.entries[id].NextAttemptAt = retryAt
+	o.entries[id].LastError = lastErr
+	return nil
+}
+
+func (o *memoryOutbox) MarkDead(ctx context.Context, id string, attempts int, lastErr string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries[id].Attempts = attempts
+	o.dead[id] = true
+	return nil
+}
+
+func stockEvent(id string) events.Event {
+	return domain.StockChanged{ProductID: id, Previous: 5, Current: 1}
+}
+
+func TestOutboxRelay_DeliversInOrderWithIDs(t *testing.T) {
+	outbox := newMemoryOutbox(stockEvent("p1"), stockEvent("p2"))
+	bus := events.NewBus()
+	var got, ids []string
+	bus.Subscribe(domain.EventStockChanged, func(ctx context.Context, e events.Event) error {
+		got = append(got, e.(domain.StockChanged).ProductID)
+		ids = append(ids, events.IDFromContext(ctx))
+		return nil
+	})
+
+	relay := NewOutboxRelay(outbox, bus)
+	n, err := relay.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"p1", "p2"}, got)
+	assert.Equal(t, []string{"a", "b"}, ids)
+
+	// Lo entregado no se vuelve a publicar.
+	n, _ = relay.RunOnce(context.Background())
+	assert.Equal(t, 0, n)
+}
+
+func TestOutboxRelay_PublishesWithEntryTenant(t *testing.T) {
+	outbox := newMemoryOutbox(stockEvent("p1"), stockEvent("p2"))
+	outbox.entries["a"].Tenant = "tienda-a"
+	bus := events.NewBus()
+	var tenants []string
+	bus.Subscribe(domain.EventStockChanged, func(ctx context.Context, e events.Event) error {
+		tenants = append(tenants, tenant.ID(ctx))
+		return nil
+	})
+
+	_, err := NewOutboxRelay(outbox, bus).RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tienda-a", tenant.Default}, tenants)
+}
+
+func TestOutboxRelay_RetriesWithBackoffAndKeepsOrder(t *testing.T) {
+	outbox := newMemoryOutbox(stockEvent("p1"), stockEvent("p2"))
+	bus := events.NewBus()
+	fail := true
+	var got []string
+	bus.Subscribe(domain.EventStockChanged, func(ctx context.Context, e events.Event) error {
+		if fail {
+			return errors.New("webhook caído")
+		}
+		got = append(got, e.(domain.StockChanged).ProductID)
+		return nil
+	})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	relay := NewOutboxRelay(outbox, bus)
+	relay.now = func() time.Time { return now }
+
+	n, err := relay.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 1, outbox.entries["a"].Attempts)
+	assert.Equal(t, now.Add(relay.Backoff), outbox.entries["a"].NextAttemptAt)
+	// p2 espera detrás de p1 para no adelantarse.
+	assert.Equal(t, 0, outbox.entries["b"].Attempts)
+
+	// Aunque el suscriptor se recupere, p2 no sale antes de que venza el reintento de p1.
+	fail = false
+	n, _ = relay.RunOnce(context.Background())
+	assert.Equal(t, 0, n)
+	assert.Empty(t, got)
+
+	now = now.Add(time.Hour)
+	n, _ = relay.RunOnce(context.Background())
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"p1", "p2"}, got)
+}
+
+func TestOutboxRelay_DeadLettersAfterMaxAttempts(t *testing.T) {
+	outbox := newMemoryOutbox(stockEvent("p1"), stockEvent("p2"))
+	bus := events.NewBus()
+	bus.Subscribe(domain.EventStockChanged, func(ctx context.Context, e events.Event) error {
+		if e.(domain.StockChanged).ProductID == "p1" {
+			return errors.New("inválido")
+		}
+		return nil
+	})
+
+	relay := NewOutboxRelay(outbox, bus)
+	relay.MaxAttempts = 1
+	n, err := relay.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.True(t, outbox.dead["a"])
+	assert.True(t, outbox.delivered["b"])
+}
+
+func TestOutboxRelay_BackoffIsCapped(t *testing.T) {
+	outbox := newMemoryOutbox(stockEvent("p1"))
+	outbox.entries["a"].Attempts = 40
+	bus := events.NewBus()
+	bus.Subscribe(events.All, func(ctx context.Context, e events.Event) error { return errors.New("x") })
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	relay := NewOutboxRelay(outbox, bus)
+	relay.MaxAttempts = 100
+	relay.now = func() time.Time { return now }
+	relay.RunOnce(context.Background())
+	assert.Equal(t, now.Add(relay.MaxBackoff), outbox.entries["a"].NextAttemptAt)
+}
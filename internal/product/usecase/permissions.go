@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+
+	"mlsport/internal/auth"
+	"mlsport/internal/product/domain"
+)
+
+func (s *ProductService) authorize(ctx context.Context, perms ...auth.Permission) error {
+	if !s.EnforcePermissions {
+		return nil
+	}
+	return auth.Authorize(ctx, perms...)
+}
+
+// patchPermissions traduce los campos de un PATCH a permisos: stock (y su umbral de reposición) y
+// precio tienen el suyo y el resto de los datos requiere edición.
+func patchPermissions(fields map[string]interface{}) []auth.Permission {
+	var perms []auth.Permission
+	edit := false
+	for k := range fields {
+		switch k {
+		case "stock", "reorder_threshold":
+			perms = append(perms, auth.PermProductStock)
+		case "price":
+			perms = append(perms, auth.PermProductPrice)
+		default:
+			edit = true
+		}
+	}
+	if edit || len(perms) == 0 {
+		perms = append(perms, auth.PermProductEdit)
+	}
+	return perms
+}
+
+// replacePermissions compara el producto guardado con su reemplazo (PUT) y pide permiso solo para
+// lo que cambia; así un PUT no sirve para saltarse la regla de un PATCH.
+func replacePermissions(before, after *domain.Product) []auth.Permission {
+	if before == nil {
+		return []auth.Permission{auth.PermProductEdit}
+	}
+
+	var perms []auth.Permission
+	if before.Stock != after.Stock || before.ReorderThreshold != after.ReorderThreshold {
+		perms = append(perms, auth.PermProductStock)
+	}
+	if before.Price != after.Price {
+		perms = append(perms, auth.PermProductPrice)
+	}
+
+	if descriptive(*before) != descriptive(*after) || len(perms) == 0 {
+		perms = append(perms, auth.PermProductEdit)
+	}
+	return perms
+}
+
+// descriptive deja solo los datos que controla product:edit; un campo nuevo de Product debe
+// agregarse aquí o a los permisos de stock y precio.
+func descriptive(p domain.Product) [3]string {
+	return [3]string{p.Name, p.Category, p.Brand}
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"mlsport/internal/auth"
+	"mlsport/internal/product/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func as(role string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: role, Roles: []string{role}})
+}
+
+func TestProductService_EnforcesPermissions(t *testing.T) {
+	repo := &memoryProductRepo{byID: map[string]domain.Product{
+		"1": {ID: "1", Name: "Botín", Category: "calzado", Price: 100, Stock: 4},
+	}}
+	service := NewProductService(repo)
+	service.EnforcePermissions = true
+
+	clerk, merch, viewer := as(auth.RoleInventoryClerk), as(auth.RoleMerchandiser), as(auth.RoleViewer)
+
+	// Sin usuario (p. ej. un proceso que no pasa por HTTP) no se escribe.
+	assert.ErrorIs(t, service.Delete(context.Background(), "1"), auth.ErrUnauthenticated)
+
+	// El depósito ajusta stock pero no precio ni datos.
+	assert.NoError(t, service.Patch(clerk, "1", map[string]interface{}{"stock": 2}))
+	assert.ErrorIs(t, service.Patch(clerk, "1", map[string]interface{}{"price": 90.0}), auth.ErrForbidden)
+	assert.ErrorIs(t, service.Patch(clerk, "1", map[string]interface{}{"stock": 1, "name": "x"}), auth.ErrForbidden)
+	assert.ErrorIs(t, service.Create(clerk, &domain.Product{ID: "2"}), auth.ErrForbidden)
+
+	// Un PUT se autoriza por lo que cambia: el depósito puede reemplazar si solo cambia el stock.
+	assert.NoError(t, service.Update(clerk, &domain.Product{ID: "1", Name: "Botín", Category: "calzado", Price: 100, Stock: 8}))
+	assert.ErrorIs(t, service.Update(clerk, &domain.Product{ID: "1", Name: "Botín", Category: "calzado", Price: 80, Stock: 8}), auth.ErrForbidden)
+
+	// Comercial cambia precios pero no stock ni borra.
+	assert.NoError(t, service.Update(merch, &domain.Product{ID: "1", Name: "Botín Pro", Category: "calzado", Price: 120, Stock: 8}))
+	assert.ErrorIs(t, service.Update(merch, &domain.Product{ID: "1", Name: "Botín Pro", Category: "calzado", Price: 120, Stock: 0}), auth.ErrForbidden)
+	assert.ErrorIs(t, service.Delete(merch, "1"), auth.ErrForbidden)
+
+	assert.ErrorIs(t, service.Patch(viewer, "1", map[string]interface{}{"stock": 0}), auth.ErrForbidden)
+	assert.NoError(t, service.Delete(as(auth.RoleAdmin), "1"))
+	assert.Empty(t, repo.byID)
+}
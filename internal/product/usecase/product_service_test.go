@@ -1,9 +1,11 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	category "mlsport/internal/category/domain"
 	"mlsport/internal/product/domain"
 
 	"github.com/stretchr/testify/assert"
@@ -11,20 +13,34 @@ import (
 
 type mockRepo struct{}
 
-func (m *mockRepo) Create(p *domain.Product) error { return nil }
-func (m *mockRepo) FindAll() ([]domain.Product, error) {
+func (m *mockRepo) Create(ctx context.Context, p *domain.Product) error { return nil }
+func (m *mockRepo) FindAll(ctx context.Context) ([]domain.Product, error) {
 	return []domain.Product{{Name: "Balón"}}, nil
 }
-func (m *mockRepo) FindByID(id string) (*domain.Product, error) {
+func (m *mockRepo) Find(ctx context.Context, f domain.ProductFilter) ([]domain.Product, error) {
+	return []domain.Product{{Name: "Guayos", Brand: f.Brands[0]}}, nil
+}
+func (m *mockRepo) FindByID(ctx context.Context, id string) (*domain.Product, error) {
 	return &domain.Product{ID: id, Name: "Zapatilla"}, nil
 }
-func (m *mockRepo) FindByCategory(cat string) ([]domain.Product, error) {
-	return []domain.Product{{Category: cat}}, nil
+func (m *mockRepo) FindByCategory(ctx context.Context, cats ...string) ([]domain.Product, error) {
+	return []domain.Product{{Category: cats[0]}}, nil
+}
+func (m *mockRepo) Update(ctx context.Context, p *domain.Product) error { return nil }
+func (m *mockRepo) Patch(ctx context.Context, id string, fields map[string]interface{}) error {
+	return nil
+}
+func (m *mockRepo) Delete(ctx context.Context, id string) error { return nil }
+func (m *mockRepo) GetPriceDistribution(ctx context.Context, q domain.PriceQuery) (*domain.PriceDistribution, error) {
+	return &domain.PriceDistribution{Count: q.Buckets}, nil
 }
-func (m *mockRepo) Update(p *domain.Product) error                       { return nil }
-func (m *mockRepo) Patch(id string, fields map[string]interface{}) error { return nil }
-func (m *mockRepo) Delete(id string) error                               { return nil }
-func (m *mockRepo) GetMetrics() (map[string]interface{}, error) {
+func (m *mockRepo) FindLowStock(ctx context.Context, threshold int) ([]domain.Product, error) {
+	return []domain.Product{{ID: "1", Name: "Balón", Stock: threshold}}, nil
+}
+func (m *mockRepo) FindRecent(ctx context.Context, limit int) ([]domain.Product, error) {
+	return []domain.Product{{ID: "1", Name: "Camiseta"}}, nil
+}
+func (m *mockRepo) GetMetrics(ctx context.Context, q domain.MetricsQuery) (map[string]interface{}, error) {
 	return map[string]interface{}{
 		"total_products": 3,
 		"top_categories": []string{"Ropa", "Calzado"},
@@ -32,30 +48,50 @@ func (m *mockRepo) GetMetrics() (map[string]interface{}, error) {
 		"average_price":  89.5,
 	}, nil
 }
-func (m *mockRepo) GetCategories() ([]string, error) { return []string{"Ropa", "Calzado"}, nil }
+func (m *mockRepo) GetCategories(ctx context.Context) ([]string, error) {
+	return []string{"Ropa", "Calzado"}, nil
+}
 
 // mockRepo que simula errores
 type errorMockRepo struct{}
 
-func (m *errorMockRepo) Create(p *domain.Product) error { return errors.New("error simulado create") }
-func (m *errorMockRepo) FindAll() ([]domain.Product, error) {
+func (m *errorMockRepo) Create(ctx context.Context, p *domain.Product) error {
+	return errors.New("error simulado create")
+}
+func (m *errorMockRepo) FindAll(ctx context.Context) ([]domain.Product, error) {
 	return nil, errors.New("error simulado findall")
 }
-func (m *errorMockRepo) FindByID(id string) (*domain.Product, error) {
+func (m *errorMockRepo) Find(ctx context.Context, f domain.ProductFilter) ([]domain.Product, error) {
+	return nil, errors.New("error simulado find")
+}
+func (m *errorMockRepo) FindByID(ctx context.Context, id string) (*domain.Product, error) {
 	return nil, errors.New("error simulado findbyid")
 }
-func (m *errorMockRepo) FindByCategory(cat string) ([]domain.Product, error) {
+func (m *errorMockRepo) FindByCategory(ctx context.Context, cats ...string) ([]domain.Product, error) {
 	return nil, errors.New("error simulado findbycategory")
 }
-func (m *errorMockRepo) Update(p *domain.Product) error { return errors.New("error simulado update") }
-func (m *errorMockRepo) Patch(id string, fields map[string]interface{}) error {
+func (m *errorMockRepo) Update(ctx context.Context, p *domain.Product) error {
+	return errors.New("error simulado update")
+}
+func (m *errorMockRepo) Patch(ctx context.Context, id string, fields map[string]interface{}) error {
 	return errors.New("error simulado patch")
 }
-func (m *errorMockRepo) Delete(id string) error { return errors.New("error simulado delete") }
-func (m *errorMockRepo) GetMetrics() (map[string]interface{}, error) {
+func (m *errorMockRepo) Delete(ctx context.Context, id string) error {
+	return errors.New("error simulado delete")
+}
+func (m *errorMockRepo) GetPriceDistribution(ctx context.Context, q domain.PriceQuery) (*domain.PriceDistribution, error) {
+	return nil, errors.New("error simulado prices")
+}
+func (m *errorMockRepo) FindLowStock(ctx context.Context, threshold int) ([]domain.Product, error) {
+	return nil, errors.New("error simulado lowstock")
+}
+func (m *errorMockRepo) FindRecent(ctx context.Context, limit int) ([]domain.Product, error) {
+	return nil, errors.New("error simulado recent")
+}
+func (m *errorMockRepo) GetMetrics(ctx context.Context, q domain.MetricsQuery) (map[string]interface{}, error) {
 	return nil, errors.New("error simulado metrics")
 }
-func (m *errorMockRepo) GetCategories() ([]string, error) {
+func (m *errorMockRepo) GetCategories(ctx context.Context) ([]string, error) {
 	return nil, errors.New("error simulado categories")
 }
 
@@ -63,7 +99,7 @@ func TestCreateProduct(t *testing.T) {
 	repo := &mockRepo{}
 	service := NewProductService(repo)
 
-	err := service.Create(&domain.Product{Name: "Nuevo Producto"})
+	err := service.Create(context.Background(), &domain.Product{Name: "Nuevo Producto"})
 
 	assert.NoError(t, err)
 }
@@ -72,7 +108,7 @@ func TestGetAll(t *testing.T) {
 	repo := &mockRepo{}
 	service := NewProductService(repo)
 
-	list, err := service.GetAll()
+	list, err := service.GetAll(context.Background())
 
 	assert.NoError(t, err)
 	assert.Len(t, list, 1)
@@ -83,7 +119,7 @@ func TestGetByID(t *testing.T) {
 	repo := &mockRepo{}
 	service := NewProductService(repo)
 
-	product, err := service.GetByID("123")
+	product, err := service.GetByID(context.Background(), "123")
 
 	assert.NoError(t, err)
 	assert.Equal(t, "123", product.ID)
@@ -94,7 +130,7 @@ func TestGetByCategory(t *testing.T) {
 	repo := &mockRepo{}
 	service := NewProductService(repo)
 
-	prods, err := service.GetByCategory("Accesorios")
+	prods, err := service.GetByCategory(context.Background(), "Accesorios")
 
 	assert.NoError(t, err)
 	assert.Equal(t, "Accesorios", prods[0].Category)
@@ -104,7 +140,7 @@ func TestGetMetrics(t *testing.T) {
 	repo := &mockRepo{}
 	service := NewProductService(repo)
 
-	data, err := service.GetMetrics()
+	data, err := service.GetMetrics(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, 3, data["total_products"])
@@ -118,7 +154,7 @@ func TestUpdateProduct(t *testing.T) {
 	service := NewProductService(repo)
 
 	p := &domain.Product{ID: "123", Name: "Nuevo nombre"}
-	err := service.Update(p)
+	err := service.Update(context.Background(), p)
 
 	assert.NoError(t, err)
 }
@@ -128,7 +164,7 @@ func TestUpdateProduct_Error(t *testing.T) {
 	service := NewProductService(repo)
 
 	p := &domain.Product{ID: "123", Name: "Error"}
-	err := service.Update(p)
+	err := service.Update(context.Background(), p)
 
 	assert.Error(t, err)
 	assert.Equal(t, "error simulado update", err.Error())
@@ -138,7 +174,7 @@ func TestPatchProduct(t *testing.T) {
 	repo := &mockRepo{}
 	service := NewProductService(repo)
 
-	err := service.Patch("123", map[string]interface{}{"price": 99.9})
+	err := service.Patch(context.Background(), "123", map[string]interface{}{"price": 99.9})
 	assert.NoError(t, err)
 }
 
@@ -146,7 +182,7 @@ func TestPatchProduct_Error(t *testing.T) {
 	repo := &errorMockRepo{}
 	service := NewProductService(repo)
 
-	err := service.Patch("123", map[string]interface{}{"price": 99.9})
+	err := service.Patch(context.Background(), "123", map[string]interface{}{"price": 99.9})
 	assert.Error(t, err)
 	assert.Equal(t, "error simulado patch", err.Error())
 }
@@ -155,7 +191,7 @@ func TestDeleteProduct(t *testing.T) {
 	repo := &mockRepo{}
 	service := NewProductService(repo)
 
-	err := service.Delete("123")
+	err := service.Delete(context.Background(), "123")
 	assert.NoError(t, err)
 }
 
@@ -163,7 +199,86 @@ func TestDeleteProduct_Error(t *testing.T) {
 	repo := &errorMockRepo{}
 	service := NewProductService(repo)
 
-	err := service.Delete("123")
+	err := service.Delete(context.Background(), "123")
 	assert.Error(t, err)
 	assert.Equal(t, "error simulado delete", err.Error())
 }
+
+type staticCategories struct{}
+
+func (staticCategories) Tree(context.Context) (*category.Tree, error) {
+	return category.NewTree([]category.Category{
+		{ID: "1", Name: "Fútbol", Slug: "futbol"},
+		{ID: "2", Name: "Calzado", Slug: "calzado", ParentID: "1"},
+	}), nil
+}
+
+func TestGetByCategoryTree(t *testing.T) {
+	service := NewProductService(&mockRepo{})
+	service.Categories = staticCategories{}
+
+	prods, err := service.GetByCategoryTree(context.Background(), "Fútbol ")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "futbol", prods[0].Category)
+	assert.Equal(t, []category.Breadcrumb{{Name: "Fútbol", Slug: "futbol"}}, prods[0].Breadcrumbs)
+}
+
+func TestCreateProduct_NormalizesCategory(t *testing.T) {
+	service := NewProductService(&mockRepo{})
+	service.Categories = staticCategories{}
+
+	p := &domain.Product{Name: "Guayos", Category: "CALZADO "}
+	err := service.Create(context.Background(), p)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "calzado", p.Category)
+	assert.Len(t, p.Breadcrumbs, 2)
+}
+
+type upperBrands struct{}
+
+func (upperBrands) Canonical(_ context.Context, brand string) (string, error) {
+	if brand == "NIKE" || brand == "nike" {
+		return "Nike", nil
+	}
+	return brand, nil
+}
+
+func TestSearch_NormalizesBrand(t *testing.T) {
+	service := NewProductService(&mockRepo{})
+	service.Brands = upperBrands{}
+
+	prods, err := service.Search(context.Background(), domain.ProductFilter{Brands: []string{"NIKE"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Nike", prods[0].Brand)
+}
+
+func TestPatchProduct_NormalizesBrand(t *testing.T) {
+	service := NewProductService(&mockRepo{})
+	service.Brands = upperBrands{}
+
+	fields := map[string]interface{}{"brand": "nike"}
+	err := service.Patch(context.Background(), "123", fields)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Nike", fields["brand"])
+}
+
+func TestGetPriceDistribution_DefaultBuckets(t *testing.T) {
+	service := NewProductService(&mockRepo{})
+
+	dist, err := service.GetPriceDistribution(context.Background(), domain.PriceQuery{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DefaultPriceBuckets, dist.Count)
+}
+
+func TestGetPriceDistribution_InvalidBoundaries(t *testing.T) {
+	service := NewProductService(&mockRepo{})
+
+	_, err := service.GetPriceDistribution(context.Background(), domain.PriceQuery{Boundaries: []float64{100, 50}})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPriceBuckets)
+}
@@ -1,44 +1,374 @@
 package usecase
 
-import "mlsport/internal/product/domain"
+import (
+	"context"
+	"log/slog"
+	"mlsport/internal/auth"
+	"mlsport/internal/events"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/slug"
+	"mlsport/internal/tenant"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
 
 type ProductService struct {
-	Repo domain.ProductRepository
+	Repo       domain.ProductRepository
+	Categories domain.CategoryResolver
+	Brands     domain.BrandResolver
+	// LowStockThreshold aplica a los productos sin umbral de reposición propio.
+	LowStockThreshold int
+	// Counters, si está configurado, se actualiza con cada escritura (modelo de lectura de métricas).
+	// Es para repositorios que no los actualizan por su cuenta: la diferencia se calcula con lecturas
+	// fuera de la escritura, así que escrituras concurrentes pueden descuadrarlos. El repositorio de
+	// Mongo los aplica en la transacción de la escritura (MongoProductRepo.Counters).
+	Counters domain.CounterRepository
+	// Events, si está configurado, recibe los eventos de dominio de cada alta, modificación y baja.
+	Events events.Publisher
+	// EnforcePermissions exige que el ctx de cada escritura traiga un usuario con permiso para el
+	// cambio concreto: stock, precio y el resto de los datos se autorizan por separado.
+	EnforcePermissions bool
 }
 
 func NewProductService(repo domain.ProductRepository) *ProductService {
-	return &ProductService{Repo: repo}
+	return &ProductService{Repo: repo, LowStockThreshold: domain.DefaultLowStockThreshold}
+}
+
+func (s *ProductService) Create(ctx context.Context, p *domain.Product) (err error) {
+	ctx, span := startSpan(ctx, "Create")
+	defer func() { endSpan(span, err) }()
+	if err := s.authorize(ctx, auth.PermProductCreate); err != nil {
+		return err
+	}
+	if err := s.normalize(ctx, p); err != nil {
+		return err
+	}
+	if err := s.Repo.Create(ctx, p); err != nil {
+		return err
+	}
+	s.decorateWritten(ctx, p)
+	s.changed(ctx, nil, p, nil)
+	return nil
+}
+
+func (s *ProductService) GetAll(ctx context.Context) (_ []domain.Product, err error) {
+	ctx, span := startSpan(ctx, "GetAll")
+	defer func() { endSpan(span, err) }()
+	list, err := s.Repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return list, s.withBreadcrumbs(ctx, list)
+}
+
+// Search filtra por categoría y marca, normalizando los valores recibidos.
+func (s *ProductService) Search(ctx context.Context, filter domain.ProductFilter) (_ []domain.Product, err error) {
+	ctx, span := startSpan(ctx, "Search")
+	defer func() { endSpan(span, err) }()
+	filter, err = s.normalizeFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.Repo.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return list, s.withBreadcrumbs(ctx, list)
+}
+
+func (s *ProductService) GetByID(ctx context.Context, id string) (_ *domain.Product, err error) {
+	ctx, span := startSpan(ctx, "GetByID", attribute.String("mlsport.product_id", id))
+	defer func() { endSpan(span, err) }()
+	p, err := s.Repo.FindByID(ctx, id)
+	if err != nil || p == nil {
+		return p, err
+	}
+	return p, s.withBreadcrumbs(ctx, nil, p)
+}
+func (s *ProductService) GetCategories(ctx context.Context) (_ []string, err error) {
+	ctx, span := startSpan(ctx, "GetCategories")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.GetCategories(ctx)
+}
+func (s *ProductService) GetByCategory(ctx context.Context, cat string) (_ []domain.Product, err error) {
+	ctx, span := startSpan(ctx, "GetByCategory", attribute.String("mlsport.category", cat))
+	defer func() { endSpan(span, err) }()
+	if s.Categories != nil {
+		cat = slug.Make(cat)
+	}
+	list, err := s.Repo.FindByCategory(ctx, cat)
+	if err != nil {
+		return nil, err
+	}
+	return list, s.withBreadcrumbs(ctx, list)
+}
+
+// GetByCategoryTree incluye los productos de todas las subcategorías de cat.
+func (s *ProductService) GetByCategoryTree(ctx context.Context, cat string) (_ []domain.Product, err error) {
+	ctx, span := startSpan(ctx, "GetByCategoryTree", attribute.String("mlsport.category", cat))
+	defer func() { endSpan(span, err) }()
+	if s.Categories == nil {
+		return s.GetByCategory(ctx, cat)
+	}
+
+	tree, err := s.Categories.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.Repo.FindByCategory(ctx, tree.Descendants(slug.Make(cat))...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Breadcrumbs = tree.Breadcrumbs(list[i].Category)
+	}
+	return list, nil
+}
+
+func (s *ProductService) Update(ctx context.Context, p *domain.Product) (err error) {
+	ctx, span := startSpan(ctx, "Update", attribute.String("mlsport.product_id", p.ID))
+	defer func() { endSpan(span, err) }()
+	if err := s.normalize(ctx, p); err != nil {
+		return err
+	}
+	before := s.current(ctx, p.ID)
+	if s.EnforcePermissions {
+		if err := auth.Authorize(ctx, replacePermissions(before, p)...); err != nil {
+			return err
+		}
+	}
+	if err := s.Repo.Update(ctx, p); err != nil {
+		return err
+	}
+	s.decorateWritten(ctx, p)
+	if before != nil {
+		s.changed(ctx, before, p, nil)
+	}
+	return nil
 }
 
-func (s *ProductService) Create(p *domain.Product) error {
-	return s.Repo.Create(p)
+func (s *ProductService) Patch(ctx context.Context, id string, fields map[string]interface{}) (err error) {
+	ctx, span := startSpan(ctx, "Patch", attribute.String("mlsport.product_id", id))
+	defer func() { endSpan(span, err) }()
+	if err := s.authorize(ctx, patchPermissions(fields)...); err != nil {
+		return err
+	}
+	if cat, ok := fields["category"].(string); ok && s.Categories != nil {
+		fields["category"] = slug.Make(cat)
+	}
+	if brand, ok := fields["brand"].(string); ok && s.Brands != nil {
+		canonical, err := s.Brands.Canonical(ctx, brand)
+		if err != nil {
+			return err
+		}
+		fields["brand"] = canonical
+	}
+
+	before := s.current(ctx, id)
+	if err := s.Repo.Patch(ctx, id, fields); err != nil {
+		return err
+	}
+	if before == nil {
+		return nil
+	}
+	if after := s.current(ctx, id); after != nil {
+		applied := make(map[string]interface{}, len(fields))
+		for k, v := range fields {
+			applied[k] = v
+		}
+		s.changed(ctx, before, after, applied)
+	}
+	return nil
+}
+
+func (s *ProductService) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "Delete", attribute.String("mlsport.product_id", id))
+	defer func() { endSpan(span, err) }()
+	if err := s.authorize(ctx, auth.PermProductDelete); err != nil {
+		return err
+	}
+	before := s.current(ctx, id)
+	if err := s.Repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if before != nil {
+		s.changed(ctx, before, nil, nil)
+	}
+	return nil
+}
+func (s *ProductService) GetMetrics(ctx context.Context) (_ map[string]interface{}, err error) {
+	ctx, span := startSpan(ctx, "GetMetrics")
+	defer func() { endSpan(span, err) }()
+	return s.QueryMetrics(ctx, domain.MetricsQuery{})
+}
+
+// QueryMetrics calcula totales y desglose por categoría y marca, opcionalmente filtrados.
+func (s *ProductService) QueryMetrics(ctx context.Context, q domain.MetricsQuery) (_ map[string]interface{}, err error) {
+	ctx, span := startSpan(ctx, "QueryMetrics")
+	defer func() { endSpan(span, err) }()
+	filter, err := s.normalizeFilter(ctx, q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	q.Filter = filter
+	if q.TopN <= 0 {
+		q.TopN = domain.DefaultMetricsTopN
+	}
+	if q.LowStockThreshold <= 0 {
+		q.LowStockThreshold = s.LowStockThreshold
+	}
+	return s.Repo.GetMetrics(ctx, q)
+}
+
+// GetLowStock lista los productos por debajo de su umbral; threshold <= 0 usa el general.
+func (s *ProductService) GetLowStock(ctx context.Context, threshold int) (_ []domain.Product, err error) {
+	ctx, span := startSpan(ctx, "GetLowStock")
+	defer func() { endSpan(span, err) }()
+	if threshold <= 0 {
+		threshold = s.LowStockThreshold
+	}
+	list, err := s.Repo.FindLowStock(ctx, threshold)
+	if err != nil {
+		return nil, err
+	}
+	return list, s.withBreadcrumbs(ctx, list)
+}
+
+// GetPriceDistribution devuelve percentiles e histograma de precios, opcionalmente filtrados.
+func (s *ProductService) GetPriceDistribution(ctx context.Context, q domain.PriceQuery) (_ *domain.PriceDistribution, err error) {
+	ctx, span := startSpan(ctx, "GetPriceDistribution")
+	defer func() { endSpan(span, err) }()
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	filter, err := s.normalizeFilter(ctx, q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	q.Filter = filter
+	if len(q.Boundaries) == 0 && q.Buckets <= 0 {
+		q.Buckets = domain.DefaultPriceBuckets
+	}
+	return s.Repo.GetPriceDistribution(ctx, q)
 }
 
-func (s *ProductService) GetAll() ([]domain.Product, error) {
-	return s.Repo.FindAll()
+// GetRecent devuelve los últimos productos creados o modificados.
+func (s *ProductService) GetRecent(ctx context.Context, limit int) (_ []domain.Product, err error) {
+	ctx, span := startSpan(ctx, "GetRecent")
+	defer func() { endSpan(span, err) }()
+	list, err := s.Repo.FindRecent(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return list, s.withBreadcrumbs(ctx, list)
 }
 
-func (s *ProductService) GetByID(id string) (*domain.Product, error) {
-	return s.Repo.FindByID(id)
+// current lee el producto antes o después de una escritura para calcular la variación de contadores,
+// publicar el cambio y autorizar un reemplazo; si nada lo necesita no consulta.
+func (s *ProductService) current(ctx context.Context, id string) *domain.Product {
+	if s.Counters == nil && s.Events == nil && !s.EnforcePermissions {
+		return nil
+	}
+	p, err := s.Repo.FindByID(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return p
 }
-func (s *ProductService) GetCategories() ([]string, error) {
-	return s.Repo.GetCategories()
+
+// changed actualiza los contadores y publica los eventos de la escritura. Los errores de los
+// suscriptores no revierten la escritura, solo se registran.
+func (s *ProductService) changed(ctx context.Context, before, after *domain.Product, fields map[string]interface{}) {
+	s.track(ctx, before, after)
+	if s.Events == nil {
+		return
+	}
+
+	evs := domain.ProductEventsFor(before, after, fields, time.Now().UTC())
+	if err := s.Events.Publish(ctx, evs...); err != nil {
+		slog.ErrorContext(ctx, "Error publicando eventos de producto", logging.Err(err))
+	}
 }
-func (s *ProductService) GetByCategory(cat string) ([]domain.Product, error) {
-	return s.Repo.FindByCategory(cat)
+
+// track suma a los contadores de la tienda la diferencia entre before y after (nil = no existe). Un error
+// aquí no revierte la escritura: el descuadre se detecta con el chequeo de consistencia y se corrige con rebuild.
+func (s *ProductService) track(ctx context.Context, before, after *domain.Product) {
+	if s.Counters == nil {
+		return
+	}
+
+	delta := domain.ChangeDelta(before, after)
+	if delta.IsZero() {
+		return
+	}
+
+	if err := s.Counters.Apply(tenant.ID(ctx), delta); err != nil {
+		slog.ErrorContext(ctx, "Error actualizando contadores de métricas", logging.Err(err))
+	}
 }
 
-func (s *ProductService) Update(p *domain.Product) error {
-	return s.Repo.Update(p)
+func (s *ProductService) normalize(ctx context.Context, p *domain.Product) error {
+	if s.Categories != nil {
+		p.Category = slug.Make(p.Category)
+	}
+	if s.Brands != nil {
+		canonical, err := s.Brands.Canonical(ctx, p.Brand)
+		if err != nil {
+			return err
+		}
+		p.Brand = canonical
+	}
+	return nil
 }
 
-func (s *ProductService) Patch(id string, fields map[string]interface{}) error {
-	return s.Repo.Patch(id, fields)
+func (s *ProductService) normalizeFilter(ctx context.Context, f domain.ProductFilter) (domain.ProductFilter, error) {
+	out := domain.ProductFilter{}
+	for _, cat := range f.Categories {
+		if s.Categories != nil {
+			cat = slug.Make(cat)
+		}
+		out.Categories = append(out.Categories, cat)
+	}
+	for _, brand := range f.Brands {
+		if s.Brands != nil {
+			canonical, err := s.Brands.Canonical(ctx, brand)
+			if err != nil {
+				return out, err
+			}
+			brand = canonical
+		}
+		out.Brands = append(out.Brands, brand)
+	}
+	return out, nil
 }
 
-func (s *ProductService) Delete(id string) error {
-	return s.Repo.Delete(id)
+// decorateWritten no hace fallar una escritura ya persistida si el árbol no está disponible.
+func (s *ProductService) decorateWritten(ctx context.Context, p *domain.Product) {
+	if err := s.withBreadcrumbs(ctx, nil, p); err != nil {
+		slog.ErrorContext(ctx, "Error cargando breadcrumbs", logging.Err(err))
+	}
 }
-func (s *ProductService) GetMetrics() (map[string]interface{}, error) {
-	return s.Repo.GetMetrics()
+
+// withBreadcrumbs completa la ruta de categorías de los productos con una sola carga del árbol.
+func (s *ProductService) withBreadcrumbs(ctx context.Context, list []domain.Product, extra ...*domain.Product) error {
+	if s.Categories == nil {
+		return nil
+	}
+
+	tree, err := s.Categories.Tree(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range list {
+		list[i].Breadcrumbs = tree.Breadcrumbs(list[i].Category)
+	}
+	for _, p := range extra {
+		p.Breadcrumbs = tree.Breadcrumbs(p.Category)
+	}
+	return nil
 }
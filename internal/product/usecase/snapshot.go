@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+	"time"
+)
+
+// SnapshotService guarda una foto diaria de las métricas de cada tienda y arma series y comparaciones.
+type SnapshotService struct {
+	Products *ProductService
+	Repo     domain.SnapshotRepository
+	// Tenants, si está configurado, lista las tiendas que Run fotografía; sin él, solo la por defecto.
+	Tenants tenant.Lister
+	now     func() time.Time
+}
+
+func NewSnapshotService(products *ProductService, repo domain.SnapshotRepository) *SnapshotService {
+	return &SnapshotService{Products: products, Repo: repo, now: time.Now}
+}
+
+// Run actualiza el snapshot del día de cada tienda cada interval; el último del día queda como cierre.
+func (s *SnapshotService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := tenant.Each(ctx, s.Tenants, func(ctx context.Context) error {
+			_, err := s.Take(ctx)
+			return err
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Error guardando snapshot de métricas", logging.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Take guarda el snapshot del día de la tienda del contexto.
+func (s *SnapshotService) Take(ctx context.Context) (*domain.MetricsSnapshot, error) {
+	metrics, err := s.Products.GetMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	snap := &domain.MetricsSnapshot{
+		Tenant:        tenant.ID(ctx),
+		Date:          domain.Day(now),
+		TakenAt:       now,
+		TotalProducts: int(number(metrics["total_products"])),
+		TotalStock:    int(number(metrics["total_stock"])),
+		AveragePrice:  number(metrics["average_price"]),
+		StockValue:    number(metrics["stock_value"]),
+		LowStock:      int(number(metrics["low_stock"])),
+		Metrics:       metrics,
+	}
+	if err := s.Repo.Save(ctx, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// History agrupa los snapshots por día, semana o mes usando el cierre de cada periodo.
+func (s *SnapshotService) History(ctx context.Context, from, to time.Time, granularity string) ([]domain.MetricsPoint, error) {
+	if _, err := domain.PeriodStart(from, granularity); err != nil {
+		return nil, err
+	}
+
+	snaps, err := s.Repo.FindRange(ctx, domain.Day(from), domain.Day(to))
+	if err != nil {
+		return nil, err
+	}
+
+	points := []domain.MetricsPoint{}
+	for _, snap := range snaps {
+		period, _ := domain.PeriodStart(snap.Date, granularity)
+		point := snap.Point(period)
+		if n := len(points); n > 0 && points[n-1].Period.Equal(period) {
+			points[n-1] = point
+			continue
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// Compare calcula la diferencia entre el cierre de [from, to] y el de [prevFrom, prevTo].
+func (s *SnapshotService) Compare(ctx context.Context, from, to, prevFrom, prevTo time.Time) (*domain.MetricsComparison, error) {
+	current, err := s.closing(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.closing(ctx, prevFrom, prevTo)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &domain.MetricsComparison{Current: current, Previous: previous}
+	if current != nil && previous != nil {
+		cmp.Delta = domain.NewMetricsDelta(*current, *previous)
+	}
+	return cmp, nil
+}
+
+func (s *SnapshotService) closing(ctx context.Context, from, to time.Time) (*domain.MetricsPoint, error) {
+	snaps, err := s.Repo.FindRange(ctx, domain.Day(from), domain.Day(to))
+	if err != nil || len(snaps) == 0 {
+		return nil, err
+	}
+	last := snaps[len(snaps)-1]
+	point := last.Point(last.Date)
+	return &point, nil
+}
+
+func number(v interface{}) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memorySnapshotRepo struct {
+	byDate map[time.Time]domain.MetricsSnapshot
+}
+
+func (m *memorySnapshotRepo) Save(ctx context.Context, s *domain.MetricsSnapshot) error {
+	s.Tenant = tenant.ID(ctx)
+	m.byDate[s.Date] = *s
+	return nil
+}
+
+func (m *memorySnapshotRepo) FindRange(ctx context.Context, from, to time.Time) ([]domain.MetricsSnapshot, error) {
+	var out []domain.MetricsSnapshot
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if s, ok := m.byDate[d]; ok && s.Tenant == tenant.ID(ctx) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func day(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func seededSnapshots() (*SnapshotService, *memorySnapshotRepo) {
+	repo := &memorySnapshotRepo{byDate: map[time.Time]domain.MetricsSnapshot{}}
+	for i, d := range []string{"2026-03-02", "2026-03-04", "2026-03-09", "2026-03-10"} {
+		repo.byDate[day(d)] = domain.MetricsSnapshot{
+			Tenant:        tenant.Default,
+			Date:          day(d),
+			TotalProducts: 10 + i,
+			TotalStock:    100 * (i + 1),
+			AveragePrice:  50,
+		}
+	}
+	return NewSnapshotService(NewProductService(&mockRepo{}), repo), repo
+}
+
+func TestTakeSnapshot_UpsertsToday(t *testing.T) {
+	service, repo := seededSnapshots()
+	service.now = func() time.Time { return time.Date(2026, 3, 11, 15, 30, 0, 0, time.UTC) }
+
+	snap, err := service.Take(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, day("2026-03-11"), snap.Date)
+	assert.Equal(t, tenant.Default, snap.Tenant)
+	assert.Equal(t, 3, repo.byDate[day("2026-03-11")].TotalProducts)
+	assert.Equal(t, 200, repo.byDate[day("2026-03-11")].TotalStock)
+}
+
+func TestHistory_WeeklyUsesClosingValue(t *testing.T) {
+	service, _ := seededSnapshots()
+
+	points, err := service.History(context.Background(), day("2026-03-01"), day("2026-03-31"), domain.GranularityWeek)
+
+	assert.NoError(t, err)
+	assert.Len(t, points, 2)
+	assert.Equal(t, day("2026-03-02"), points[0].Period)
+	assert.Equal(t, 200, points[0].TotalStock)
+	assert.Equal(t, day("2026-03-09"), points[1].Period)
+	assert.Equal(t, 400, points[1].TotalStock)
+}
+
+func TestHistory_OnlyTenantSnapshots(t *testing.T) {
+	service, _ := seededSnapshots()
+
+	points, err := service.History(tenant.WithID(context.Background(), "otra-tienda"), day("2026-03-01"), day("2026-03-31"), domain.GranularityDay)
+
+	assert.NoError(t, err)
+	assert.Empty(t, points)
+}
+
+func TestHistory_InvalidGranularity(t *testing.T) {
+	service, _ := seededSnapshots()
+
+	_, err := service.History(context.Background(), day("2026-03-01"), day("2026-03-31"), "hour")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidGranularity)
+}
+
+func TestCompare(t *testing.T) {
+	service, _ := seededSnapshots()
+
+	cmp, err := service.Compare(context.Background(), day("2026-03-09"), day("2026-03-15"), day("2026-03-02"), day("2026-03-08"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cmp.Delta.TotalStock/100)
+	assert.Equal(t, 2, cmp.Delta.TotalProducts)
+	assert.Equal(t, 0.0, cmp.Delta.AveragePrice)
+	assert.InDelta(t, 100.0, *cmp.Delta.TotalStockPercent, 1e-9)
+}
+
+func TestCompare_MissingPeriod(t *testing.T) {
+	service, _ := seededSnapshots()
+
+	cmp, err := service.Compare(context.Background(), day("2026-03-09"), day("2026-03-15"), day("2026-01-01"), day("2026-01-31"))
+
+	assert.NoError(t, err)
+	assert.Nil(t, cmp.Previous)
+	assert.Nil(t, cmp.Delta)
+}
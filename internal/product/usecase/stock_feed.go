@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"mlsport/internal/events"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+)
+
+const (
+	DefaultStockFeedHistory      = 1024
+	DefaultStockFeedClientBuffer = 64
+	DefaultMaxSubscriptions      = 200
+)
+
+// StockFeed reparte los cambios de stock y precio a los clientes suscritos a ciertos productos o
+// categorías. Como DashboardStream, guarda un historial para retomar por Seq y desconecta a quien
+// no consume a tiempo. Un cliente solo recibe cambios de productos de su tienda.
+type StockFeed struct {
+	History          int
+	ClientBuffer     int
+	MaxSubscriptions int
+
+	mu      sync.Mutex
+	seq     uint64
+	history []domain.StockEvent
+	clients map[*StockClient]struct{}
+}
+
+// StockClient es una conexión al feed; sus suscripciones se modifican a través del feed.
+type StockClient struct {
+	Events  <-chan domain.StockEvent
+	Dropped <-chan struct{}
+
+	tenant     string
+	events     chan domain.StockEvent
+	dropped    chan struct{}
+	products   map[string]bool
+	categories map[string]bool
+}
+
+func NewStockFeed() *StockFeed {
+	return &StockFeed{
+		History:          DefaultStockFeedHistory,
+		ClientBuffer:     DefaultStockFeedClientBuffer,
+		MaxSubscriptions: DefaultMaxSubscriptions,
+		clients:          map[*StockClient]struct{}{},
+	}
+}
+
+// Handle se suscribe a los eventos de ciclo de vida de productos.
+func (f *StockFeed) Handle(ctx context.Context, e events.Event) error {
+	if change, ok := domain.ChangeFrom(e); ok {
+		f.Publish(ctx, change)
+	}
+	return nil
+}
+
+// Publish reparte el cambio, que pertenece a la tienda del contexto.
+func (f *StockFeed) Publish(ctx context.Context, change domain.ProductChange) {
+	ev, ok := domain.StockEventFrom(change)
+	if !ok {
+		return
+	}
+	ev.Tenant = tenant.ID(ctx)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.seq++
+	ev.Seq = f.seq
+	f.history = append(f.history, ev)
+	if len(f.history) > f.History {
+		f.history = f.history[len(f.history)-f.History:]
+	}
+
+	for c := range f.clients {
+		if !c.matches(ev) {
+			continue
+		}
+		select {
+		case c.events <- ev:
+		default:
+			f.drop(c)
+		}
+	}
+}
+
+// Connect registra un cliente de la tienda del contexto.
+func (f *StockFeed) Connect(ctx context.Context) *StockClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan domain.StockEvent, f.ClientBuffer)
+	dropped := make(chan struct{})
+	c := &StockClient{
+		Events:     ch,
+		Dropped:    dropped,
+		tenant:     tenant.ID(ctx),
+		events:     ch,
+		dropped:    dropped,
+		products:   map[string]bool{},
+		categories: map[string]bool{},
+	}
+	f.clients[c] = struct{}{}
+	return c
+}
+
+func (f *StockFeed) Disconnect(c *StockClient) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.drop(c)
+}
+
+// Subscribe agrega productos y categorías al cliente. Con resume, replay trae los eventos
+// suscritos posteriores a since; complete es false si el historial ya no los tiene todos y el
+// cliente debe volver a consultar el stock actual. seq es el último número emitido.
+func (f *StockFeed) Subscribe(c *StockClient, products, categories []string, since uint64, resume bool) (replay []domain.StockEvent, complete bool, seq uint64, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	added := 0
+	for _, id := range products {
+		if !c.products[id] {
+			added++
+		}
+	}
+	for _, cat := range categories {
+		if !c.categories[domain.CounterKey(cat)] {
+			added++
+		}
+	}
+	if len(c.products)+len(c.categories)+added > f.MaxSubscriptions {
+		return nil, false, f.seq, domain.ErrTooManySubscriptions
+	}
+
+	for _, id := range products {
+		c.products[id] = true
+	}
+	for _, cat := range categories {
+		c.categories[domain.CounterKey(cat)] = true
+	}
+
+	if !resume {
+		return nil, true, f.seq, nil
+	}
+	// Un since mayor al último (p. ej. tras reiniciar el servidor) no se puede retomar.
+	if since > f.seq || since < f.seq && (len(f.history) == 0 || f.history[0].Seq > since+1) {
+		return nil, false, f.seq, nil
+	}
+	for _, ev := range f.history {
+		if ev.Seq > since && c.matches(ev) {
+			replay = append(replay, ev)
+		}
+	}
+	return replay, true, f.seq, nil
+}
+
+func (f *StockFeed) Unsubscribe(c *StockClient, products, categories []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, id := range products {
+		delete(c.products, id)
+	}
+	for _, cat := range categories {
+		delete(c.categories, domain.CounterKey(cat))
+	}
+}
+
+// Subscriptions devuelve los productos y categorías suscritos.
+func (f *StockFeed) Subscriptions(c *StockClient) (products, categories []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	products = make([]string, 0, len(c.products))
+	for id := range c.products {
+		products = append(products, id)
+	}
+	categories = make([]string, 0, len(c.categories))
+	for cat := range c.categories {
+		categories = append(categories, cat)
+	}
+	return products, categories
+}
+
+func (f *StockFeed) Clients() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.clients)
+}
+
+// drop se llama con mu tomado.
+func (f *StockFeed) drop(c *StockClient) {
+	if _, ok := f.clients[c]; !ok {
+		return
+	}
+	delete(f.clients, c)
+	close(c.dropped)
+}
+
+func (c *StockClient) matches(ev domain.StockEvent) bool {
+	if ev.Tenant != c.tenant {
+		return false
+	}
+	return c.products[ev.ProductID] || c.categories[domain.CounterKey(ev.Category)]
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stockChange(id, category string, before, after int) domain.ProductChange {
+	return domain.ProductChange{
+		Type:      domain.ChangeUpdated,
+		ProductID: id,
+		Previous:  &domain.Product{ID: id, Category: category, Stock: before},
+		Product:   &domain.Product{ID: id, Category: category, Stock: after},
+	}
+}
+
+func TestStockFeed_DeliversSubscribedOnly(t *testing.T) {
+	feed := NewStockFeed()
+	client := feed.Connect(context.Background())
+	_, _, _, err := feed.Subscribe(client, []string{"p1"}, []string{"Calzado"}, 0, false)
+	assert.NoError(t, err)
+
+	feed.Publish(context.Background(), stockChange("p1", "ropa", 5, 4))
+	feed.Publish(context.Background(), stockChange("p2", "ropa", 5, 4))
+	feed.Publish(context.Background(), stockChange("p3", "calzado", 2, 1))
+	// Sin cambio de stock ni precio no hay evento.
+	feed.Publish(context.Background(), stockChange("p1", "ropa", 4, 4))
+
+	assert.Len(t, client.Events, 2)
+	first := <-client.Events
+	assert.Equal(t, "p1", first.ProductID)
+	assert.Equal(t, 5, *first.PreviousStock)
+	assert.Equal(t, 4, first.Stock)
+	second := <-client.Events
+	assert.Equal(t, "p3", second.ProductID)
+	assert.Equal(t, uint64(3), second.Seq)
+}
+
+func TestStockFeed_SubscriptionLimit(t *testing.T) {
+	feed := NewStockFeed()
+	feed.MaxSubscriptions = 2
+	client := feed.Connect(context.Background())
+
+	_, _, _, err := feed.Subscribe(client, []string{"p1", "p2"}, nil, 0, false)
+	assert.NoError(t, err)
+	// Repetir una suscripción no cuenta.
+	_, _, _, err = feed.Subscribe(client, []string{"p1"}, nil, 0, false)
+	assert.NoError(t, err)
+	_, _, _, err = feed.Subscribe(client, nil, []string{"ropa"}, 0, false)
+	assert.ErrorIs(t, err, domain.ErrTooManySubscriptions)
+
+	feed.Unsubscribe(client, []string{"p2"}, nil)
+	_, _, _, err = feed.Subscribe(client, nil, []string{"ropa"}, 0, false)
+	assert.NoError(t, err)
+}
+
+func TestStockFeed_Resume(t *testing.T) {
+	feed := NewStockFeed()
+	feed.History = 3
+	for i := 1; i <= 4; i++ {
+		feed.Publish(context.Background(), stockChange("p1", "ropa", i, i+1))
+	}
+
+	client := feed.Connect(context.Background())
+	replay, complete, seq, err := feed.Subscribe(client, []string{"p1"}, nil, 2, true)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, uint64(4), seq)
+	assert.Len(t, replay, 2)
+	assert.Equal(t, uint64(3), replay[0].Seq)
+
+	_, complete, _, _ = feed.Subscribe(client, nil, nil, 0, true)
+	assert.False(t, complete)
+	_, complete, _, _ = feed.Subscribe(client, nil, nil, 10, true)
+	assert.False(t, complete)
+}
+
+func TestStockFeed_DropsSlowClient(t *testing.T) {
+	feed := NewStockFeed()
+	feed.ClientBuffer = 1
+	client := feed.Connect(context.Background())
+	_, _, _, _ = feed.Subscribe(client, nil, []string{"ropa"}, 0, false)
+
+	feed.Publish(context.Background(), stockChange("p1", "ropa", 2, 1))
+	feed.Publish(context.Background(), stockChange("p1", "ropa", 1, 0))
+
+	_, open := <-client.Dropped
+	assert.False(t, open)
+	assert.Equal(t, 0, feed.Clients())
+}
+
+func TestStockFeed_OnlyTenantChanges(t *testing.T) {
+	feed := NewStockFeed()
+	client := feed.Connect(tenant.WithID(context.Background(), "tienda-a"))
+	_, _, _, err := feed.Subscribe(client, []string{"p1"}, []string{"ropa"}, 0, false)
+	assert.NoError(t, err)
+
+	feed.Publish(tenant.WithID(context.Background(), "tienda-b"), stockChange("p1", "ropa", 5, 4))
+	feed.Publish(tenant.WithID(context.Background(), "tienda-a"), stockChange("p1", "ropa", 4, 3))
+
+	ev := <-client.Events
+	assert.Equal(t, 3, ev.Stock)
+	assert.Empty(t, client.Events)
+}
@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"mlsport/internal/events"
+	"mlsport/internal/logging"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+)
+
+const (
+	DefaultStreamHistory         = 256
+	DefaultStreamClientBuffer    = 32
+	DefaultStreamMetricsInterval = 2 * time.Second
+)
+
+// DashboardStream difunde a los clientes del dashboard los cambios de productos y las métricas
+// recalculadas. Guarda los últimos History eventos para retomar con Last-Event-ID; un cliente que no
+// consume a tiempo (más de ClientBuffer eventos pendientes) se desconecta para que se reconecte y
+// retome, en lugar de frenar a los demás o acumular memoria. Cada cliente solo recibe los eventos y
+// las métricas de su tienda.
+type DashboardStream struct {
+	Products        *ProductService
+	History         int
+	ClientBuffer    int
+	MetricsInterval time.Duration
+
+	mu      sync.Mutex
+	seq     uint64
+	history []domain.StreamEvent
+	subs    map[*StreamSubscription]struct{}
+	// pending son las tiendas con métricas por recalcular; dirty avisa a Run.
+	pending map[string]bool
+	dirty   chan struct{}
+}
+
+// StreamSubscription entrega los eventos de un cliente; Dropped se cierra si se quedó atrás.
+type StreamSubscription struct {
+	Events  <-chan domain.StreamEvent
+	Dropped <-chan struct{}
+
+	tenant  string
+	events  chan domain.StreamEvent
+	dropped chan struct{}
+}
+
+func NewDashboardStream(products *ProductService) *DashboardStream {
+	return &DashboardStream{
+		Products:        products,
+		History:         DefaultStreamHistory,
+		ClientBuffer:    DefaultStreamClientBuffer,
+		MetricsInterval: DefaultStreamMetricsInterval,
+		subs:            map[*StreamSubscription]struct{}{},
+		pending:         map[string]bool{},
+		dirty:           make(chan struct{}, 1),
+	}
+}
+
+// Handle se suscribe a los eventos de ciclo de vida de productos.
+func (s *DashboardStream) Handle(ctx context.Context, e events.Event) error {
+	if change, ok := domain.ChangeFrom(e); ok {
+		s.Publish(ctx, change)
+	}
+	return nil
+}
+
+// Publish difunde el cambio a los clientes de la tienda del contexto y agenda el recálculo de sus métricas.
+func (s *DashboardStream) Publish(ctx context.Context, change domain.ProductChange) {
+	id := tenant.ID(ctx)
+	s.broadcast(id, domain.StreamEventProduct, change)
+	s.refresh(id)
+}
+
+// Refresh agenda el recálculo de métricas de todas las tiendas con clientes, sin emitir un cambio
+// (p. ej. tras renombrar categorías, que son compartidas).
+func (s *DashboardStream) Refresh() {
+	s.mu.Lock()
+	for sub := range s.subs {
+		s.pending[sub.tenant] = true
+	}
+	s.mu.Unlock()
+	s.wake()
+}
+
+func (s *DashboardStream) refresh(tenantID string) {
+	s.mu.Lock()
+	s.pending[tenantID] = true
+	s.mu.Unlock()
+	s.wake()
+}
+
+func (s *DashboardStream) wake() {
+	select {
+	case s.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// Run recalcula las métricas tras cada tanda de cambios, como mucho una vez por MetricsInterval.
+func (s *DashboardStream) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.dirty:
+		}
+
+		// Espera para agrupar las escrituras seguidas en un solo recálculo.
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.MetricsInterval):
+		}
+		select {
+		case <-s.dirty:
+		default:
+		}
+
+		s.mu.Lock()
+		pending := s.pending
+		s.pending = map[string]bool{}
+		s.mu.Unlock()
+
+		for id := range pending {
+			metrics, err := s.Products.GetMetrics(tenant.WithID(ctx, id))
+			if err != nil {
+				slog.ErrorContext(ctx, "Error recalculando métricas para el stream", "tenant", id, logging.Err(err))
+				continue
+			}
+			s.broadcast(id, domain.StreamEventMetrics, metrics)
+		}
+	}
+}
+
+// Subscribe registra un cliente de la tienda del contexto. Con resume, replay trae los eventos de la
+// tienda posteriores a lastID; complete es false si ya no están todos en el historial (o no se pidió
+// retomar) y el cliente debe recibir el estado actual antes de seguir.
+func (s *DashboardStream) Subscribe(ctx context.Context, lastID uint64, resume bool) (sub *StreamSubscription, replay []domain.StreamEvent, complete bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan domain.StreamEvent, s.ClientBuffer)
+	dropped := make(chan struct{})
+	sub = &StreamSubscription{Events: ch, Dropped: dropped, tenant: tenant.ID(ctx), events: ch, dropped: dropped}
+	s.subs[sub] = struct{}{}
+
+	if !resume || lastID > s.seq {
+		return sub, nil, false
+	}
+	if lastID == s.seq {
+		return sub, nil, true
+	}
+	if len(s.history) == 0 || s.history[0].ID > lastID+1 {
+		return sub, nil, false
+	}
+	for _, ev := range s.history {
+		if ev.ID > lastID && ev.Tenant == sub.tenant {
+			replay = append(replay, ev)
+		}
+	}
+	return sub, replay, true
+}
+
+func (s *DashboardStream) Unsubscribe(sub *StreamSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop(sub)
+}
+
+// Snapshot devuelve las métricas actuales de la tienda del contexto con el ID del último evento emitido.
+func (s *DashboardStream) Snapshot(ctx context.Context) (domain.StreamEvent, error) {
+	s.mu.Lock()
+	id := s.seq
+	s.mu.Unlock()
+
+	metrics, err := s.Products.GetMetrics(ctx)
+	if err != nil {
+		return domain.StreamEvent{}, err
+	}
+	return domain.StreamEvent{ID: id, Tenant: tenant.ID(ctx), Name: domain.StreamEventMetrics, Data: metrics}, nil
+}
+
+// Clients devuelve cuántos clientes están conectados.
+func (s *DashboardStream) Clients() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subs)
+}
+
+func (s *DashboardStream) broadcast(tenantID, name string, data interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	ev := domain.StreamEvent{ID: s.seq, Tenant: tenantID, Name: name, Data: data}
+	s.history = append(s.history, ev)
+	if len(s.history) > s.History {
+		s.history = s.history[len(s.history)-s.History:]
+	}
+
+	for sub := range s.subs {
+		if sub.tenant != tenantID {
+			continue
+		}
+		select {
+		case sub.events <- ev:
+		default:
+			s.drop(sub)
+		}
+	}
+}
+
+// drop quita al cliente y le avisa por Dropped; se llama con mu tomado.
+func (s *DashboardStream) drop(sub *StreamSubscription) {
+	if _, ok := s.subs[sub]; !ok {
+		return
+	}
+	delete(s.subs, sub)
+	close(sub.dropped)
+}
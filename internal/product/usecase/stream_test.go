@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mlsport/internal/events"
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func change(id string) domain.ProductChange {
+	return domain.ProductChange{Type: domain.ChangeUpdated, ProductID: id}
+}
+
+func TestDashboardStream_ResumeFromHistory(t *testing.T) {
+	stream := NewDashboardStream(NewProductService(&mockRepo{}))
+	stream.History = 3
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		stream.Publish(context.Background(), change(id))
+	}
+
+	sub, replay, complete := stream.Subscribe(context.Background(), 2, true)
+	defer stream.Unsubscribe(sub)
+	assert.True(t, complete)
+	assert.Len(t, replay, 2)
+	assert.Equal(t, uint64(3), replay[0].ID)
+	assert.Equal(t, "4", replay[1].Data.(domain.ProductChange).ProductID)
+
+	// El evento 1 ya salió del historial: hay que enviar el estado actual.
+	_, replay, complete = stream.Subscribe(context.Background(), 0, true)
+	assert.False(t, complete)
+	assert.Empty(t, replay)
+
+	// Un ID mayor al último (p. ej. tras reiniciar el servidor) tampoco se puede retomar.
+	_, _, complete = stream.Subscribe(context.Background(), 99, true)
+	assert.False(t, complete)
+
+	_, replay, complete = stream.Subscribe(context.Background(), 4, true)
+	assert.True(t, complete)
+	assert.Empty(t, replay)
+}
+
+func TestDashboardStream_OnlyTenantEvents(t *testing.T) {
+	stream := NewDashboardStream(NewProductService(&mockRepo{}))
+	a := tenant.WithID(context.Background(), "tienda-a")
+	b := tenant.WithID(context.Background(), "tienda-b")
+
+	subA, _, _ := stream.Subscribe(a, 0, false)
+	defer stream.Unsubscribe(subA)
+	stream.Publish(b, change("1"))
+	stream.Publish(a, change("2"))
+
+	ev := <-subA.Events
+	assert.Equal(t, "2", ev.Data.(domain.ProductChange).ProductID)
+	assert.Empty(t, subA.Events)
+
+	_, replay, complete := stream.Subscribe(b, 0, true)
+	assert.True(t, complete)
+	assert.Len(t, replay, 1)
+	assert.Equal(t, "1", replay[0].Data.(domain.ProductChange).ProductID)
+}
+
+func TestDashboardStream_DropsSlowClient(t *testing.T) {
+	stream := NewDashboardStream(NewProductService(&mockRepo{}))
+	stream.ClientBuffer = 2
+
+	slow, _, _ := stream.Subscribe(context.Background(), 0, false)
+	fast, _, _ := stream.Subscribe(context.Background(), 0, false)
+
+	for _, id := range []string{"1", "2"} {
+		stream.Publish(context.Background(), change(id))
+		<-fast.Events
+	}
+	stream.Publish(context.Background(), change("3"))
+
+	select {
+	case <-slow.Dropped:
+	default:
+		t.Fatal("el cliente lento debió desconectarse")
+	}
+	assert.Equal(t, uint64(3), (<-fast.Events).ID)
+	assert.Equal(t, 1, stream.Clients())
+}
+
+func TestDashboardStream_CoalescesMetrics(t *testing.T) {
+	stream := NewDashboardStream(NewProductService(&mockRepo{}))
+	stream.MetricsInterval = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go stream.Run(ctx)
+
+	sub, _, _ := stream.Subscribe(context.Background(), 0, false)
+	for _, id := range []string{"1", "2", "3"} {
+		stream.Publish(context.Background(), change(id))
+	}
+
+	var names []string
+	timeout := time.After(time.Second)
+	for len(names) < 4 {
+		select {
+		case ev := <-sub.Events:
+			names = append(names, ev.Name)
+		case <-timeout:
+			t.Fatalf("faltan eventos: %v", names)
+		}
+	}
+	assert.Equal(t, []string{"product", "product", "product", "metrics"}, names)
+
+	select {
+	case ev := <-sub.Events:
+		t.Fatalf("evento inesperado %s", ev.Name)
+	case <-time.After(3 * stream.MetricsInterval):
+	}
+}
+
+func TestProductService_PublishesChanges(t *testing.T) {
+	stream := NewDashboardStream(nil)
+	service := NewProductService(&memoryProductRepo{byID: map[string]domain.Product{}})
+	bus := events.NewBus()
+	bus.Subscribe(events.All, stream.Handle)
+	service.Events = bus
+	sub, _, _ := stream.Subscribe(context.Background(), 0, false)
+
+	ctx := context.Background()
+	assert.NoError(t, service.Create(ctx, &domain.Product{ID: "1", Name: "Balón", Stock: 3}))
+	assert.NoError(t, service.Patch(ctx, "1", map[string]interface{}{"stock": 1}))
+	assert.NoError(t, service.Delete(ctx, "1"))
+	// Modificar un producto inexistente no publica nada.
+	assert.NoError(t, service.Update(ctx, &domain.Product{ID: "2"}))
+
+	created := (<-sub.Events).Data.(domain.ProductChange)
+	assert.Equal(t, domain.ChangeCreated, created.Type)
+	assert.Equal(t, 3, created.Product.Stock)
+
+	patched := (<-sub.Events).Data.(domain.ProductChange)
+	assert.Equal(t, domain.ChangeUpdated, patched.Type)
+	assert.Equal(t, 1, patched.Product.Stock)
+
+	deleted := (<-sub.Events).Data.(domain.ProductChange)
+	assert.Equal(t, domain.ChangeDeleted, deleted.Type)
+	assert.Nil(t, deleted.Product)
+
+	assert.Empty(t, sub.Events)
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifica los spans del servicio; el tracer sale del proveedor global, así que sin
+// exportador configurado los spans no se registran.
+const tracerName = "mlsport/internal/product/usecase"
+
+// startSpan abre el span de un método de ProductService; las consultas a Mongo quedan como hijas.
+func startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("mlsport.tenant", tenant.ID(ctx)))
+	return otel.Tracer(tracerName).Start(ctx, "ProductService."+method, trace.WithAttributes(attrs...))
+}
+
+// endSpan cierra el span marcándolo como fallido si el método devolvió un error; un producto
+// inexistente es una respuesta, no una falla.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, domain.ErrProductNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"mlsport/internal/product/domain"
+	"mlsport/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestProductService_TracesEachCall(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	service := NewProductService(&memoryProductRepo{byID: map[string]domain.Product{}})
+	ctx, parent := otel.Tracer("test").Start(tenant.WithID(context.Background(), "tienda-a"), "GET /api/products/:id")
+	require.NoError(t, service.Create(ctx, &domain.Product{ID: "1", Name: "Balón"}))
+	_, err := service.GetByID(ctx, "2")
+	assert.Error(t, err)
+	_, _ = service.GetMetrics(ctx)
+	parent.End()
+
+	spans := recorder.Ended()
+	var names []string
+	for _, s := range spans {
+		names = append(names, s.Name())
+	}
+	assert.Equal(t, []string{
+		"ProductService.Create",
+		"ProductService.GetByID",
+		"ProductService.QueryMetrics",
+		"ProductService.GetMetrics",
+		"GET /api/products/:id",
+	}, names)
+
+	// Los spans del servicio cuelgan del de la petición; QueryMetrics, de GetMetrics.
+	for _, s := range spans[:4] {
+		assert.Contains(t, s.Attributes(), attribute.String("mlsport.tenant", "tienda-a"))
+	}
+	for _, i := range []int{0, 1, 3} {
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[i].Parent().SpanID())
+	}
+	assert.Equal(t, spans[3].SpanContext().SpanID(), spans[2].Parent().SpanID())
+
+	getByID := spans[1]
+	assert.Equal(t, codes.Error, getByID.Status().Code)
+	assert.Contains(t, getByID.Attributes(), attribute.String("mlsport.product_id", "2"))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+}
@@ -0,0 +1,76 @@
+// Package ratelimit limita las peticiones de cada cliente (API key, usuario o IP) con un balde de
+// tokens por clase de ruta, más una cuota diaria. El estado vive en un Store: en memoria para una
+// sola instancia, o uno compartido (p. ej. Redis) cuando hay varias detrás de un balanceador.
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Class agrupa rutas con un mismo límite.
+type Class string
+
+const (
+	ClassReads     Class = "reads"
+	ClassWrites    Class = "writes"
+	ClassExports   Class = "exports"
+	ClassDashboard Class = "dashboard"
+)
+
+// Limit es un balde de Burst tokens que se rellena a razón de Requests cada Period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// PerMinute es un límite de n peticiones por minuto con ráfagas de hasta n.
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Period: time.Minute, Burst: n}
+}
+
+// ParseLimit lee límites como "600/1m" o "600/1m:100" (ráfaga de 100). "0" o vacío es sin límite.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return Limit{}, nil
+	}
+	rate, burst, hasBurst := strings.Cut(s, ":")
+	n, period, ok := strings.Cut(rate, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("límite %q: se espera <peticiones>/<período>", s)
+	}
+	requests, err := strconv.Atoi(n)
+	if err != nil || requests < 0 {
+		return Limit{}, fmt.Errorf("límite %q: cantidad inválida", s)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("límite %q: período inválido", s)
+	}
+	l := Limit{Requests: requests, Period: d, Burst: requests}
+	if hasBurst {
+		if l.Burst, err = strconv.Atoi(burst); err != nil || l.Burst <= 0 {
+			return Limit{}, fmt.Errorf("límite %q: ráfaga inválida", s)
+		}
+	}
+	return l, nil
+}
+
+// Unlimited indica que el límite no restringe.
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0 || l.Burst <= 0
+}
+
+// perSecond es el ritmo de recarga del balde.
+func (l Limit) perSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// String es la política en el formato de la cabecera RateLimit-Policy.
+func (l Limit) String() string {
+	return fmt.Sprintf("%d;w=%d", l.Burst, int(l.Period/time.Second))
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mlsport/internal/auth"
+	"mlsport/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cabeceras de respuesta (draft IETF "RateLimit header fields for HTTP").
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderPolicy     = "RateLimit-Policy"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Limiter aplica los límites por clase de ruta y la cuota diaria a cada cliente. Va después de la
+// autenticación, para identificar al cliente por su API key o usuario; los anónimos, por IP.
+type Limiter struct {
+	Store  Store
+	Limits map[Class]Limit
+	// DailyQuota es el máximo de peticiones por cliente y día UTC, sumando todas las clases; 0 no limita.
+	DailyQuota int64
+	// Classify asigna la clase de la ruta; por defecto, lecturas o escrituras según el método.
+	Classify func(c *gin.Context) Class
+
+	now func() time.Time
+}
+
+func NewLimiter(store Store, limits map[Class]Limit) *Limiter {
+	return &Limiter{Store: store, Limits: limits, Classify: ClassifyMethod, now: time.Now}
+}
+
+// ClassifyMethod clasifica GET y HEAD como lecturas y el resto como escrituras.
+func ClassifyMethod(c *gin.Context) Class {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ClassReads
+	}
+	return ClassWrites
+}
+
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := l.now()
+		client := auth.ClientID(c)
+		class := l.Classify(c)
+
+		if limit, ok := l.Limits[class]; ok && !limit.Unlimited() {
+			d, err := l.Store.Take(c.Request.Context(), string(class)+":"+client, limit, now)
+			if err != nil {
+				// Si el store falla se deja pasar: mejor sin límite que sin servicio.
+				slog.ErrorContext(c.Request.Context(), "Error consultando límite", "client", client, logging.Err(err))
+			} else {
+				l.setHeaders(c, limit, d)
+				if !d.Allowed {
+					reject(c, d.RetryAfter, fmt.Sprintf("demasiadas peticiones (%s), reintente en %s s", class, ceilSeconds(d.RetryAfter)))
+					return
+				}
+			}
+		}
+
+		if l.DailyQuota > 0 {
+			day := now.UTC().Truncate(24 * time.Hour)
+			tomorrow := day.Add(24 * time.Hour)
+			used, err := l.Store.Increment(c.Request.Context(), "quota:"+client+":"+day.Format("2006-01-02"), tomorrow)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Error consultando cuota", "client", client, logging.Err(err))
+			} else if used > l.DailyQuota {
+				reject(c, tomorrow.Sub(now), "cuota diaria agotada")
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+func (l *Limiter) setHeaders(c *gin.Context, limit Limit, d Decision) {
+	policy := limit.String()
+	if l.DailyQuota > 0 {
+		policy += fmt.Sprintf(", %d;w=86400", l.DailyQuota)
+	}
+	c.Header(HeaderLimit, strconv.Itoa(limit.Burst))
+	c.Header(HeaderRemaining, strconv.Itoa(d.Remaining))
+	c.Header(HeaderReset, ceilSeconds(d.Reset))
+	c.Header(HeaderPolicy, policy)
+}
+
+func reject(c *gin.Context, retryAfter time.Duration, message string) {
+	c.Header(HeaderRetryAfter, ceilSeconds(retryAfter))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": message})
+}
+
+// ceilSeconds redondea hacia arriba a segundos enteros, como piden las cabeceras.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mlsport/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit, time.Time) (Decision, error) {
+	return Decision{}, errors.New("store caído")
+}
+
+func (failingStore) Increment(context.Context, string, time.Time) (int64, error) {
+	return 0, errors.New("store caído")
+}
+
+func newRouter(l *Limiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if key := c.GetHeader("X-Test-Key"); key != "" {
+			p := &auth.Principal{Subject: "apikey:" + key, Method: auth.MethodAPIKey}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		}
+	}, l.Middleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/products", ok)
+	r.POST("/products", ok)
+	return r
+}
+
+func call(r *gin.Engine, method, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/products", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	if key != "" {
+		req.Header.Set("X-Test-Key", key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLimiter_PerClientAndClass(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	l := NewLimiter(NewMemoryStore(), map[Class]Limit{ClassReads: PerMinute(2), ClassWrites: PerMinute(1)})
+	l.now = func() time.Time { return now }
+	r := newRouter(l)
+
+	w := call(r, http.MethodGet, "a")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get(HeaderLimit))
+	assert.Equal(t, "1", w.Header().Get(HeaderRemaining))
+	assert.Equal(t, "30", w.Header().Get(HeaderReset))
+	assert.Equal(t, "2;w=60", w.Header().Get(HeaderPolicy))
+
+	assert.Equal(t, http.StatusOK, call(r, http.MethodGet, "a").Code)
+	w = call(r, http.MethodGet, "a")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get(HeaderRetryAfter))
+	assert.Equal(t, "0", w.Header().Get(HeaderRemaining))
+
+	// Las escrituras tienen su propio balde, y cada cliente el suyo; los anónimos van por IP.
+	assert.Equal(t, http.StatusOK, call(r, http.MethodPost, "a").Code)
+	assert.Equal(t, http.StatusOK, call(r, http.MethodGet, "b").Code)
+	assert.Equal(t, http.StatusOK, call(r, http.MethodGet, "").Code)
+
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, http.StatusOK, call(r, http.MethodGet, "a").Code)
+}
+
+func TestLimiter_DailyQuota(t *testing.T) {
+	now := time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC)
+	l := NewLimiter(NewMemoryStore(), map[Class]Limit{})
+	l.DailyQuota = 2
+	l.now = func() time.Time { return now }
+	r := newRouter(l)
+
+	assert.Equal(t, http.StatusOK, call(r, http.MethodGet, "a").Code)
+	assert.Equal(t, http.StatusOK, call(r, http.MethodPost, "a").Code)
+	w := call(r, http.MethodGet, "a")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "3600", w.Header().Get(HeaderRetryAfter))
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, http.StatusOK, call(r, http.MethodGet, "a").Code)
+}
+
+func TestLimiter_FailsOpen(t *testing.T) {
+	l := NewLimiter(failingStore{}, map[Class]Limit{ClassReads: PerMinute(1)})
+	l.DailyQuota = 1
+	r := newRouter(l)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, call(r, http.MethodGet, "a").Code)
+	}
+}
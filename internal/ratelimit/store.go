@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Decision es el resultado de consumir un token.
+type Decision struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter es cuánto falta para el próximo token cuando no se permite.
+	RetryAfter time.Duration
+	// Reset es cuánto falta para que el balde vuelva a estar lleno.
+	Reset time.Duration
+}
+
+// Store guarda los baldes y los contadores de cuota. Las implementaciones compartidas deben hacer
+// cada operación de forma atómica.
+type Store interface {
+	// Take consume un token del balde key con el límite dado.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Decision, error)
+	// Increment suma uno al contador key, que se descarta en expires, y devuelve el total.
+	Increment(ctx context.Context, key string, expires time.Time) (int64, error)
+}
+
+// MemoryStore guarda el estado en el proceso; con varias instancias, cada una limita por su lado.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	counters  map[string]*counter
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time
+}
+
+type counter struct {
+	value   int64
+	expires time.Time
+}
+
+// sweepEvery es cada cuánto se descartan baldes llenos y contadores vencidos.
+const sweepEvery = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, counters: map[string]*counter{}}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	rate := limit.perSecond()
+	burst := float64(limit.Burst)
+	b := s.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*rate)
+		b.last = now
+	}
+
+	d := Decision{}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = seconds((burst - b.tokens) / rate)
+	b.full = now.Add(d.Reset)
+	return d, nil
+}
+
+func (s *MemoryStore) Increment(_ context.Context, key string, expires time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.counters[key]
+	if c == nil {
+		c = &counter{expires: expires}
+		s.counters[key] = c
+	}
+	c.value++
+	return c.value, nil
+}
+
+// sweep descarta los baldes que ya se llenaron (equivalen a uno nuevo) y los contadores vencidos.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepEvery {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, c := range s.counters {
+		if !now.Before(c.expires) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	l, err := ParseLimit("600/1m")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 600, Period: time.Minute, Burst: 600}, l)
+
+	l, err = ParseLimit("10/1s:50")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 10, Period: time.Second, Burst: 50}, l)
+
+	l, err = ParseLimit("0")
+	require.NoError(t, err)
+	assert.True(t, l.Unlimited())
+
+	for _, bad := range []string{"600", "x/1m", "10/soon", "10/1m:0"} {
+		_, err := ParseLimit(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 2, Period: time.Second, Burst: 3}
+	t0 := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	for i := 2; i >= 0; i-- {
+		d, err := store.Take(ctx, "k", limit, t0)
+		require.NoError(t, err)
+		assert.True(t, d.Allowed)
+		assert.Equal(t, i, d.Remaining)
+	}
+	d, _ := store.Take(ctx, "k", limit, t0)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, 500*time.Millisecond, d.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, d.Reset)
+
+	// Otro cliente tiene su propio balde.
+	d, _ = store.Take(ctx, "otro", limit, t0)
+	assert.True(t, d.Allowed)
+
+	// Medio segundo rellena un token, y nunca más que la ráfaga.
+	d, _ = store.Take(ctx, "k", limit, t0.Add(500*time.Millisecond))
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 0, d.Remaining)
+	d, _ = store.Take(ctx, "k", limit, t0.Add(time.Hour))
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 2, d.Remaining)
+}
+
+func TestMemoryStore_SweepsIdleState(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	t0 := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	_, _ = store.Take(ctx, "k", PerMinute(60), t0)
+	_, _ = store.Increment(ctx, "quota:k:2026-10-19", t0.Add(time.Minute))
+	n, _ := store.Increment(ctx, "quota:k:2026-10-19", t0.Add(time.Minute))
+	assert.Equal(t, int64(2), n)
+
+	_, _ = store.Take(ctx, "otro", PerMinute(60), t0.Add(2*time.Minute))
+	assert.NotContains(t, store.buckets, "k")
+	assert.NotContains(t, store.counters, "quota:k:2026-10-19")
+	assert.Contains(t, store.buckets, "otro")
+}
@@ -0,0 +1,14 @@
+package slug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	assert.Equal(t, "calzado", Make("Calzado "))
+	assert.Equal(t, "calzado", Make("CALZADO"))
+	assert.Equal(t, "futbol-sala", Make("Fútbol  Sala"))
+	assert.Equal(t, "new-balance", Make("New Balance"))
+}
@@ -0,0 +1,69 @@
+// Package tenant identifica la tienda (tenant) de cada petición y la lleva en el contexto hasta
+// los repositorios, que filtran por ella. Sin tenant en el contexto se usa Default, así un
+// despliegue de una sola tienda, los comandos y los procesos de fondo siguen funcionando.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// Default es la tienda de los datos anteriores a los tenants y de las peticiones que no indican otra.
+const Default = "default"
+
+// Header permite a los usuarios de la plataforma elegir la tienda; el resto solo puede repetir la suya.
+const Header = "X-Tenant-ID"
+
+var ErrInvalidID = errors.New("el identificador de tienda debe tener de 2 a 63 letras minúsculas, números o guiones")
+
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// ValidID indica si id puede identificar una tienda.
+func ValidID(id string) bool {
+	return validID.MatchString(id)
+}
+
+type idKey struct{}
+
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext devuelve la tienda del contexto; false si no se indicó.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(idKey{}).(string)
+	return id, ok && id != ""
+}
+
+// ID devuelve la tienda del contexto, o Default.
+func ID(ctx context.Context) string {
+	if id, ok := FromContext(ctx); ok {
+		return id
+	}
+	return Default
+}
+
+// Lister devuelve las tiendas activas, para los procesos que recorren todas.
+type Lister interface {
+	ActiveIDs() ([]string, error)
+}
+
+// Each ejecuta fn una vez por tienda activa con el contexto de esa tienda; sin lister, solo para
+// Default. Sigue con las demás si una falla y devuelve el primer error.
+func Each(ctx context.Context, tenants Lister, fn func(ctx context.Context) error) error {
+	ids := []string{Default}
+	if tenants != nil {
+		var err error
+		if ids, err = tenants.ActiveIDs(); err != nil {
+			return err
+		}
+	}
+	var first error
+	for _, id := range ids {
+		if err := fn(WithID(ctx, id)); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
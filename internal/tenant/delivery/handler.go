@@ -0,0 +1,131 @@
+package delivery
+
+import (
+	"errors"
+	"mlsport/internal/logging"
+	"mlsport/internal/tenant/domain"
+	"mlsport/internal/tenant/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	Service *usecase.TenantService
+}
+
+func NewTenantHandler(s *usecase.TenantService) *TenantHandler {
+	return &TenantHandler{Service: s}
+}
+
+// GetAll godoc
+// @Summary Listar tiendas
+// @Description Tiendas alojadas en el despliegue. Solo para usuarios de la plataforma (tenant * en el token).
+// @Tags Tiendas
+// @Produce json
+// @Success 200 {array} domain.Tenant
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tenants [get]
+func (h *TenantHandler) GetAll(c *gin.Context) {
+	list, err := h.Service.GetAll()
+	if err != nil {
+		logging.InternalError(c, "no se pudieron obtener las tiendas", err)
+		return
+	}
+	if list == nil {
+		list = []domain.Tenant{}
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GetByID godoc
+// @Summary Consultar una tienda
+// @Tags Tiendas
+// @Produce json
+// @Param id path string true "ID de la tienda"
+// @Success 200 {object} domain.Tenant
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tenants/{id} [get]
+func (h *TenantHandler) GetByID(c *gin.Context) {
+	t, err := h.Service.GetByID(c.Param("id"))
+	if err != nil {
+		respondError(c, err, "no se pudo obtener la tienda")
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// Create godoc
+// @Summary Dar de alta una tienda
+// @Description Crea una tienda con catálogo propio. El ID (minúsculas, números y guiones) es el que llevan
+// @Description los tokens en el claim "tenant" y la cabecera X-Tenant-ID, y no se puede cambiar.
+// @Tags Tiendas
+// @Accept json
+// @Produce json
+// @Param tenant body domain.Tenant true "ID y nombre"
+// @Success 201 {object} domain.Tenant
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tenants [post]
+func (h *TenantHandler) Create(c *gin.Context) {
+	var input domain.Tenant
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+		return
+	}
+	if err := h.Service.Create(&input); err != nil {
+		respondError(c, err, "no se pudo crear la tienda")
+		return
+	}
+	c.JSON(http.StatusCreated, input)
+}
+
+// Update godoc
+// @Summary Actualizar una tienda
+// @Description Cambia el nombre o la deshabilita (disabled): una tienda deshabilitada rechaza todas las
+// @Description peticiones pero conserva sus datos.
+// @Tags Tiendas
+// @Accept json
+// @Produce json
+// @Param id path string true "ID de la tienda"
+// @Param tenant body domain.Tenant true "Nombre y estado"
+// @Success 200 {object} domain.Tenant
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tenants/{id} [put]
+func (h *TenantHandler) Update(c *gin.Context) {
+	var input domain.Tenant
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+		return
+	}
+	if err := h.Service.Update(c.Param("id"), &input); err != nil {
+		respondError(c, err, "no se pudo actualizar la tienda")
+		return
+	}
+	c.JSON(http.StatusOK, input)
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrTenantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrTenantExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidTenant), errors.Is(err, domain.ErrDefaultTenant):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logging.InternalError(c, fallback, err)
+	}
+}
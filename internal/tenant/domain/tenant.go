@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTenantNotFound = errors.New("tienda no encontrada")
+	ErrTenantExists   = errors.New("la tienda ya existe")
+	ErrInvalidTenant  = errors.New("la tienda necesita un identificador válido y un nombre")
+	ErrDefaultTenant  = errors.New("la tienda por defecto no se puede deshabilitar")
+)
+
+// Tenant es una tienda o vendedor con su propio catálogo. ID es el identificador que usan los
+// tokens y la cabecera X-Tenant-ID, y no cambia.
+type Tenant struct {
+	ID        string    `json:"id" bson:"_id"`
+	Name      string    `json:"name" bson:"name"`
+	Disabled  bool      `json:"disabled" bson:"disabled"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+type TenantRepository interface {
+	// Create devuelve ErrTenantExists si el ID ya está en uso.
+	Create(t *Tenant) error
+	FindAll() ([]Tenant, error)
+	FindByID(id string) (*Tenant, error)
+	Update(t *Tenant) error
+}
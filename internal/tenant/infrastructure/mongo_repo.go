@@ -0,0 +1,90 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mlsport/config"
+	"mlsport/internal/logging"
+	"mlsport/internal/tenant/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoTenantRepo struct {
+	CollectionName string
+}
+
+func NewMongoTenantRepo() *MongoTenantRepo {
+	return &MongoTenantRepo{CollectionName: "tenants"}
+}
+
+func (r *MongoTenantRepo) Create(t *domain.Tenant) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := config.GetDB().Collection(r.CollectionName).InsertOne(ctx, t)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrTenantExists
+	}
+	return err
+}
+
+func (r *MongoTenantRepo) FindAll() ([]domain.Tenant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var result []domain.Tenant
+	collection := config.GetDB().Collection(r.CollectionName)
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var t domain.Tenant
+		if err := cursor.Decode(&t); err != nil {
+			continue
+		}
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (r *MongoTenantRepo) FindByID(id string) (*domain.Tenant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var t domain.Tenant
+	err := config.GetDB().Collection(r.CollectionName).FindOne(ctx, bson.M{"_id": id}).Decode(&t)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *MongoTenantRepo) Update(t *domain.Tenant) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := config.GetDB().Collection(r.CollectionName).ReplaceOne(ctx, bson.M{"_id": t.ID}, t)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrTenantNotFound
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package tenant
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"mlsport/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Directory confirma que una tienda existe y está habilitada.
+type Directory interface {
+	Active(id string) (bool, error)
+}
+
+// Middleware resuelve la tienda de la petición. Los usuarios de la plataforma (claim tenant "*")
+// la eligen con X-Tenant-ID; el resto queda en la de sus credenciales, o en Default si no indican
+// ninguna, y una cabecera distinta se rechaza. Las peticiones anónimas solo ven Default: elegir
+// otra tienda exige credenciales. Va después de la autenticación.
+func Middleware(dir Directory) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requested := strings.TrimSpace(c.GetHeader(Header))
+		id := requested
+		p, authenticated := auth.CurrentPrincipal(c)
+		switch {
+		case authenticated && p.Platform():
+		case authenticated:
+			id = p.Tenant
+			if id == "" {
+				id = Default
+			}
+			if requested != "" && requested != id {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "las credenciales no son de esa tienda"})
+				return
+			}
+		case !authenticated && requested != "" && requested != Default:
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "se requieren credenciales para elegir otra tienda"})
+			return
+		}
+		if id == "" {
+			id = Default
+		}
+		if !ValidID(id) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrInvalidID.Error()})
+			return
+		}
+
+		if dir != nil {
+			active, err := dir.Active(id)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Error consultando la tienda", "tenant", id, "error", err)
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "no se pudo verificar la tienda"})
+				return
+			}
+			if !active {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "tienda desconocida o deshabilitada"})
+				return
+			}
+		}
+		c.Request = c.Request.WithContext(WithID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// PlatformOnly reserva la ruta a los usuarios de la plataforma, como el alta de tiendas.
+func PlatformOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p, ok := auth.CurrentPrincipal(c); !ok || !p.Platform() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "solo para usuarios de la plataforma"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,159 @@
+package tenant
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mlsport/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type directory map[string]bool
+
+func (d directory) Active(id string) (bool, error) {
+	if id == "caida" {
+		return false, errors.New("mongo caído")
+	}
+	return d[id], nil
+}
+
+// platform marca en X-Test-Tenant un usuario de la plataforma; noClaim, uno sin tienda en las credenciales.
+const (
+	platform = auth.AllTenants
+	noClaim  = "(sin claim)"
+)
+
+// fakeAuth autentica con la tienda de X-Test-Tenant.
+func fakeAuth(c *gin.Context) {
+	if id := c.GetHeader("X-Test-Tenant"); id != "" {
+		if id == noClaim {
+			id = ""
+		}
+		p := &auth.Principal{Subject: "user-1", Tenant: id}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+	}
+}
+
+func newRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(fakeAuth, Middleware(directory{Default: true, "tienda-a": true, "tienda-b": true}))
+	r.GET("/products", func(c *gin.Context) {
+		c.String(http.StatusOK, ID(c.Request.Context()))
+	})
+	return r
+}
+
+func call(r *gin.Engine, bound, header string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	if bound != "" {
+		req.Header.Set("X-Test-Tenant", bound)
+	}
+	if header != "" {
+		req.Header.Set(Header, header)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_ResolvesTenant(t *testing.T) {
+	r := newRouter()
+
+	w := call(r, "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, Default, w.Body.String())
+
+	// Un usuario de la plataforma elige la tienda con la cabecera.
+	w = call(r, platform, "tienda-a")
+	assert.Equal(t, "tienda-a", w.Body.String())
+	w = call(r, platform, "")
+	assert.Equal(t, Default, w.Body.String())
+
+	// El token manda; la cabecera solo puede repetir su tienda.
+	w = call(r, "tienda-b", "")
+	assert.Equal(t, "tienda-b", w.Body.String())
+	w = call(r, "tienda-b", "tienda-b")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = call(r, "tienda-b", "tienda-a")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestMiddleware_RejectsUnknownTenants(t *testing.T) {
+	r := newRouter()
+
+	assert.Equal(t, http.StatusBadRequest, call(r, platform, "Tienda A").Code)
+	assert.Equal(t, http.StatusNotFound, call(r, platform, "tienda-z").Code)
+	assert.Equal(t, http.StatusServiceUnavailable, call(r, platform, "caida").Code)
+}
+
+func TestMiddleware_AnonymousOnlySeesDefault(t *testing.T) {
+	r := newRouter()
+
+	w := call(r, "", Default)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, Default, w.Body.String())
+
+	w = call(r, "", "tienda-a")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, call(r, "", "tienda-z").Code)
+}
+
+func TestMiddleware_CredentialsWithoutTenantStayInDefault(t *testing.T) {
+	r := newRouter()
+
+	w := call(r, noClaim, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, Default, w.Body.String())
+	assert.Equal(t, http.StatusOK, call(r, noClaim, Default).Code)
+	assert.Equal(t, http.StatusForbidden, call(r, noClaim, "tienda-b").Code)
+}
+
+func TestMiddleware_JWTWithoutTenantClaimCannotPickTenant(t *testing.T) {
+	secret := []byte("secreto-de-prueba")
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(auth.Required(false, auth.NewJWTVerifier(secret, nil)), Middleware(directory{Default: true, "tienda-b": true}))
+	r.GET("/products", func(c *gin.Context) {
+		c.String(http.StatusOK, ID(c.Request.Context()))
+	})
+	get := func(claims jwt.MapClaims, header string) *httptest.ResponseRecorder {
+		claims["sub"] = "merch-1"
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		assert.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(Header, header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Los tokens emitidos antes de las tiendas no traen el claim: no pueden leer otra.
+	assert.Equal(t, http.StatusForbidden, get(jwt.MapClaims{"roles": []string{"admin"}}, "tienda-b").Code)
+	w := get(jwt.MapClaims{"roles": []string{"admin"}}, "")
+	assert.Equal(t, Default, w.Body.String())
+
+	w = get(jwt.MapClaims{"tenant": auth.AllTenants}, "tienda-b")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "tienda-b", w.Body.String())
+}
+
+func TestPlatformOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(fakeAuth, PlatformOnly())
+	r.GET("/products", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	assert.Equal(t, http.StatusOK, call(r, platform, "").Code)
+	assert.Equal(t, http.StatusForbidden, call(r, noClaim, "").Code)
+	assert.Equal(t, http.StatusForbidden, call(r, "tienda-a", "").Code)
+	assert.Equal(t, http.StatusForbidden, call(r, "", "").Code)
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"mlsport/internal/tenant"
+	"mlsport/internal/tenant/domain"
+)
+
+// DefaultActiveCacheTTL es cuánto se recuerda si una tienda está habilitada; la consulta se hace
+// en cada petición.
+const DefaultActiveCacheTTL = 30 * time.Second
+
+type TenantService struct {
+	Repo     domain.TenantRepository
+	CacheTTL time.Duration
+
+	mu     sync.Mutex
+	active map[string]activeEntry
+	now    func() time.Time
+}
+
+type activeEntry struct {
+	active  bool
+	expires time.Time
+}
+
+func NewTenantService(repo domain.TenantRepository) *TenantService {
+	return &TenantService{
+		Repo:     repo,
+		CacheTTL: DefaultActiveCacheTTL,
+		active:   map[string]activeEntry{},
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Create da de alta la tienda; desde ese momento sus tokens y la cabecera X-Tenant-ID la aceptan.
+func (s *TenantService) Create(t *domain.Tenant) error {
+	t.ID = strings.TrimSpace(t.ID)
+	t.Name = strings.TrimSpace(t.Name)
+	if !tenant.ValidID(t.ID) || t.Name == "" {
+		return domain.ErrInvalidTenant
+	}
+	t.CreatedAt = s.now()
+	t.UpdatedAt = t.CreatedAt
+	if err := s.Repo.Create(t); err != nil {
+		return err
+	}
+	s.forget(t.ID)
+	return nil
+}
+
+func (s *TenantService) GetAll() ([]domain.Tenant, error) {
+	return s.Repo.FindAll()
+}
+
+func (s *TenantService) GetByID(id string) (*domain.Tenant, error) {
+	return s.Repo.FindByID(id)
+}
+
+// Update cambia el nombre y el estado; una tienda deshabilitada rechaza todas las peticiones pero
+// conserva sus datos.
+func (s *TenantService) Update(id string, input *domain.Tenant) error {
+	current, err := s.Repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return domain.ErrInvalidTenant
+	}
+	if id == tenant.Default && input.Disabled {
+		return domain.ErrDefaultTenant
+	}
+
+	input.ID = current.ID
+	input.CreatedAt = current.CreatedAt
+	input.UpdatedAt = s.now()
+	if err := s.Repo.Update(input); err != nil {
+		return err
+	}
+	s.forget(id)
+	return nil
+}
+
+// EnsureDefault crea la tienda por defecto si no existe, para los datos anteriores a los tenants.
+func (s *TenantService) EnsureDefault() error {
+	_, err := s.Repo.FindByID(tenant.Default)
+	if !errors.Is(err, domain.ErrTenantNotFound) {
+		return err
+	}
+	err = s.Create(&domain.Tenant{ID: tenant.Default, Name: "Tienda principal"})
+	if errors.Is(err, domain.ErrTenantExists) {
+		return nil
+	}
+	return err
+}
+
+// Active implementa tenant.Directory. La tienda por defecto siempre está habilitada.
+func (s *TenantService) Active(id string) (bool, error) {
+	if id == tenant.Default {
+		return true, nil
+	}
+	now := s.now()
+	s.mu.Lock()
+	if e, ok := s.active[id]; ok && now.Before(e.expires) {
+		s.mu.Unlock()
+		return e.active, nil
+	}
+	s.mu.Unlock()
+
+	t, err := s.Repo.FindByID(id)
+	if err != nil && !errors.Is(err, domain.ErrTenantNotFound) {
+		return false, err
+	}
+	active := err == nil && !t.Disabled
+
+	s.mu.Lock()
+	s.active[id] = activeEntry{active: active, expires: now.Add(s.CacheTTL)}
+	s.mu.Unlock()
+	return active, nil
+}
+
+// ActiveIDs implementa tenant.Lister para los procesos que recorren todas las tiendas.
+func (s *TenantService) ActiveIDs() ([]string, error) {
+	list, err := s.Repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{tenant.Default}
+	for _, t := range list {
+		if !t.Disabled && t.ID != tenant.Default {
+			ids = append(ids, t.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (s *TenantService) forget(id string) {
+	s.mu.Lock()
+	delete(s.active, id)
+	s.mu.Unlock()
+}
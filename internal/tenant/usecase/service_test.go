@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"mlsport/internal/tenant"
+	"mlsport/internal/tenant/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryTenantRepo struct {
+	byID  map[string]domain.Tenant
+	reads int
+}
+
+func newMemoryTenantRepo() *memoryTenantRepo {
+	return &memoryTenantRepo{byID: map[string]domain.Tenant{}}
+}
+
+func (r *memoryTenantRepo) Create(t *domain.Tenant) error {
+	if _, ok := r.byID[t.ID]; ok {
+		return domain.ErrTenantExists
+	}
+	r.byID[t.ID] = *t
+	return nil
+}
+
+func (r *memoryTenantRepo) FindAll() ([]domain.Tenant, error) {
+	var list []domain.Tenant
+	for _, t := range r.byID {
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+func (r *memoryTenantRepo) FindByID(id string) (*domain.Tenant, error) {
+	r.reads++
+	t, ok := r.byID[id]
+	if !ok {
+		return nil, domain.ErrTenantNotFound
+	}
+	return &t, nil
+}
+
+func (r *memoryTenantRepo) Update(t *domain.Tenant) error {
+	r.byID[t.ID] = *t
+	return nil
+}
+
+func TestTenantService_CreateAndDisable(t *testing.T) {
+	service := NewTenantService(newMemoryTenantRepo())
+	assert.NoError(t, service.EnsureDefault())
+	assert.NoError(t, service.EnsureDefault())
+
+	assert.ErrorIs(t, service.Create(&domain.Tenant{ID: "Tienda A", Name: "A"}), domain.ErrInvalidTenant)
+	assert.ErrorIs(t, service.Create(&domain.Tenant{ID: "tienda-a"}), domain.ErrInvalidTenant)
+	assert.NoError(t, service.Create(&domain.Tenant{ID: "tienda-a", Name: " Tienda A "}))
+	assert.ErrorIs(t, service.Create(&domain.Tenant{ID: "tienda-a", Name: "Otra"}), domain.ErrTenantExists)
+
+	active, err := service.Active("tienda-a")
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	// Deshabilitar se nota enseguida aunque el estado estuviera en caché.
+	assert.NoError(t, service.Update("tienda-a", &domain.Tenant{Name: "Tienda A", Disabled: true}))
+	active, _ = service.Active("tienda-a")
+	assert.False(t, active)
+
+	assert.ErrorIs(t, service.Update(tenant.Default, &domain.Tenant{Name: "Principal", Disabled: true}), domain.ErrDefaultTenant)
+	ids, _ := service.ActiveIDs()
+	assert.Equal(t, []string{tenant.Default}, ids)
+}
+
+func TestTenantService_CachesActive(t *testing.T) {
+	repo := newMemoryTenantRepo()
+	service := NewTenantService(repo)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		active, err := service.Active("tienda-z")
+		assert.NoError(t, err)
+		assert.False(t, active)
+	}
+	assert.Equal(t, 1, repo.reads)
+
+	now = now.Add(service.CacheTTL)
+	_, _ = service.Active("tienda-z")
+	assert.Equal(t, 2, repo.reads)
+}
@@ -0,0 +1,68 @@
+// Package tracing configura OpenTelemetry: el proveedor de trazas, el exportador (OTLP o stdout) y
+// la propagación W3C trace-context, para seguir una petición desde gin hasta cada comando de Mongo.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	// ExporterConsole es el nombre de la especificación de OpenTelemetry para stdout.
+	ExporterConsole = "console"
+)
+
+// Setup instala el proveedor global de trazas con el exportador indicado y devuelve la función que
+// envía los spans pendientes al terminar. Con ExporterNone no se registran spans, pero se sigue
+// propagando el traceparent recibido. OTLP se configura con las variables estándar
+// (OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_HEADERS, ...) y el muestreo con OTEL_TRACES_SAMPLER.
+func Setup(ctx context.Context, exporter, serviceName string, stdout io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var opt sdktrace.TracerProviderOption
+	switch exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("exportador OTLP: %w", err)
+		}
+		opt = sdktrace.WithBatcher(exp)
+	case ExporterStdout, ExporterConsole:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(stdout), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("exportador stdout: %w", err)
+		}
+		// Para uso local: cada span se escribe al cerrarse.
+		opt = sdktrace.WithSyncer(exp)
+	default:
+		return nil, fmt.Errorf("exportador de trazas %q desconocido: use otlp, stdout o none", exporter)
+	}
+
+	// OTEL_SERVICE_NAME y OTEL_RESOURCE_ATTRIBUTES pisan el nombre por defecto.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("recurso de trazas: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(opt, sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup_StdoutExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	var buf bytes.Buffer
+	shutdown, err := Setup(context.Background(), ExporterStdout, "mlsport-test", &buf)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "ProductService.GetAll")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	assert.Contains(t, buf.String(), `"Name": "ProductService.GetAll"`)
+	assert.Contains(t, buf.String(), "mlsport-test")
+}
+
+func TestSetup_PropagatesTraceContext(t *testing.T) {
+	shutdown, err := Setup(context.Background(), ExporterNone, "mlsport-test", nil)
+	require.NoError(t, err)
+	defer func() { _ = shutdown(context.Background()) }()
+
+	// Sin exportador se sigue aceptando y reenviando el traceparent del cliente.
+	incoming := http.Header{}
+	incoming.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(incoming))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+
+	outgoing := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(outgoing))
+	assert.Equal(t, incoming.Get("traceparent"), outgoing.Get("traceparent"))
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), "jaeger", "mlsport-test", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+package delivery
+
+import (
+	"errors"
+	"mlsport/internal/logging"
+	"mlsport/internal/webhook/domain"
+	"mlsport/internal/webhook/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	Service *usecase.WebhookService
+}
+
+func NewWebhookHandler(s *usecase.WebhookService) *WebhookHandler {
+	return &WebhookHandler{Service: s}
+}
+
+// GetAll godoc
+// @Summary Listar webhooks
+// @Description Devuelve los webhooks registrados, sin sus secretos.
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} domain.Webhook
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	list, err := h.Service.GetAll(c.Request.Context())
+	if err != nil {
+		logging.InternalError(c, "no se pudieron obtener los webhooks", err)
+		return
+	}
+	if list == nil {
+		list = []domain.Webhook{}
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GetByID godoc
+// @Summary Consultar un webhook
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "ID del webhook"
+// @Success 200 {object} domain.Webhook
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	w, err := h.Service.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "no se pudo obtener el webhook")
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+// Create godoc
+// @Summary Registrar webhook
+// @Description Suscribe una URL a tipos de evento (p. ej. product.price_changed, o "*" para todos).
+// @Description Cada entrega lleva X-Mlsport-Signature: t=<unix>,v1=<HMAC-SHA256 de "<t>.<cuerpo>" con el secreto>.
+// @Description Si no se envía secreto se genera uno; esta es la única respuesta que lo incluye.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body domain.Webhook true "URL, eventos y secreto opcional"
+// @Success 201 {object} domain.Webhook
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var input domain.Webhook
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+		return
+	}
+	if err := h.Service.Create(c.Request.Context(), &input); err != nil {
+		respondError(c, err, "no se pudo crear el webhook")
+		return
+	}
+	c.JSON(http.StatusCreated, input)
+}
+
+// Update godoc
+// @Summary Actualizar webhook
+// @Description Reemplaza URL, eventos y estado (disabled). Sin secreto se conserva el actual.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del webhook"
+// @Param webhook body domain.Webhook true "Datos actualizados"
+// @Success 200 {object} domain.Webhook
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var input domain.Webhook
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formato inválido"})
+		return
+	}
+	if err := h.Service.Update(c.Request.Context(), c.Param("id"), &input); err != nil {
+		respondError(c, err, "no se pudo actualizar el webhook")
+		return
+	}
+	c.JSON(http.StatusOK, input)
+}
+
+// Delete godoc
+// @Summary Eliminar webhook
+// @Description Borra el webhook; sus entregas pendientes pasan a la lista de muertas.
+// @Tags Webhooks
+// @Param id path string true "ID del webhook"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	if err := h.Service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		respondError(c, err, "no se pudo eliminar el webhook")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "eliminado"})
+}
+
+// GetDeliveries godoc
+// @Summary Registro de entregas
+// @Description Entregas del webhook, de la más reciente a la más antigua, con cada intento (código, error y duración).
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "ID del webhook"
+// @Param status query string false "pending, succeeded o dead"
+// @Param limit query int false "Máximo de entregas (50 por defecto, hasta 500)"
+// @Success 200 {array} domain.Delivery
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	list, err := h.Service.DeliveryLog(c.Request.Context(), c.Param("id"), c.Query("status"), limit)
+	if err != nil {
+		respondError(c, err, "no se pudieron obtener las entregas")
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GetDeadLetters godoc
+// @Summary Entregas muertas
+// @Description Entregas que agotaron sus reintentos, de todos los webhooks.
+// @Tags Webhooks
+// @Produce json
+// @Param limit query int false "Máximo de entregas (50 por defecto, hasta 500)"
+// @Success 200 {array} domain.Delivery
+// @Router /webhooks/dead-letters [get]
+func (h *WebhookHandler) GetDeadLetters(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	list, err := h.Service.DeadLetters(c.Request.Context(), limit)
+	if err != nil {
+		logging.InternalError(c, "no se pudieron obtener las entregas", err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// RetryDelivery godoc
+// @Summary Reintentar entrega
+// @Description Vuelve a poner en cola una entrega, por ejemplo de la lista de muertas.
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "ID de la entrega"
+// @Success 202 {object} domain.Delivery
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /webhooks/deliveries/{id}/retry [post]
+func (h *WebhookHandler) RetryDelivery(c *gin.Context) {
+	d, err := h.Service.Retry(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "no se pudo reintentar la entrega")
+		return
+	}
+	c.JSON(http.StatusAccepted, d)
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrWebhookNotFound), errors.Is(err, domain.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidWebhook), errors.Is(err, domain.ErrUnknownEvent),
+		errors.Is(err, domain.ErrBlockedAddress):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logging.InternalError(c, fallback, err)
+	}
+}
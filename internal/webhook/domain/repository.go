@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+type WebhookRepository interface {
+	Create(w *Webhook) error
+	// FindAll lista los webhooks de una tienda.
+	FindAll(tenantID string) ([]Webhook, error)
+	FindByID(id string) (*Webhook, error)
+	Update(w *Webhook) error
+	Delete(id string) error
+}
+
+type DeliveryRepository interface {
+	// Create devuelve ErrDuplicateDelivery si el webhook ya tiene una entrega con el mismo EventID.
+	Create(d *Delivery) error
+	FindByID(id string) (*Delivery, error)
+	// Due devuelve hasta limit entregas pendientes cuyo próximo intento ya venció.
+	Due(now time.Time, limit int) ([]Delivery, error)
+	// FindByWebhook lista las entregas más recientes de un webhook; status vacío no filtra.
+	FindByWebhook(webhookID, status string, limit int) ([]Delivery, error)
+	FindByStatus(tenantID, status string, limit int) ([]Delivery, error)
+	Update(d *Delivery) error
+}
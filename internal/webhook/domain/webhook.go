@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrWebhookNotFound   = errors.New("webhook no encontrado")
+	ErrInvalidWebhook    = errors.New("la URL debe ser http(s) y hay que indicar al menos un evento")
+	ErrUnknownEvent      = errors.New("tipo de evento desconocido")
+	ErrBlockedAddress    = errors.New("la URL apunta a una dirección interna")
+	ErrDeliveryNotFound  = errors.New("entrega no encontrada")
+	ErrDuplicateDelivery = errors.New("el evento ya tiene una entrega para este webhook")
+)
+
+// AllEvents suscribe el webhook a todos los eventos.
+const AllEvents = "*"
+
+// Cabeceras de cada entrega.
+const (
+	HeaderEvent     = "X-Mlsport-Event"
+	HeaderDelivery  = "X-Mlsport-Delivery"
+	HeaderSignature = "X-Mlsport-Signature"
+)
+
+// Webhook es una suscripción de un partner. Secret firma las entregas; solo se devuelve al crearlo.
+type Webhook struct {
+	ID        string             `json:"id" bson:"-"`
+	ObjectID  primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Tenant    string             `json:"-" bson:"tenant"`
+	URL       string             `json:"url" bson:"url"`
+	Events    []string           `json:"events" bson:"events"`
+	Secret    string             `json:"secret,omitempty" bson:"secret"`
+	Disabled  bool               `json:"disabled" bson:"disabled"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Subscribed indica si el webhook, habilitado, recibe el evento name.
+func (w *Webhook) Subscribed(name string) bool {
+	if w.Disabled {
+		return false
+	}
+	for _, e := range w.Events {
+		if e == name || e == AllEvents {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// Delivery es el envío de un evento a un webhook, con el registro de cada intento.
+type Delivery struct {
+	ID            string             `json:"id" bson:"-"`
+	ObjectID      primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Tenant        string             `json:"-" bson:"tenant"`
+	WebhookID     string             `json:"webhook_id" bson:"webhook_id"`
+	Event         string             `json:"event" bson:"event"`
+	EventID       string             `json:"event_id,omitempty" bson:"event_id,omitempty"`
+	Payload       string             `json:"payload" bson:"payload"`
+	Status        string             `json:"status" bson:"status"`
+	Attempts      []DeliveryAttempt  `json:"attempts" bson:"attempts"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" bson:"next_attempt_at"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	DeliveredAt   *time.Time         `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
+
+type DeliveryAttempt struct {
+	At         time.Time `json:"at" bson:"at"`
+	StatusCode int       `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms" bson:"duration_ms"`
+}
+
+// Sign firma el cuerpo como HMAC-SHA256 de "<timestamp>.<body>". El receptor recalcula la firma y
+// descarta timestamps viejos para evitar reenvíos. La cabecera queda "t=<timestamp>,v1=<firma>".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + strconv.FormatInt(timestamp, 10) + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
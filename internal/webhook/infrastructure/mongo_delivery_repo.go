@@ -0,0 +1,132 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"mlsport/config"
+	"mlsport/internal/webhook/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoDeliveryRepo struct {
+	CollectionName string
+}
+
+func NewMongoDeliveryRepo() *MongoDeliveryRepo {
+	return &MongoDeliveryRepo{CollectionName: "webhook_deliveries"}
+}
+
+// EnsureIndexes crea el índice único que evita entregar dos veces el mismo evento a un webhook y
+// los de las consultas de pendientes y del registro.
+func (r *MongoDeliveryRepo) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "event_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"event_id": bson.M{"$exists": true}}),
+		},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+func (r *MongoDeliveryRepo) Create(d *domain.Delivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	res, err := collection.InsertOne(ctx, d)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicateDelivery
+	}
+	if err != nil {
+		return err
+	}
+
+	d.ObjectID = res.InsertedID.(primitive.ObjectID)
+	d.ID = d.ObjectID.Hex()
+	return nil
+}
+
+func (r *MongoDeliveryRepo) FindByID(id string) (*domain.Delivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrDeliveryNotFound
+	}
+
+	var d domain.Delivery
+	collection := config.GetDB().Collection(r.CollectionName)
+	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d.ID = d.ObjectID.Hex()
+	d.Tenant = tenantOrDefault(d.Tenant)
+	return &d, nil
+}
+
+func (r *MongoDeliveryRepo) Due(now time.Time, limit int) ([]domain.Delivery, error) {
+	filter := bson.M{"status": domain.DeliveryPending, "next_attempt_at": bson.M{"$lte": now}}
+	return r.find(filter, bson.D{{Key: "next_attempt_at", Value: 1}}, limit)
+}
+
+func (r *MongoDeliveryRepo) FindByWebhook(webhookID, status string, limit int) ([]domain.Delivery, error) {
+	filter := bson.M{"webhook_id": webhookID}
+	if status != "" {
+		filter["status"] = status
+	}
+	return r.find(filter, bson.D{{Key: "created_at", Value: -1}}, limit)
+}
+
+func (r *MongoDeliveryRepo) FindByStatus(tenantID, status string, limit int) ([]domain.Delivery, error) {
+	filter := bson.M{"tenant": tenantFilter(tenantID), "status": status}
+	return r.find(filter, bson.D{{Key: "created_at", Value: -1}}, limit)
+}
+
+func (r *MongoDeliveryRepo) Update(d *domain.Delivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": d.ObjectID}, d)
+	return err
+}
+
+func (r *MongoDeliveryRepo) find(filter bson.M, sort bson.D, limit int) ([]domain.Delivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(sort).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+
+	result := []domain.Delivery{}
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].ID = result[i].ObjectID.Hex()
+		result[i].Tenant = tenantOrDefault(result[i].Tenant)
+	}
+	return result, nil
+}
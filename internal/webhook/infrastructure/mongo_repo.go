@@ -0,0 +1,132 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mlsport/config"
+	"mlsport/internal/logging"
+	"mlsport/internal/tenant"
+	"mlsport/internal/webhook/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoWebhookRepo struct {
+	CollectionName string
+}
+
+func NewMongoWebhookRepo() *MongoWebhookRepo {
+	return &MongoWebhookRepo{CollectionName: "webhooks"}
+}
+
+func (r *MongoWebhookRepo) Create(w *domain.Webhook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	res, err := collection.InsertOne(ctx, w)
+	if err != nil {
+		return err
+	}
+
+	w.ObjectID = res.InsertedID.(primitive.ObjectID)
+	w.ID = w.ObjectID.Hex()
+	return nil
+}
+
+func (r *MongoWebhookRepo) FindAll(tenantID string) ([]domain.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var result []domain.Webhook
+	collection := config.GetDB().Collection(r.CollectionName)
+	cursor, err := collection.Find(ctx, bson.M{"tenant": tenantFilter(tenantID)}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error closing cursor", logging.Err(err))
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var w domain.Webhook
+		if err := cursor.Decode(&w); err != nil {
+			continue
+		}
+		w.ID = w.ObjectID.Hex()
+		w.Tenant = tenantOrDefault(w.Tenant)
+		result = append(result, w)
+	}
+
+	return result, nil
+}
+
+func (r *MongoWebhookRepo) FindByID(id string) (*domain.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	var w domain.Webhook
+	collection := config.GetDB().Collection(r.CollectionName)
+	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&w)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w.ID = w.ObjectID.Hex()
+	w.Tenant = tenantOrDefault(w.Tenant)
+	return &w, nil
+}
+
+func (r *MongoWebhookRepo) Update(w *domain.Webhook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": w.ObjectID}, w)
+	return err
+}
+
+func (r *MongoWebhookRepo) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrWebhookNotFound
+	}
+
+	collection := config.GetDB().Collection(r.CollectionName)
+	_, err = collection.DeleteOne(ctx, bson.M{"_id": objID})
+	return err
+}
+
+// tenantFilter incluye en la tienda por defecto los documentos anteriores a los tenants, que no
+// tienen el campo.
+func tenantFilter(tenantID string) interface{} {
+	if tenantID == tenant.Default {
+		return bson.M{"$in": bson.A{tenant.Default, nil}}
+	}
+	return tenantID
+}
+
+func tenantOrDefault(tenantID string) string {
+	if tenantID == "" {
+		return tenant.Default
+	}
+	return tenantID
+}
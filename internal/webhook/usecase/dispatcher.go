@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"mlsport/internal/events"
+	"mlsport/internal/logging"
+	"mlsport/internal/tenant"
+	"mlsport/internal/webhook/domain"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	DefaultDispatchInterval    = 2 * time.Second
+	DefaultDispatchBatch       = 50
+	DefaultDispatchConcurrency = 8
+	DefaultDeliveryMaxAttempts = 8
+	DefaultDeliveryBackoff     = 10 * time.Second
+	DefaultDeliveryMaxBackoff  = time.Hour
+	DefaultDeliveryTimeout     = 10 * time.Second
+)
+
+// Envelope es el cuerpo que recibe el partner. ID identifica el evento y se repite en los
+// reintentos, para que el receptor descarte duplicados.
+type Envelope struct {
+	ID        string       `json:"id"`
+	Event     string       `json:"event"`
+	CreatedAt time.Time    `json:"created_at"`
+	Data      events.Event `json:"data"`
+}
+
+// Dispatcher convierte los eventos del bus en entregas a los webhooks suscritos y las envía,
+// reintentando con espera exponencial hasta MaxAttempts; después quedan como muertas.
+type Dispatcher struct {
+	Webhooks    domain.WebhookRepository
+	Deliveries  domain.DeliveryRepository
+	Client      *http.Client
+	Interval    time.Duration
+	BatchSize   int
+	Concurrency int
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+
+	now  func() time.Time
+	wake chan struct{}
+}
+
+func NewDispatcher(webhooks domain.WebhookRepository, deliveries domain.DeliveryRepository) *Dispatcher {
+	return &Dispatcher{
+		Webhooks:    webhooks,
+		Deliveries:  deliveries,
+		Client:      NewClient(DefaultDeliveryTimeout, false),
+		Interval:    DefaultDispatchInterval,
+		BatchSize:   DefaultDispatchBatch,
+		Concurrency: DefaultDispatchConcurrency,
+		MaxAttempts: DefaultDeliveryMaxAttempts,
+		Backoff:     DefaultDeliveryBackoff,
+		MaxBackoff:  DefaultDeliveryMaxBackoff,
+		now:         func() time.Time { return time.Now().UTC() },
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Handle se suscribe al bus: guarda una entrega pendiente por cada webhook de la tienda del evento
+// interesado en él.
+// Un error hace que el outbox vuelva a entregar el evento; las entregas ya creadas no se duplican
+// porque se identifican por el ID del evento.
+func (d *Dispatcher) Handle(ctx context.Context, e events.Event) error {
+	tenantID := tenant.ID(ctx)
+	hooks, err := d.Webhooks.FindAll(tenantID)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	eventID := events.IDFromContext(ctx)
+	now := d.now()
+	created := 0
+	for _, w := range hooks {
+		if !w.Subscribed(e.EventName()) {
+			continue
+		}
+		if payload == nil {
+			if eventID == "" {
+				if eventID, err = randomID(); err != nil {
+					return err
+				}
+			}
+			if payload, err = json.Marshal(Envelope{ID: eventID, Event: e.EventName(), CreatedAt: now, Data: e}); err != nil {
+				return err
+			}
+		}
+
+		delivery := &domain.Delivery{
+			Tenant:        tenantID,
+			WebhookID:     w.ID,
+			Event:         e.EventName(),
+			EventID:       eventID,
+			Payload:       string(payload),
+			Status:        domain.DeliveryPending,
+			Attempts:      []domain.DeliveryAttempt{},
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		err := d.Deliveries.Create(delivery)
+		if errors.Is(err, domain.ErrDuplicateDelivery) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		created++
+	}
+	if created > 0 {
+		d.Wake()
+	}
+	return nil
+}
+
+// Wake adelanta la próxima vuelta del envío.
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run envía las entregas vencidas cada Interval (o al despertarlo) hasta que se cancela ctx.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Error enviando webhooks", logging.Err(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// RunOnce envía un lote de entregas vencidas y devuelve cuántas se intentaron.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	due, err := d.Deliveries.Due(d.now(), d.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// Sin WithContext: que falle el registro de una entrega no debe cortar los envíos en curso.
+	var g errgroup.Group
+	g.SetLimit(d.Concurrency)
+	for i := range due {
+		delivery := &due[i]
+		g.Go(func() error {
+			return d.attempt(ctx, delivery)
+		})
+	}
+	return len(due), g.Wait()
+}
+
+// attempt hace un envío y registra su resultado en la entrega.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *domain.Delivery) error {
+	hook, err := d.Webhooks.FindByID(delivery.WebhookID)
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		delivery.Attempts = append(delivery.Attempts, domain.DeliveryAttempt{At: d.now(), Error: err.Error()})
+		delivery.Status = domain.DeliveryDead
+		return d.Deliveries.Update(delivery)
+	}
+	if err != nil {
+		return err
+	}
+
+	result := d.send(ctx, hook, delivery)
+	delivery.Attempts = append(delivery.Attempts, result)
+	switch {
+	case result.Error == "":
+		delivery.Status = domain.DeliverySucceeded
+		delivery.DeliveredAt = &result.At
+	case len(delivery.Attempts) >= d.MaxAttempts:
+		delivery.Status = domain.DeliveryDead
+		slog.WarnContext(ctx, "Entrega de webhook muerta", "webhook", hook.URL, "delivery", delivery.ID, "attempts", len(delivery.Attempts), "error", result.Error)
+	default:
+		wait := d.Backoff << (len(delivery.Attempts) - 1)
+		if wait <= 0 || wait > d.MaxBackoff {
+			wait = d.MaxBackoff
+		}
+		delivery.NextAttemptAt = result.At.Add(wait)
+	}
+	return d.Deliveries.Update(delivery)
+}
+
+func (d *Dispatcher) send(ctx context.Context, hook *domain.Webhook, delivery *domain.Delivery) domain.DeliveryAttempt {
+	started := d.now()
+	result := domain.DeliveryAttempt{At: started}
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mlsport-webhooks/1.0")
+	req.Header.Set(domain.HeaderEvent, delivery.Event)
+	req.Header.Set(domain.HeaderDelivery, delivery.ID)
+	req.Header.Set(domain.HeaderSignature, domain.Sign(hook.Secret, started.Unix(), body))
+
+	begin := time.Now()
+	resp, err := d.Client.Do(req)
+	result.DurationMS = time.Since(begin).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.WarnContext(ctx, "Error closing webhook body", logging.Err(err))
+		}
+	}()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Error = fmt.Sprintf("el receptor respondió %d", resp.StatusCode)
+	}
+	return result
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
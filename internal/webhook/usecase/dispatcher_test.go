@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mlsport/internal/events"
+	"mlsport/internal/tenant"
+	"mlsport/internal/webhook/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryWebhookRepo struct {
+	hooks map[string]*domain.Webhook
+}
+
+func newMemoryWebhookRepo(hooks ...domain.Webhook) *memoryWebhookRepo {
+	r := &memoryWebhookRepo{hooks: map[string]*domain.Webhook{}}
+	for i := range hooks {
+		r.hooks[hooks[i].ID] = &hooks[i]
+	}
+	return r
+}
+
+func (r *memoryWebhookRepo) Create(w *domain.Webhook) error {
+	w.ID = "w" + strconv.Itoa(len(r.hooks)+1)
+	copied := *w
+	r.hooks[w.ID] = &copied
+	return nil
+}
+
+func (r *memoryWebhookRepo) FindAll(tenantID string) ([]domain.Webhook, error) {
+	var out []domain.Webhook
+	for _, w := range r.hooks {
+		if w.Tenant == tenantID {
+			out = append(out, *w)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryWebhookRepo) FindByID(id string) (*domain.Webhook, error) {
+	w, ok := r.hooks[id]
+	if !ok {
+		return nil, domain.ErrWebhookNotFound
+	}
+	copied := *w
+	return &copied, nil
+}
+
+func (r *memoryWebhookRepo) Update(w *domain.Webhook) error {
+	copied := *w
+	r.hooks[w.ID] = &copied
+	return nil
+}
+
+func (r *memoryWebhookRepo) Delete(id string) error {
+	delete(r.hooks, id)
+	return nil
+}
+
+type memoryDeliveryRepo struct {
+	mu    sync.Mutex
+	items []*domain.Delivery
+}
+
+func (r *memoryDeliveryRepo) Create(d *domain.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.items {
+		if d.EventID != "" && existing.WebhookID == d.WebhookID && existing.EventID == d.EventID {
+			return domain.ErrDuplicateDelivery
+		}
+	}
+	d.ID = "d" + strconv.Itoa(len(r.items)+1)
+	copied := *d
+	r.items = append(r.items, &copied)
+	return nil
+}
+
+func (r *memoryDeliveryRepo) FindByID(id string) (*domain.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.items {
+		if d.ID == id {
+			copied := *d
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrDeliveryNotFound
+}
+
+func (r *memoryDeliveryRepo) Due(now time.Time, limit int) ([]domain.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.Delivery
+	for _, d := range r.items {
+		if d.Status == domain.DeliveryPending && !d.NextAttemptAt.After(now) && len(out) < limit {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryDeliveryRepo) FindByWebhook(webhookID, status string, limit int) ([]domain.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := []domain.Delivery{}
+	for i := len(r.items) - 1; i >= 0 && len(out) < limit; i-- {
+		if d := r.items[i]; d.WebhookID == webhookID && (status == "" || d.Status == status) {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryDeliveryRepo) FindByStatus(tenantID, status string, limit int) ([]domain.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := []domain.Delivery{}
+	for _, d := range r.items {
+		if d.Tenant == tenantID && d.Status == status && len(out) < limit {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryDeliveryRepo) Update(d *domain.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.items {
+		if existing.ID == d.ID {
+			copied := *d
+			r.items[i] = &copied
+		}
+	}
+	return nil
+}
+
+type priceChanged struct {
+	ProductID string  `json:"product_id"`
+	Current   float64 `json:"current"`
+}
+
+func (priceChanged) EventName() string { return "product.price_changed" }
+
+// receiver es un partner de prueba que verifica la firma y responde con los códigos de statuses (200 al agotarlos).
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+	headers  []http.Header
+}
+
+func (rc *receiver) start(t *testing.T, secret string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sig := r.Header.Get(domain.HeaderSignature)
+		ts, _ := strconv.ParseInt(strings.TrimPrefix(strings.SplitN(sig, ",", 2)[0], "t="), 10, 64)
+		if sig != domain.Sign(secret, ts, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		rc.bodies = append(rc.bodies, string(body))
+		rc.headers = append(rc.headers, r.Header.Clone())
+		status := http.StatusOK
+		if len(rc.statuses) > 0 {
+			status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestDispatcher(url string, events ...string) (*Dispatcher, *memoryDeliveryRepo) {
+	hooks := newMemoryWebhookRepo(domain.Webhook{ID: "w1", Tenant: tenant.Default, URL: url, Events: events, Secret: "s3cr3t"})
+	deliveries := &memoryDeliveryRepo{}
+	d := NewDispatcher(hooks, deliveries)
+	// Los receptores de prueba escuchan en 127.0.0.1.
+	d.Client = NewClient(DefaultDeliveryTimeout, true)
+	return d, deliveries
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	rc := &receiver{}
+	srv := rc.start(t, "s3cr3t")
+	d, deliveries := newTestDispatcher(srv.URL, "product.price_changed")
+
+	ctx := events.ContextWithID(context.Background(), "evt-1")
+	assert.NoError(t, d.Handle(ctx, priceChanged{ProductID: "p1", Current: 99.5}))
+	// El outbox puede volver a entregar el mismo evento: no se duplica.
+	assert.NoError(t, d.Handle(ctx, priceChanged{ProductID: "p1", Current: 99.5}))
+
+	n, err := d.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Len(t, rc.bodies, 1)
+	var env struct {
+		ID    string       `json:"id"`
+		Event string       `json:"event"`
+		Data  priceChanged `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(rc.bodies[0]), &env))
+	assert.Equal(t, "evt-1", env.ID)
+	assert.Equal(t, "product.price_changed", env.Event)
+	assert.Equal(t, 99.5, env.Data.Current)
+	assert.Equal(t, "product.price_changed", rc.headers[0].Get(domain.HeaderEvent))
+	assert.Equal(t, "d1", rc.headers[0].Get(domain.HeaderDelivery))
+
+	delivered := deliveries.items[0]
+	assert.Equal(t, domain.DeliverySucceeded, delivered.Status)
+	assert.Equal(t, http.StatusOK, delivered.Attempts[0].StatusCode)
+	assert.NotNil(t, delivered.DeliveredAt)
+}
+
+func TestDispatcher_IgnoresUnsubscribedAndDisabled(t *testing.T) {
+	d, deliveries := newTestDispatcher("http://localhost", "product.stock_changed")
+	assert.NoError(t, d.Handle(context.Background(), priceChanged{}))
+
+	d.Webhooks.(*memoryWebhookRepo).hooks["w1"].Events = []string{domain.AllEvents}
+	d.Webhooks.(*memoryWebhookRepo).hooks["w1"].Disabled = true
+	assert.NoError(t, d.Handle(context.Background(), priceChanged{}))
+	assert.Empty(t, deliveries.items)
+}
+
+func TestDispatcher_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	rc := &receiver{statuses: []int{500, 503, 500}}
+	srv := rc.start(t, "s3cr3t")
+	d, deliveries := newTestDispatcher(srv.URL, domain.AllEvents)
+	d.MaxAttempts = 3
+	d.Backoff = time.Minute
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	assert.NoError(t, d.Handle(context.Background(), priceChanged{}))
+
+	d.RunOnce(context.Background())
+	first := deliveries.items[0]
+	assert.Equal(t, domain.DeliveryPending, first.Status)
+	assert.Equal(t, now.Add(time.Minute), first.NextAttemptAt)
+	assert.Equal(t, "el receptor respondió 500", first.Attempts[0].Error)
+
+	// Antes de que venza la espera no se reintenta.
+	n, _ := d.RunOnce(context.Background())
+	assert.Equal(t, 0, n)
+
+	now = now.Add(time.Minute)
+	d.RunOnce(context.Background())
+	assert.Equal(t, now.Add(2*time.Minute), deliveries.items[0].NextAttemptAt)
+
+	now = now.Add(2 * time.Minute)
+	d.RunOnce(context.Background())
+	dead := deliveries.items[0]
+	assert.Equal(t, domain.DeliveryDead, dead.Status)
+	assert.Len(t, dead.Attempts, 3)
+
+	service := NewWebhookService(d.Webhooks, deliveries, nil)
+	letters, _ := service.DeadLetters(context.Background(), 0)
+	assert.Len(t, letters, 1)
+
+	// Reintentarla manualmente la vuelve a enviar; el receptor ya responde 200.
+	_, err := service.Retry(context.Background(), dead.ID)
+	assert.NoError(t, err)
+	d.now = time.Now
+	d.RunOnce(context.Background())
+	assert.Equal(t, domain.DeliverySucceeded, deliveries.items[0].Status)
+	assert.Len(t, rc.bodies, 4)
+}
+
+func TestDispatcher_DeletedWebhookGoesToDeadLetters(t *testing.T) {
+	d, deliveries := newTestDispatcher("http://localhost", domain.AllEvents)
+	assert.NoError(t, d.Handle(context.Background(), priceChanged{}))
+	delete(d.Webhooks.(*memoryWebhookRepo).hooks, "w1")
+
+	d.RunOnce(context.Background())
+	assert.Equal(t, domain.DeliveryDead, deliveries.items[0].Status)
+}
+
+func TestDispatcher_OnlyEventTenantWebhooks(t *testing.T) {
+	d, deliveries := newTestDispatcher("http://localhost", domain.AllEvents)
+	ctx := tenant.WithID(context.Background(), "otra-tienda")
+	assert.NoError(t, d.Handle(ctx, priceChanged{}))
+	assert.Empty(t, deliveries.items)
+
+	assert.NoError(t, d.Handle(context.Background(), priceChanged{}))
+	assert.Len(t, deliveries.items, 1)
+	assert.Equal(t, tenant.Default, deliveries.items[0].Tenant)
+}
+
+func TestDispatcher_BlocksInternalAddresses(t *testing.T) {
+	rc := &receiver{}
+	srv := rc.start(t, "s3cr3t")
+	d, deliveries := newTestDispatcher(srv.URL, domain.AllEvents)
+	d.Client = NewClient(DefaultDeliveryTimeout, false)
+	assert.NoError(t, d.Handle(context.Background(), priceChanged{}))
+
+	d.RunOnce(context.Background())
+
+	assert.Empty(t, rc.bodies)
+	attempt := deliveries.items[0].Attempts[0]
+	assert.Contains(t, attempt.Error, domain.ErrBlockedAddress.Error())
+	assert.Zero(t, attempt.StatusCode)
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	rc := &receiver{}
+	target := rc.start(t, "s3cr3t")
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	t.Cleanup(redirect.Close)
+	d, deliveries := newTestDispatcher(redirect.URL, domain.AllEvents)
+	assert.NoError(t, d.Handle(context.Background(), priceChanged{}))
+
+	d.RunOnce(context.Background())
+
+	assert.Empty(t, rc.bodies)
+	delivery := deliveries.items[0]
+	assert.Equal(t, domain.DeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusTemporaryRedirect, delivery.Attempts[0].StatusCode)
+}
+
+func TestBlockedAddr(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.9", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "::1", "fd00::1", "fe80::1", "::ffff:127.0.0.1"} {
+		assert.True(t, blockedAddr(netip.MustParseAddr(host)), host)
+	}
+	for _, host := range []string{"8.8.8.8", "151.101.1.69", "2606:4700::1111"} {
+		assert.False(t, blockedAddr(netip.MustParseAddr(host)), host)
+	}
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"mlsport/internal/webhook/domain"
+)
+
+// blockedPrefixes completa lo que netip ya clasifica (loopback, privadas, link-local): redes
+// compartidas y de documentación que tampoco deberían recibir webhooks de terceros.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// blockedAddr indica si la dirección apunta a la red interna: loopback, redes privadas,
+// link-local (incluida la metadata de la nube en 169.254.169.254), multicast o no especificada.
+func blockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedHost rechaza al registrar el webhook los hosts que ya se sabe que son internos; los
+// nombres que resuelven a la red interna los corta el dialer al conectar.
+func blockedHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && blockedAddr(addr)
+}
+
+// guardDial se ejecuta con la IP ya resuelta, justo antes de conectar: un DNS que cambia de
+// respuesta entre la validación y la entrega (DNS rebinding) no puede saltarse el control.
+func guardDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if blockedAddr(addr) {
+		return fmt.Errorf("%w: %s", domain.ErrBlockedAddress, addr)
+	}
+	return nil
+}
+
+// NewClient arma el cliente de las entregas: no sigue redirecciones (un 3xx cuenta como fallo) y,
+// salvo allowPrivate, no conecta con direcciones internas. Va sin proxy para que el control se
+// aplique a la IP del receptor.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = guardDial
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+
+	"mlsport/internal/tenant"
+	"mlsport/internal/webhook/domain"
+)
+
+const (
+	DefaultDeliveryLogLimit = 50
+	MaxDeliveryLogLimit     = 500
+)
+
+// WebhookService administra los webhooks de la tienda del contexto; los de otras tiendas se
+// tratan como inexistentes.
+type WebhookService struct {
+	Repo       domain.WebhookRepository
+	Deliveries domain.DeliveryRepository
+	// KnownEvents son los tipos de evento a los que se puede suscribir un webhook.
+	KnownEvents []string
+	// OnRetry, si está configurado, despierta al despachador al reprogramar una entrega.
+	OnRetry func()
+	// AllowPrivateNetworks acepta URLs de loopback o redes privadas (desarrollo local).
+	AllowPrivateNetworks bool
+}
+
+func NewWebhookService(repo domain.WebhookRepository, deliveries domain.DeliveryRepository, knownEvents []string) *WebhookService {
+	return &WebhookService{Repo: repo, Deliveries: deliveries, KnownEvents: knownEvents}
+}
+
+// Create registra el webhook. Si no trae secreto se genera uno; es la única respuesta que lo incluye.
+func (s *WebhookService) Create(ctx context.Context, w *domain.Webhook) error {
+	if err := s.prepare(w); err != nil {
+		return err
+	}
+	w.Tenant = tenant.ID(ctx)
+	if w.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return err
+		}
+		w.Secret = secret
+	}
+	w.CreatedAt = time.Now().UTC()
+	return s.Repo.Create(w)
+}
+
+func (s *WebhookService) GetAll(ctx context.Context) ([]domain.Webhook, error) {
+	list, err := s.Repo.FindAll(tenant.ID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Secret = ""
+	}
+	return list, nil
+}
+
+func (s *WebhookService) GetByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	w, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	w.Secret = ""
+	return w, nil
+}
+
+// Update reemplaza URL, eventos y estado; sin secreto se conserva el actual.
+func (s *WebhookService) Update(ctx context.Context, id string, input *domain.Webhook) error {
+	current, err := s.find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.prepare(input); err != nil {
+		return err
+	}
+
+	input.ID = current.ID
+	input.ObjectID = current.ObjectID
+	input.Tenant = current.Tenant
+	input.CreatedAt = current.CreatedAt
+	secret := input.Secret
+	if secret == "" {
+		input.Secret = current.Secret
+	}
+	if err := s.Repo.Update(input); err != nil {
+		return err
+	}
+	if secret == "" {
+		input.Secret = ""
+	}
+	return nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id string) error {
+	if _, err := s.find(ctx, id); err != nil {
+		return err
+	}
+	return s.Repo.Delete(id)
+}
+
+// DeliveryLog devuelve el registro de entregas del webhook, de la más reciente a la más antigua.
+func (s *WebhookService) DeliveryLog(ctx context.Context, webhookID, status string, limit int) ([]domain.Delivery, error) {
+	if _, err := s.find(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return s.Deliveries.FindByWebhook(webhookID, status, clampLimit(limit))
+}
+
+// DeadLetters lista las entregas que agotaron sus reintentos.
+func (s *WebhookService) DeadLetters(ctx context.Context, limit int) ([]domain.Delivery, error) {
+	return s.Deliveries.FindByStatus(tenant.ID(ctx), domain.DeliveryDead, clampLimit(limit))
+}
+
+// Retry vuelve a poner en cola una entrega (típicamente una de la lista de muertas).
+func (s *WebhookService) Retry(ctx context.Context, id string) (*domain.Delivery, error) {
+	d, err := s.Deliveries.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Tenant != tenant.ID(ctx) {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	d.Status = domain.DeliveryPending
+	d.NextAttemptAt = time.Now().UTC()
+	if err := s.Deliveries.Update(d); err != nil {
+		return nil, err
+	}
+	if s.OnRetry != nil {
+		s.OnRetry()
+	}
+	return d, nil
+}
+
+// find devuelve el webhook solo si es de la tienda del contexto.
+func (s *WebhookService) find(ctx context.Context, id string) (*domain.Webhook, error) {
+	w, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if w.Tenant != tenant.ID(ctx) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	return w, nil
+}
+
+func (s *WebhookService) prepare(w *domain.Webhook) error {
+	w.URL = strings.TrimSpace(w.URL)
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(w.Events) == 0 {
+		return domain.ErrInvalidWebhook
+	}
+	if !s.AllowPrivateNetworks && blockedHost(u.Hostname()) {
+		return domain.ErrBlockedAddress
+	}
+
+	seen := map[string]bool{}
+	events := w.Events[:0]
+	for _, e := range w.Events {
+		e = strings.TrimSpace(e)
+		if !s.known(e) {
+			return domain.ErrUnknownEvent
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+	w.Events = events
+	return nil
+}
+
+func (s *WebhookService) known(name string) bool {
+	if name == domain.AllEvents {
+		return true
+	}
+	for _, e := range s.KnownEvents {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultDeliveryLogLimit
+	}
+	if limit > MaxDeliveryLogLimit {
+		return MaxDeliveryLogLimit
+	}
+	return limit
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
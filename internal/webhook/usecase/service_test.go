@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"mlsport/internal/tenant"
+	"mlsport/internal/webhook/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookService_CreateValidatesAndHidesSecret(t *testing.T) {
+	service := NewWebhookService(newMemoryWebhookRepo(), &memoryDeliveryRepo{}, []string{"product.created"})
+	ctx := context.Background()
+
+	assert.ErrorIs(t, service.Create(ctx, &domain.Webhook{URL: "ftp://x", Events: []string{"product.created"}}), domain.ErrInvalidWebhook)
+	assert.ErrorIs(t, service.Create(ctx, &domain.Webhook{URL: "https://x.test"}), domain.ErrInvalidWebhook)
+	assert.ErrorIs(t, service.Create(ctx, &domain.Webhook{URL: "https://x.test", Events: []string{"order.paid"}}), domain.ErrUnknownEvent)
+
+	w := &domain.Webhook{URL: " https://partner.test/hook ", Events: []string{"product.created", "product.created", "*"}}
+	assert.NoError(t, service.Create(ctx, w))
+	assert.Equal(t, "https://partner.test/hook", w.URL)
+	assert.Equal(t, []string{"product.created", "*"}, w.Events)
+	assert.NotEmpty(t, w.Secret)
+
+	got, _ := service.GetByID(ctx, w.ID)
+	assert.Empty(t, got.Secret)
+
+	// Actualizar sin secreto conserva el anterior.
+	assert.NoError(t, service.Update(ctx, w.ID, &domain.Webhook{URL: "https://partner.test/v2", Events: []string{"*"}}))
+	stored, _ := service.Repo.FindByID(w.ID)
+	assert.Equal(t, w.Secret, stored.Secret)
+	assert.Equal(t, "https://partner.test/v2", stored.URL)
+}
+
+func TestWebhookService_RejectsInternalURLs(t *testing.T) {
+	service := NewWebhookService(newMemoryWebhookRepo(), &memoryDeliveryRepo{}, []string{"product.created"})
+	ctx := context.Background()
+
+	for _, url := range []string{"http://127.0.0.1:8080/hook", "http://localhost/hook", "http://169.254.169.254/latest/meta-data",
+		"https://10.0.0.5/hook", "http://[::1]/hook", "http://api.localhost/hook"} {
+		err := service.Create(ctx, &domain.Webhook{URL: url, Events: []string{"product.created"}})
+		assert.ErrorIs(t, err, domain.ErrBlockedAddress, url)
+	}
+
+	service.AllowPrivateNetworks = true
+	assert.NoError(t, service.Create(ctx, &domain.Webhook{URL: "http://127.0.0.1:8080/hook", Events: []string{"product.created"}}))
+}
+
+func TestWebhookService_HidesOtherTenants(t *testing.T) {
+	service := NewWebhookService(newMemoryWebhookRepo(), &memoryDeliveryRepo{}, []string{"product.created"})
+	a := tenant.WithID(context.Background(), "tienda-a")
+	b := tenant.WithID(context.Background(), "tienda-b")
+
+	w := &domain.Webhook{URL: "https://a.test/hook", Events: []string{"product.created"}}
+	assert.NoError(t, service.Create(a, w))
+
+	_, err := service.GetByID(b, w.ID)
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+	assert.ErrorIs(t, service.Delete(b, w.ID), domain.ErrWebhookNotFound)
+	list, _ := service.GetAll(b)
+	assert.Empty(t, list)
+	list, _ = service.GetAll(a)
+	assert.Len(t, list, 1)
+}
+
+func TestSign(t *testing.T) {
+	sig := domain.Sign("secreto", 1700000000, []byte(`{"a":1}`))
+	assert.Equal(t, sig, domain.Sign("secreto", 1700000000, []byte(`{"a":1}`)))
+	assert.NotEqual(t, sig, domain.Sign("otro", 1700000000, []byte(`{"a":1}`)))
+	assert.Contains(t, sig, "t=1700000000,v1=")
+}